@@ -103,3 +103,14 @@ func WithStack(err error) Error {
 
 	return &item{msg: err.Error(), stack: callers(3)}
 }
+
+// Origin the function and file:line where err created, empty if err has no stack info
+func Origin(err error) string {
+	e, ok := err.(*item)
+	if !ok || len(e.stack) == 0 {
+		return ""
+	}
+
+	frame, _ := runtime.CallersFrames(e.stack[:1]).Next()
+	return fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)
+}
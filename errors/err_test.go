@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/bluekaki/pkg/zaplog"
@@ -34,3 +35,13 @@ func TestErr(t *testing.T) {
 
 	t.Logf("%+v", New("a dummy error"))
 }
+
+func TestOrigin(t *testing.T) {
+	if origin := Origin(New("a dummy err")); !strings.HasPrefix(origin, "github.com/bluekaki/pkg/errors.TestOrigin ") || !strings.Contains(origin, "err_test.go:") {
+		t.Fatal("unexpected origin", origin)
+	}
+
+	if origin := Origin(errors.New("std err")); origin != "" {
+		t.Fatal("std err should have no origin", origin)
+	}
+}
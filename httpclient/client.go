@@ -115,6 +115,7 @@ func withoutBody(method, url string, form httpURL.Values, options ...Option) (bo
 	if opt.Journal != nil {
 		opt.Header[journal.JournalHeader] = opt.Journal.ID
 	}
+	propagateTrace(opt)
 
 	ttl := opt.TTL
 	if ttl <= 0 {
@@ -232,6 +233,7 @@ func withFormBody(method, url string, form httpURL.Values, options ...Option) (b
 	if opt.Journal != nil {
 		opt.Header[journal.JournalHeader] = opt.Journal.ID
 	}
+	propagateTrace(opt)
 
 	ttl := opt.TTL
 	if ttl <= 0 {
@@ -327,6 +329,7 @@ func withJSONBody(method, url string, raw json.RawMessage, options ...Option) (b
 	if opt.Journal != nil {
 		opt.Header[journal.JournalHeader] = opt.Journal.ID
 	}
+	propagateTrace(opt)
 
 	ttl := opt.TTL
 	if ttl <= 0 {
@@ -456,6 +459,7 @@ func withMultipartFile(method, url string, payload [][]byte, options ...Option)
 	if opt.Journal != nil {
 		opt.Header[journal.JournalHeader] = opt.Journal.ID
 	}
+	propagateTrace(opt)
 
 	ttl := opt.TTL
 	if ttl <= 0 {
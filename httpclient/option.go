@@ -37,6 +37,7 @@ func newOption() *option {
 	}
 }
 
+// WithContext setup the parent context, the trace carried by ctx also be propagated
 func WithContext(ctx context.Context) Option {
 	return func(opt *option) {
 		opt.Ctx = ctx
@@ -14,6 +14,7 @@ import (
 
 	"github.com/bluekaki/pkg/errors"
 	"github.com/bluekaki/pkg/httpclient/internal/journal"
+	"github.com/bluekaki/pkg/trace"
 
 	"go.uber.org/zap"
 )
@@ -34,6 +35,19 @@ var defaultClient = &http.Client{
 	},
 }
 
+// propagateTrace set w3c trace headers from the span context carried by ctx
+func propagateTrace(opt *option) {
+	sc := trace.SpanContextFromContext(opt.Ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	opt.Header[trace.Traceparent] = sc.Traceparent()
+	if sc.TraceState != "" {
+		opt.Header[trace.Tracestate] = sc.TraceState
+	}
+}
+
 func doHTTP(ctx context.Context, method, url string, payload []byte, opt *option) ([]byte, http.Header, int, error) {
 	ts := time.Now()
 
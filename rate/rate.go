@@ -32,7 +32,11 @@ type Config struct {
 		Upper uint32
 		// Lower used when lose redis
 		Lower uint16
+		// Burst the maximum burst size; optional, default 1
+		Burst uint32
 	}
+	// DisableSlowStart use Limit.Upper at once, rather than wait for other instances startup
+	DisableSlowStart bool
 	// Log setup logger, if enable and no logger set, zap.NewProduction() will used.
 	Log struct {
 		Enable bool
@@ -58,6 +62,8 @@ type Limiter interface {
 	UpdateRate(limitUpper uint32, limitLower uint16) error
 	// Allow whether event can happen at time now
 	Allow()
+	// TryAllow report whether event can happen at time now, never block
+	TryAllow() bool
 }
 
 type limiter struct {
@@ -91,6 +97,11 @@ func NewLimiter(conf *Config, redis Redis) (Limiter, error) {
 		return nil, errors.New("redis required")
 	}
 
+	burst := 1
+	if conf.Limit.Burst > 0 {
+		burst = int(conf.Limit.Burst)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	limiter := &limiter{
 		ctx:        ctx,
@@ -101,7 +112,7 @@ func NewLimiter(conf *Config, redis Redis) (Limiter, error) {
 		identifier: prefix + conf.Identifier,
 		limitUpper: int(conf.Limit.Upper),
 		limitLower: int(conf.Limit.Lower),
-		limiter:    rate.NewLimiter(rate.Limit(1), 1), // slow start
+		limiter:    rate.NewLimiter(rate.Limit(1), burst), // slow start
 	}
 
 	if conf.Log.Logger == nil && conf.Log.Enable {
@@ -111,10 +122,15 @@ func NewLimiter(conf *Config, redis Redis) (Limiter, error) {
 		}
 	}
 
-	go func() {
-		time.Sleep(time.Second * 5) // avoid rate increase rapidly during multi instances startup
+	if conf.DisableSlowStart {
 		limiter.accelerate()
-	}()
+
+	} else {
+		go func() {
+			time.Sleep(time.Second * 5) // avoid rate increase rapidly during multi instances startup
+			limiter.accelerate()
+		}()
+	}
 
 	tickerInterval := conf.TickerInterval
 	if tickerInterval == 0 {
@@ -177,6 +193,15 @@ func (l *limiter) Allow() {
 	atomic.AddUint64(&l.summary, 1)
 }
 
+func (l *limiter) TryAllow() bool {
+	if !l.limiter.Allow() {
+		return false
+	}
+
+	atomic.AddUint64(&l.summary, 1)
+	return true
+}
+
 // report increment to redis
 func (l *limiter) report(tickerInterval time.Duration) {
 	ticker := time.NewTicker(tickerInterval)
@@ -0,0 +1,163 @@
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+// SpanKind the role of a span
+type SpanKind int
+
+const (
+	// KindServer handle an incoming request
+	KindServer SpanKind = iota + 1
+	// KindClient send an outgoing request
+	KindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "unspecified"
+	}
+}
+
+// Span a timed operation
+type Span struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	Parent      SpanID
+	Start       time.Time
+	End         time.Time
+	Attributes  map[string]string
+	Code        string // status code, e.g. OK
+	Error       string
+
+	tracer *Tracer
+	once   sync.Once
+	mux    sync.Mutex
+}
+
+// SetAttribute add an attribute
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	s.Attributes[key] = value
+	s.mux.Unlock()
+}
+
+// Finish end the span with code and err, then export it; only the first call takes effect
+func (s *Span) Finish(code string, err error) {
+	if s == nil {
+		return
+	}
+
+	s.once.Do(func() {
+		s.mux.Lock()
+		s.End = time.Now()
+		s.Code = code
+		if err != nil {
+			s.Error = err.Error()
+		}
+		s.mux.Unlock()
+
+		if s.SpanContext.Sampled {
+			s.tracer.exporter.Export(s)
+		}
+	})
+}
+
+// Exporter send finished spans to somewhere
+type Exporter interface {
+	Export(span *Span)
+}
+
+// Tracer create spans
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer create a tracer with exporter
+func NewTracer(exporter Exporter) *Tracer {
+	if exporter == nil {
+		panic("exporter required")
+	}
+
+	return &Tracer{exporter: exporter}
+}
+
+// Start begin a span; the trace continues from parent if valid, otherwise a new sampled trace started with the id derived from journal id
+func (t *Tracer) Start(name string, kind SpanKind, parent SpanContext, journalID string) *Span {
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: make(map[string]string),
+		tracer:     t,
+	}
+
+	if parent.IsValid() {
+		span.SpanContext = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     NewSpanID(),
+			Sampled:    parent.Sampled,
+			TraceState: parent.TraceState,
+		}
+		span.Parent = parent.SpanID
+
+	} else {
+		span.SpanContext = SpanContext{
+			TraceID: TraceIDFromJournalID(journalID),
+			SpanID:  NewSpanID(),
+			Sampled: true,
+		}
+	}
+
+	return span
+}
+
+var _ Exporter = (*InMemoryExporter)(nil)
+
+// InMemoryExporter keep spans in memory, for tests
+type InMemoryExporter struct {
+	mux   sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter create an in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return new(InMemoryExporter)
+}
+
+func (i *InMemoryExporter) Export(span *Span) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.spans = append(i.spans, span)
+}
+
+// Spans the exported spans in order
+func (i *InMemoryExporter) Spans() []*Span {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	spans := make([]*Span, len(i.spans))
+	copy(spans, i.spans)
+	return spans
+}
+
+// Reset drop all spans
+func (i *InMemoryExporter) Reset() {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.spans = nil
+}
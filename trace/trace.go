@@ -0,0 +1,150 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/minami58"
+)
+
+const (
+	// Traceparent the w3c trace context header
+	Traceparent = "traceparent"
+	// Tracestate the w3c vendor-specific trace header, propagated as it is
+	Tracestate = "tracestate"
+
+	version      = "00"
+	flagsSampled = 0x01
+)
+
+// TraceID 16 bytes trace id
+type TraceID [16]byte
+
+// IsValid not all zero
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID 8 bytes span id
+type SpanID [8]byte
+
+// IsValid not all zero
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext the propagated part of a span
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string // value of tracestate header
+}
+
+// IsValid both trace id and span id are valid
+func (s SpanContext) IsValid() bool {
+	return s.TraceID.IsValid() && s.SpanID.IsValid()
+}
+
+// Traceparent format as the value of traceparent header
+func (s SpanContext) Traceparent() string {
+	flags := 0
+	if s.Sampled {
+		flags = flagsSampled
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%02x", version, s.TraceID, s.SpanID, flags)
+}
+
+// ParseTraceparent parse the value of traceparent header
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, errors.Errorf("traceparent: %s illegal", value)
+	}
+	if parts[0] == version && len(parts) != 4 {
+		return sc, errors.Errorf("traceparent: %s illegal", value)
+	}
+
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, errors.Errorf("traceparent: %s illegal", value)
+	}
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s illegal trace-id", value)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s illegal parent-id", value)
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s illegal trace-flags", value)
+	}
+	sc.Sampled = flags[0]&flagsSampled == flagsSampled
+
+	if !sc.IsValid() {
+		return sc, errors.Errorf("traceparent: %s all zero id", value)
+	}
+	return sc, nil
+}
+
+// TraceIDFromJournalID reuse the journal id as trace id, a 32len hex or a minami58 encoded journal id is kept as it is, others are hashed
+func TraceIDFromJournalID(journalID string) (traceID TraceID) {
+	if len(journalID) == 32 {
+		if _, err := hex.Decode(traceID[:], []byte(journalID)); err == nil && traceID.IsValid() {
+			return
+		}
+	}
+
+	if raw := minami58.Decode([]byte(journalID)); len(raw) >= len(traceID) && string(minami58.Encode(raw)) == journalID {
+		if copy(traceID[:], raw); traceID.IsValid() {
+			return
+		}
+	}
+
+	digest := sha256.Sum256([]byte(journalID))
+	copy(traceID[:], digest[:])
+	return
+}
+
+// NewSpanID create a random span id
+func NewSpanID() (spanID SpanID) {
+	for !spanID.IsValid() {
+		io.ReadFull(rand.Reader, spanID[:])
+	}
+	return
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext carry the span context in ctx, which used as parent by downstream calls
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext get span context from ctx, invalid if not found
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
@@ -0,0 +1,75 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluekaki/pkg/id"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceparent(t *testing.T) {
+	assert := assert.New(t)
+
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Nil(err)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal("00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(sc.Sampled)
+	assert.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(value)
+		assert.NotNil(err, value)
+	}
+}
+
+func TestTraceIDFromJournalID(t *testing.T) {
+	assert := assert.New(t)
+
+	journalID := id.JournalID()
+	assert.Equal(TraceIDFromJournalID(journalID), TraceIDFromJournalID(journalID))
+	assert.True(TraceIDFromJournalID(journalID).IsValid())
+
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", TraceIDFromJournalID("4bf92f3577b34da6a3ce929d0e0e4736").String())
+	assert.True(TraceIDFromJournalID("custom journal id").IsValid())
+}
+
+func TestTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	root := tracer.Start("gateway", KindClient, SpanContext{}, "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx := ContextWithSpanContext(context.Background(), root.SpanContext)
+
+	child := tracer.Start("server", KindServer, SpanContextFromContext(ctx), "")
+	child.SetAttribute("journal_id", "4bf92f3577b34da6a3ce929d0e0e4736")
+	child.Finish("OK", nil)
+	root.Finish("OK", nil)
+	root.Finish("Internal", nil)
+
+	spans := exporter.Spans()
+	assert.Len(spans, 2)
+	assert.Equal("server", spans[0].Name)
+	assert.Equal(root.SpanContext.TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(root.SpanContext.SpanID, spans[0].Parent)
+	assert.Equal("OK", spans[1].Code)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[1].SpanContext.TraceID.String())
+
+	unsampled := tracer.Start("server", KindServer, SpanContext{TraceID: root.SpanContext.TraceID, SpanID: NewSpanID()}, "")
+	unsampled.Finish("OK", nil)
+	assert.Len(exporter.Spans(), 2)
+
+	exporter.Reset()
+	assert.Len(exporter.Spans(), 0)
+}
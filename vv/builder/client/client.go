@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/configs"
 	"github.com/bluekaki/pkg/vv/internal/interceptor"
 	"github.com/bluekaki/pkg/vv/proposal"
@@ -30,6 +31,11 @@ var (
 	defaultDialTimeout = time.Second * 2
 )
 
+// RegisteRedactedHeaders the metadata masked in journal and alert, Authorization and Authorization-Proxy by default
+func RegisteRedactedHeaders(headers ...string) {
+	interceptor.SetRedactedHeaders(headers)
+}
+
 // Option some options for build a conn
 type Option func(*option)
 
@@ -40,6 +46,10 @@ type option struct {
 	dialTimeout     time.Duration
 	signer          proposal.Signer
 	projectName     string
+	metricsConfig   *proposal.MetricsConfig
+	links           []interceptor.ClientLink
+	tracer          *trace.Tracer
+	resilience      *interceptor.Resilience
 }
 
 // WithCredential setup credential for tls
@@ -77,6 +87,16 @@ func WithSigner(signer proposal.Signer) Option {
 	}
 }
 
+// WithMetrics enable prometheus metrics, which served by the registry of config or the default one
+func WithMetrics(config *proposal.MetricsConfig) Option {
+	return func(opt *option) {
+		if config == nil {
+			config = new(proposal.MetricsConfig)
+		}
+		opt.metricsConfig = config
+	}
+}
+
 // WithProjectName add project name into alert message
 func WithProjectName(name string) Option {
 	return func(opt *option) {
@@ -84,6 +104,110 @@ func WithProjectName(name string) Option {
 	}
 }
 
+// WithTraceExporter enable tracing, spans are propagated by w3c traceparent and sent to exporter
+func WithTraceExporter(exporter trace.Exporter) Option {
+	return func(opt *option) {
+		if exporter != nil {
+			opt.tracer = trace.NewTracer(exporter)
+		}
+	}
+}
+
+// WithRetryPolicy retry the failed unary calls of method, which is a full method (e.g. /dummy.DummyService/Echo),
+// a service (e.g. dummy.DummyService) or empty for all methods; the retryPolicy of the default service config disabled once declared.
+func WithRetryPolicy(method string, policy *proposal.RetryPolicy) Option {
+	return func(opt *option) {
+		if policy == nil {
+			policy = new(proposal.RetryPolicy)
+		}
+
+		opt.withResilience()
+		opt.resilience.Retries[strings.TrimSpace(method)] = policy
+	}
+}
+
+// WithHedgingPolicy hedge the unary calls of method (same as WithRetryPolicy), only for the idempotent ones;
+// mutually exclusive with the retry policy of the same method.
+func WithHedgingPolicy(method string, policy *proposal.HedgingPolicy) Option {
+	return func(opt *option) {
+		if policy == nil {
+			policy = new(proposal.HedgingPolicy)
+		}
+
+		opt.withResilience()
+		opt.resilience.Hedgings[strings.TrimSpace(method)] = policy
+	}
+}
+
+// WithCircuitBreaker guard the unary calls of scope (same as the method of WithRetryPolicy) by a circuit breaker,
+// the calls rejected by cuzerr.CircuitOpen when opened; the state exported by metrics if enabled.
+func WithCircuitBreaker(scope string, config *proposal.BreakerConfig) Option {
+	return func(opt *option) {
+		if config == nil {
+			config = new(proposal.BreakerConfig)
+		}
+
+		opt.withResilience()
+		opt.resilience.Breakers[strings.TrimSpace(scope)] = config
+	}
+}
+
+func (opt *option) withResilience() {
+	if opt.resilience == nil {
+		opt.resilience = &interceptor.Resilience{
+			Retries:  make(map[string]*proposal.RetryPolicy),
+			Hedgings: make(map[string]*proposal.HedgingPolicy),
+			Breakers: make(map[string]*proposal.BreakerConfig),
+		}
+	}
+}
+
+// WithUnaryInterceptor insert a custom unary interceptor before or after one of the built-in stages,
+// stages are journal and authorization_proxy(signer) in order.
+func WithUnaryInterceptor(name string, position proposal.Position, stage proposal.Stage, unary grpc.UnaryClientInterceptor) Option {
+	return func(opt *option) {
+		if unary != nil {
+			opt.links = append(opt.links, interceptor.ClientLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Unary:     unary,
+			})
+		}
+	}
+}
+
+// WithStreamInterceptor insert a custom stream interceptor before or after one of the built-in stages,
+// stages are journal and authorization_proxy(signer) in order.
+func WithStreamInterceptor(name string, position proposal.Position, stage proposal.Stage, stream grpc.StreamClientInterceptor) Option {
+	return func(opt *option) {
+		if stream != nil {
+			opt.links = append(opt.links, interceptor.ClientLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Stream:    stream,
+			})
+		}
+	}
+}
+
+// UnaryChain the names of unary interceptors in order, built-in stages included
+func UnaryChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ClientChain(interceptor.Client, opt.links, true)
+}
+
+// StreamChain the names of stream interceptors in order, built-in stages included
+func StreamChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ClientChain(interceptor.Client, opt.links, false)
+}
+
 // NewConn create a grpc client conn
 func NewConn(endpoint string, logger *zap.Logger, notify proposal.NotifyHandler, options ...Option) (ConnInterface, error) {
 	if endpoint = strings.TrimSpace(endpoint); endpoint == "" {
@@ -116,17 +240,34 @@ func NewConn(endpoint string, logger *zap.Logger, notify proposal.NotifyHandler,
 		dialTimeout = opt.dialTimeout
 	}
 
+	var metrics *interceptor.Metrics
+	if opt.metricsConfig != nil {
+		metrics = interceptor.NewMetrics(interceptor.Client, opt.projectName, opt.metricsConfig)
+	}
+
+	stages := interceptor.ClientOptions{
+		Tracer: opt.tracer,
+		Links:  opt.links,
+	}
+
 	dialOptions := []grpc.DialOption{
 		grpc.WithResolvers(resolverBuilder),
 		grpc.WithTimeout(dialTimeout),
 		grpc.WithBlock(),
 		grpc.WithMaxMsgSize(configs.MaxMsgSize),
 		grpc.WithKeepaliveParams(*kacp),
-		grpc.WithUnaryInterceptor(interceptor.UnaryClientInterceptor(logger, notify, opt.signer, opt.projectName)),
-		grpc.WithStreamInterceptor(interceptor.StreamClientInterceptor(logger, notify, opt.signer, opt.projectName)),
+		grpc.WithUnaryInterceptor(interceptor.UnaryClientInterceptor(logger, notify, metrics, opt.signer, opt.projectName, stages)),
+		grpc.WithStreamInterceptor(interceptor.StreamClientInterceptor(logger, notify, metrics, opt.signer, opt.projectName, stages)),
 		grpc.WithDefaultServiceConfig(configs.ServiceConfig),
 	}
 
+	if opt.resilience.Declared() {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(interceptor.UnaryResilienceInterceptor(metrics, opt.resilience)))
+		if len(opt.resilience.Retries)+len(opt.resilience.Hedgings) > 0 {
+			dialOptions = append(dialOptions, grpc.WithDisableRetry())
+		}
+	}
+
 	if opt.credential == nil {
 		dialOptions = append(dialOptions, grpc.WithInsecure())
 	} else {
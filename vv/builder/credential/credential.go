@@ -30,7 +30,7 @@ type ServerOption struct {
 	CertPEMBlock []byte
 	// KeyPEMBlock PEM encoded data
 	KeyPEMBlock []byte
-	// RequireAndVerifyClientCert enable verify client's cert
+	// RequireAndVerifyClientCert enable verify client's cert, authorized by options.peer_certificate and read by vv.PeerIdentity
 	RequireAndVerifyClientCert bool
 }
 
@@ -0,0 +1,337 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	defaultReloadInterval = time.Minute
+	defaultWarnBefore     = time.Hour * 24 * 7
+	warnInterval          = time.Hour * 24 // warn the expiring cert at most once in it
+)
+
+// seconds since epoch the cert expires at, by cert file
+var expiryGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "bluekaki",
+	Subsystem: "vv",
+	Name:      "tls_certificate_expiry_timestamp_seconds",
+}, []string{"file"})
+
+// ReloadOption the files of a reloading credential, polled by checksum and swapped atomically for new handshakes
+type ReloadOption struct {
+	// CertFile PEM encoded, required by server; used by client if server require and verify client cert, optional
+	CertFile string
+	// KeyFile PEM encoded, along with CertFile
+	KeyFile string
+	// ChainFile chain of the peer's issuer, required by client, and by server if RequireAndVerifyClientCert
+	ChainFile string
+	// ServerName name of server, required by client
+	ServerName string
+	// RequireAndVerifyClientCert enable verify client's cert, server only
+	RequireAndVerifyClientCert bool
+	// Interval poll the checksum of files, default 1m
+	Interval time.Duration
+	// WarnBefore log a warning if the cert expires in it, default 7 days
+	WarnBefore time.Duration
+	// Registerer the expiry metric registered in, default prometheus.DefaultRegisterer
+	Registerer prometheus.Registerer
+}
+
+// Reloader keep the credential up to date with the files
+type Reloader struct {
+	logger *zap.Logger
+	option ReloadOption
+	now    func() time.Time
+	expiry *prometheus.GaugeVec
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mux      sync.Mutex // serialize reloading
+	checksum []byte
+	warnedAt time.Time
+
+	material atomic.Value // *material
+	config   *tls.Config
+}
+
+type material struct {
+	cert *tls.Certificate // nil if no cert
+	pool *x509.CertPool   // nil if no chain
+}
+
+// NewReloadingServer create a server's credential reloaded from files
+func NewReloadingServer(logger *zap.Logger, option ReloadOption) (*Reloader, error) {
+	if option.CertFile == "" {
+		return nil, errors.New("CertFile required")
+	}
+	if option.KeyFile == "" {
+		return nil, errors.New("KeyFile required")
+	}
+	if option.RequireAndVerifyClientCert && option.ChainFile == "" {
+		return nil, errors.New("ChainFile required")
+	}
+
+	r, err := newReloader(logger, option, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.NoClientCert
+	if option.RequireAndVerifyClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.config = &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			material := r.current()
+			return &tls.Config{
+				Certificates: []tls.Certificate{*material.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    material.pool,
+				NextProtos:   []string{"h2"},
+				CipherSuites: []uint16{
+					tls.TLS_AES_128_GCM_SHA256,
+					tls.TLS_AES_256_GCM_SHA384,
+					tls.TLS_CHACHA20_POLY1305_SHA256,
+				},
+				PreferServerCipherSuites: true,
+				MinVersion:               tls.VersionTLS13,
+				CurvePreferences:         []tls.CurveID{tls.X25519},
+			}, nil
+		},
+		MinVersion: tls.VersionTLS13,
+	}
+
+	go r.poll()
+	return r, nil
+}
+
+// NewReloadingClient create a client's credential reloaded from files
+func NewReloadingClient(logger *zap.Logger, option ReloadOption) (*Reloader, error) {
+	serverName := strings.TrimSpace(option.ServerName)
+	if serverName == "" {
+		return nil, errors.New("ServerName required")
+	}
+	if option.ChainFile == "" {
+		return nil, errors.New("ChainFile required")
+	}
+	if (option.CertFile == "") != (option.KeyFile == "") {
+		return nil, errors.New("CertFile and KeyFile required both")
+	}
+
+	r, err := newReloader(logger, option, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	r.config = &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if material := r.current(); material.cert != nil {
+				return material.cert, nil
+			}
+			return new(tls.Certificate), nil
+		},
+		// the roots swapped, so verified by VerifyConnection instead
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("no server certificate")
+			}
+
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       serverName,
+				Roots:         r.current().pool,
+				Intermediates: intermediates,
+			})
+			return err
+		},
+		ServerName: serverName,
+		CipherSuites: []uint16{
+			tls.TLS_AES_128_GCM_SHA256,
+			tls.TLS_AES_256_GCM_SHA384,
+			tls.TLS_CHACHA20_POLY1305_SHA256,
+		},
+		PreferServerCipherSuites: true,
+		MinVersion:               tls.VersionTLS13,
+		CurvePreferences:         []tls.CurveID{tls.X25519},
+	}
+
+	go r.poll()
+	return r, nil
+}
+
+func newReloader(logger *zap.Logger, option ReloadOption, now func() time.Time) (*Reloader, error) {
+	if logger == nil {
+		return nil, errors.New("logger required")
+	}
+	if option.Interval <= 0 {
+		option.Interval = defaultReloadInterval
+	}
+	if option.WarnBefore <= 0 {
+		option.WarnBefore = defaultWarnBefore
+	}
+
+	registerer := option.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	expiry := expiryGauge
+	if err := registerer.Register(expiry); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return nil, errors.Wrap(err, "register expiry metric err")
+		}
+		expiry = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Reloader{
+		logger: logger,
+		option: option,
+		now:    now,
+		expiry: expiry,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if _, err := r.Reload(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Credentials the transport credentials of grpc, e.g. server.WithCredential(reloader.Credentials())
+func (r *Reloader) Credentials() credentials.TransportCredentials {
+	return credentials.NewTLS(r.config)
+}
+
+// Close stop polling the files
+func (r *Reloader) Close() {
+	r.cancel()
+}
+
+func (r *Reloader) current() *material {
+	return r.material.Load().(*material)
+}
+
+func (r *Reloader) poll() {
+	ticker := time.NewTicker(r.option.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				r.logger.Error("reload tls credential err, the last one kept", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reload the files if their checksum changed, the last ones kept if illegal
+func (r *Reloader) Reload() (reloaded bool, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	files := make(map[string][]byte)
+	digest := sha256.New()
+	for _, file := range []string{r.option.CertFile, r.option.KeyFile, r.option.ChainFile} {
+		if file == "" {
+			continue
+		}
+
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return false, errors.Wrapf(err, "read %s err", file)
+		}
+
+		files[file] = raw
+		digest.Write([]byte(file))
+		digest.Write(raw)
+	}
+
+	checksum := digest.Sum(nil)
+	if bytes.Equal(checksum, r.checksum) {
+		r.warnExpiring()
+		return false, nil
+	}
+
+	material := new(material)
+	if r.option.CertFile != "" {
+		cert, err := tls.X509KeyPair(files[r.option.CertFile], files[r.option.KeyFile])
+		if err != nil {
+			return false, errors.Wrapf(err, "%s or %s illegal", r.option.CertFile, r.option.KeyFile)
+		}
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return false, errors.Wrapf(err, "%s illegal", r.option.CertFile)
+		}
+
+		material.cert = &cert
+	}
+
+	if r.option.ChainFile != "" {
+		material.pool = x509.NewCertPool()
+		if !material.pool.AppendCertsFromPEM(files[r.option.ChainFile]) {
+			return false, errors.Errorf("%s illegal", r.option.ChainFile)
+		}
+	}
+
+	r.material.Store(material)
+	r.checksum = checksum
+	r.warnedAt = time.Time{}
+
+	if material.cert != nil {
+		r.expiry.WithLabelValues(r.option.CertFile).Set(float64(material.cert.Leaf.NotAfter.Unix()))
+		r.logger.Info("tls credential loaded", zap.String("file", r.option.CertFile), zap.Time("not_after", material.cert.Leaf.NotAfter))
+	}
+	r.warnExpiring()
+
+	return true, nil
+}
+
+// warnExpiring log a warning if the cert expires in WarnBefore
+func (r *Reloader) warnExpiring() {
+	material := r.current()
+	if material.cert == nil {
+		return
+	}
+
+	now := r.now()
+	notAfter := material.cert.Leaf.NotAfter
+	if notAfter.Sub(now) > r.option.WarnBefore || (!r.warnedAt.IsZero() && now.Sub(r.warnedAt) < warnInterval) {
+		return
+	}
+
+	r.warnedAt = now
+	r.logger.Warn("tls certificate expiring",
+		zap.String("file", r.option.CertFile),
+		zap.Time("not_after", notAfter),
+		zap.Duration("remaining", notAfter.Sub(now)),
+	)
+}
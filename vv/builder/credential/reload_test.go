@@ -0,0 +1,246 @@
+package credential
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+func newIssuer(t *testing.T) *issuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "vv test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour * 24 * 365),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &issuer{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue a leaf cert of name, returned the pem of cert and key
+func (i *issuer) issue(t *testing.T, name string, validity time.Duration) (certPEM, keyPEM []byte, serialNumber int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, i.cert, &key.PublicKey, i.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := x509.MarshalECPrivateKey(key)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: raw}),
+		serial
+}
+
+func write(t *testing.T, file string, raw []byte) {
+	if err := os.WriteFile(file, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake over loopback, the serial number of server cert returned
+func handshake(server, client *Reloader) (int64, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+
+		serverErr <- tls.Server(conn, server.config).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), client.config)
+	if err != nil {
+		<-serverErr
+		return 0, err
+	}
+	defer conn.Close()
+
+	if err = <-serverErr; err != nil {
+		return 0, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestReloader(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	ca := newIssuer(t)
+	serverCert, serverKey, first := ca.issue(t, "orders.svc", time.Hour*24*3) // expiring
+	clientCert, clientKey, _ := ca.issue(t, "payments.svc", time.Hour*24*90)
+
+	write(t, file("ca.pem"), ca.pem)
+	write(t, file("server.pem"), serverCert)
+	write(t, file("server.key"), serverKey)
+	write(t, file("client.pem"), clientCert)
+	write(t, file("client.key"), clientKey)
+
+	core, logs := observer.New(zap.InfoLevel)
+	registry := prometheus.NewRegistry()
+
+	server, err := NewReloadingServer(zap.New(core), ReloadOption{
+		CertFile:                   file("server.pem"),
+		KeyFile:                    file("server.key"),
+		ChainFile:                  file("ca.pem"),
+		RequireAndVerifyClientCert: true,
+		Interval:                   time.Hour,
+		Registerer:                 registry,
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer server.Close()
+
+	client, err := NewReloadingClient(zap.NewNop(), ReloadOption{
+		CertFile:   file("client.pem"),
+		KeyFile:    file("client.key"),
+		ChainFile:  file("ca.pem"),
+		ServerName: "orders.svc",
+		Interval:   time.Hour,
+		Registerer: registry,
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer client.Close()
+
+	serialNumber, err := handshake(server, client)
+	assert.NoError(err)
+	assert.Equal(first, serialNumber)
+
+	assert.Equal(float64(server.current().cert.Leaf.NotAfter.Unix()), testutil.ToFloat64(server.expiry.WithLabelValues(file("server.pem"))))
+	assert.Len(logs.FilterMessage("tls certificate expiring").All(), 1)
+
+	reloaded, err := server.Reload() // unchanged
+	assert.NoError(err)
+	assert.False(reloaded)
+	assert.Len(logs.FilterMessage("tls certificate expiring").All(), 1) // warned once a day
+
+	// rotated
+	serverCert, serverKey, second := ca.issue(t, "orders.svc", time.Hour*24*90)
+	write(t, file("server.pem"), serverCert)
+
+	_, err = server.Reload() // key not written yet
+	assert.Error(err)
+	serialNumber, err = handshake(server, client)
+	assert.NoError(err)
+	assert.Equal(first, serialNumber) // the last one kept
+
+	write(t, file("server.key"), serverKey)
+	reloaded, err = server.Reload()
+	assert.NoError(err)
+	assert.True(reloaded)
+
+	serialNumber, err = handshake(server, client)
+	assert.NoError(err)
+	assert.Equal(second, serialNumber)
+	assert.Equal(float64(server.current().cert.Leaf.NotAfter.Unix()), testutil.ToFloat64(server.expiry.WithLabelValues(file("server.pem"))))
+
+	// the chain of client rotated to another ca
+	another := newIssuer(t)
+	write(t, file("ca.pem"), another.pem)
+	client.Reload()
+
+	_, err = handshake(server, client)
+	assert.Error(err)
+
+	// the client cert not issued by the chain of server
+	write(t, file("ca.pem"), ca.pem)
+	client.Reload()
+	clientCert, clientKey, _ = another.issue(t, "payments.svc", time.Hour*24*90)
+	write(t, file("client.pem"), clientCert)
+	write(t, file("client.key"), clientKey)
+	client.Reload()
+
+	_, err = handshake(server, client)
+	assert.Error(err)
+
+	// picked up by polling
+	polled, err := NewReloadingServer(zap.NewNop(), ReloadOption{
+		CertFile:   file("server.pem"),
+		KeyFile:    file("server.key"),
+		Interval:   time.Millisecond * 10,
+		Registerer: registry,
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer polled.Close()
+
+	serverCert, serverKey, third := ca.issue(t, "orders.svc", time.Hour*24*90)
+	write(t, file("server.key"), serverKey)
+	write(t, file("server.pem"), serverCert)
+	assert.Eventually(func() bool {
+		return polled.current().cert.Leaf.SerialNumber.Int64() == third
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = NewReloadingServer(zap.NewNop(), ReloadOption{CertFile: file("missing.pem"), KeyFile: file("server.key")})
+	assert.Error(err)
+	_, err = NewReloadingClient(zap.NewNop(), ReloadOption{ChainFile: file("ca.pem")})
+	assert.Error(err)
+}
@@ -11,14 +11,15 @@ import (
 	"time"
 
 	"github.com/bluekaki/pkg/id"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/configs"
+	"github.com/bluekaki/pkg/vv/internal/health"
 	"github.com/bluekaki/pkg/vv/internal/interceptor"
 	"github.com/bluekaki/pkg/vv/internal/pkg/marshaler"
 	"github.com/bluekaki/pkg/vv/internal/pkg/multipart"
 	"github.com/bluekaki/pkg/vv/proposal"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
-	"github.com/rs/cors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -46,15 +47,39 @@ func RegisteWhitelistingValidator(name string, handler proposal.WhitelistingHand
 	interceptor.RegisteWhitelistingValidator(name, handler)
 }
 
+// RegisteReadinessCheck a named readiness check, the /readyz is SERVING only if all checks passed
+func RegisteReadinessCheck(name string, check proposal.ReadinessCheck) {
+	health.RegisteReadinessCheck(name, check)
+}
+
+// RegisteRedactedHeaders the metadata masked in journal and alert, Authorization and Authorization-Proxy by default
+func RegisteRedactedHeaders(headers ...string) {
+	interceptor.SetRedactedHeaders(headers)
+}
+
 // Option some options for build a gateway
 type Option func(*option)
 
 type option struct {
-	credential  credentials.TransportCredentials
-	keepalive   *keepalive.ClientParameters
-	dialTimeout time.Duration
-	metrics     func(http.Handler)
-	projectName string
+	credential    credentials.TransportCredentials
+	keepalive     *keepalive.ClientParameters
+	dialTimeout   time.Duration
+	metrics       func(http.Handler)
+	metricsConfig *proposal.MetricsConfig
+	projectName   string
+	links         []interceptor.ClientLink
+	tracer        *trace.Tracer
+
+	readinessEndpoints []string
+	catalog            bool
+	cors               *proposal.CORSPolicy
+	idempotencyStore   proposal.IdempotencyStore
+	idempotencyTTL     time.Duration
+	cacheStore         proposal.CacheStore
+	bridge             *proposal.BridgeConfig
+
+	journalConfig *proposal.JournalConfig
+	journalSinks  []proposal.JournalSink
 }
 
 // WithCredential setup credential for tls
@@ -85,6 +110,13 @@ func WithPrometheus(metrics func(http.Handler)) Option {
 	}
 }
 
+// WithMetrics customize the prometheus metrics, e.g. registry, namespace, buckets and extra labels
+func WithMetrics(config *proposal.MetricsConfig) Option {
+	return func(opt *option) {
+		opt.metricsConfig = config
+	}
+}
+
 // WithProjectName add project name into alert message
 func WithProjectName(name string) Option {
 	return func(opt *option) {
@@ -92,11 +124,125 @@ func WithProjectName(name string) Option {
 	}
 }
 
+// WithTraceExporter enable tracing, spans are propagated by w3c traceparent and sent to exporter
+func WithTraceExporter(exporter trace.Exporter) Option {
+	return func(opt *option) {
+		if exporter != nil {
+			opt.tracer = trace.NewTracer(exporter)
+		}
+	}
+}
+
+// WithReadinessEndpoint add the grpc.health.v1 of a backend endpoint into /readyz, the connection closed on Close
+func WithReadinessEndpoint(endpoint string) Option {
+	return func(opt *option) {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			opt.readinessEndpoints = append(opt.readinessEndpoints, endpoint)
+		}
+	}
+}
+
+// WithCatalog expose the method catalog on GET /catalog, lists every method with its http rule, interceptor options and validator constraints
+func WithCatalog() Option {
+	return func(opt *option) {
+		opt.catalog = true
+	}
+}
+
+// WithCORS setup the cors policy instead of allowing all, options.cors of method overrides it
+func WithCORS(policy *proposal.CORSPolicy) Option {
+	return func(opt *option) {
+		opt.cors = policy
+	}
+}
+
+// WithIdempotencyStore replay the responses of options.idempotent methods on gateway, keyed by Idempotency-Key
+// plus the authorization headers; responses kept for ttl (default 24h), and the store closed by the caller
+func WithIdempotencyStore(store proposal.IdempotencyStore, ttl time.Duration) Option {
+	return func(opt *option) {
+		opt.idempotencyStore = store
+		opt.idempotencyTTL = ttl
+	}
+}
+
+// WithCache cache the successful responses of GET declared options.cache in store, e.g. cache.NewLRUStore
+// or cache.NewRedisStore shared with the servers invalidating them; the store closed by the caller
+func WithCache(store proposal.CacheStore) Option {
+	return func(opt *option) {
+		opt.cacheStore = store
+	}
+}
+
+// WithStreamBridge expose the streaming methods over websocket and server-sent events besides the chunked http,
+// nil config means the default ping/pong keepalive and back-pressure limits
+func WithStreamBridge(config *proposal.BridgeConfig) Option {
+	return func(opt *option) {
+		if config == nil {
+			config = new(proposal.BridgeConfig)
+		}
+		opt.bridge = config
+	}
+}
+
+// WithJournalSink write journals into sinks besides the logger, asynchronously in batches; flushed on Close
+func WithJournalSink(config *proposal.JournalConfig, sinks ...proposal.JournalSink) Option {
+	return func(opt *option) {
+		opt.journalConfig = config
+		opt.journalSinks = append(opt.journalSinks, sinks...)
+	}
+}
+
+// WithUnaryInterceptor insert a custom unary interceptor before or after one of the built-in stages,
+// stages are journal and whitelisting in order.
+func WithUnaryInterceptor(name string, position proposal.Position, stage proposal.Stage, unary grpc.UnaryClientInterceptor) Option {
+	return func(opt *option) {
+		if unary != nil {
+			opt.links = append(opt.links, interceptor.ClientLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Unary:     unary,
+			})
+		}
+	}
+}
+
+// WithStreamInterceptor insert a custom stream interceptor before or after one of the built-in stages,
+// stages are journal and whitelisting in order.
+func WithStreamInterceptor(name string, position proposal.Position, stage proposal.Stage, stream grpc.StreamClientInterceptor) Option {
+	return func(opt *option) {
+		if stream != nil {
+			opt.links = append(opt.links, interceptor.ClientLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Stream:    stream,
+			})
+		}
+	}
+}
+
+// UnaryChain the names of unary interceptors in order, built-in stages included
+func UnaryChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ClientChain(interceptor.Gateway, opt.links, true)
+}
+
+// StreamChain the names of stream interceptors in order, built-in stages included
+func StreamChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ClientChain(interceptor.Gateway, opt.links, false)
+}
+
 // RegisterEndpoint the only entrance for register backend endpoints
 type RegisterEndpoint func(mux *runtime.ServeMux, opts []grpc.DialOption) error
 
 // NewCorsHandler create a cors http handler
-func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register RegisterEndpoint, options ...Option) http.Handler {
+func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register RegisterEndpoint, options ...Option) CorsHandler {
 	if logger == nil {
 		panic("logger required")
 	}
@@ -129,7 +275,7 @@ func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register
 		runtime.WithIncomingHeaderMatcher(runtime.DefaultHeaderMatcher),
 		runtime.WithOutgoingHeaderMatcher(runtime.DefaultHeaderMatcher),
 		runtime.WithMetadata(annotator(logger)),
-		runtime.WithErrorHandler(runtime.DefaultHTTPErrorHandler),
+		runtime.WithErrorHandler(interceptor.HTTPErrorHandler),
 		runtime.WithStreamErrorHandler(runtime.DefaultStreamErrorHandler),
 		runtime.WithRoutingErrorHandler(runtime.DefaultRoutingErrorHandler),
 
@@ -137,8 +283,34 @@ func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register
 		runtime.WithMarshalerOption("application/x-www-form-urlencoded", jsonPbMarshaler),
 		runtime.WithMarshalerOption("application/json", jsonPbMarshaler),
 		runtime.WithMarshalerOption("multipart/form-data", formDataMarshaler),
+		runtime.WithMarshalerOption(marshaler.ChunkMIME, marshaler.NewChunkMarshaler()),
 	)
 
+	var metrics *interceptor.Metrics
+	if opt.metrics != nil || opt.metricsConfig != nil {
+		metrics = interceptor.NewMetrics(interceptor.Gateway, opt.projectName, opt.metricsConfig)
+		if opt.metrics != nil {
+			opt.metrics(metrics.Handler())
+		}
+	}
+
+	var journals *interceptor.JournalWriter
+	if len(opt.journalSinks) > 0 {
+		journals = interceptor.NewJournalWriter(logger, opt.journalConfig, opt.journalSinks)
+	}
+
+	var idempotency *interceptor.Idempotency
+	if opt.idempotencyStore != nil {
+		idempotency = interceptor.NewIdempotency(opt.idempotencyStore, opt.idempotencyTTL, logger)
+	}
+
+	stages := interceptor.GatewayOptions{
+		Idempotency: idempotency,
+		Tracer:      opt.tracer,
+		Journals:    journals,
+		Links:       opt.links,
+	}
+
 	dialOptions := []grpc.DialOption{
 		grpc.WithResolvers(dns.NewBuilder()),
 		grpc.WithTimeout(dialTimeout),
@@ -146,15 +318,22 @@ func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register
 		grpc.WithMaxMsgSize(configs.MaxMsgSize),
 		grpc.WithMaxHeaderListSize(configs.MaxMsgSize),
 		grpc.WithKeepaliveParams(*kacp),
-		grpc.WithUnaryInterceptor(interceptor.UnaryGatewayInterceptor(logger, notify, opt.metrics, opt.projectName)),
-		grpc.WithStreamInterceptor(interceptor.StreamGatewayInterceptor(logger, notify, opt.metrics, opt.projectName)),
+		grpc.WithUnaryInterceptor(interceptor.UnaryGatewayInterceptor(logger, notify, metrics, opt.projectName, stages)),
+		grpc.WithStreamInterceptor(interceptor.StreamGatewayInterceptor(logger, notify, metrics, opt.projectName, stages)),
 		grpc.WithDefaultServiceConfig(configs.ServiceConfig),
 	}
 
+	healthDialOptions := []grpc.DialOption{
+		grpc.WithResolvers(dns.NewBuilder()),
+		grpc.WithKeepaliveParams(*kacp),
+	}
+
 	if opt.credential == nil {
 		dialOptions = append(dialOptions, grpc.WithInsecure())
+		healthDialOptions = append(healthDialOptions, grpc.WithInsecure())
 	} else {
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(opt.credential))
+		healthDialOptions = append(healthDialOptions, grpc.WithTransportCredentials(opt.credential))
 	}
 
 	if err := register(mux, dialOptions); err != nil {
@@ -162,9 +341,73 @@ func NewCorsHandler(logger *zap.Logger, notify proposal.NotifyHandler, register
 	}
 	interceptor.ResloveFileDescriptor(interceptor.Gateway)
 
-	return cors.AllowAll().Handler(mux)
+	readiness := make(map[string]proposal.ReadinessCheck)
+	var readinessConns []*grpc.ClientConn
+	for _, endpoint := range opt.readinessEndpoints {
+		conn, err := grpc.Dial(endpoint, healthDialOptions...)
+		if err != nil {
+			panic(err)
+		}
+
+		readiness["endpoint:"+endpoint] = health.EndpointCheck(conn)
+		readinessConns = append(readinessConns, conn)
+	}
+
+	if err := mux.HandlePath(http.MethodGet, health.HealthzPath, health.Healthz); err != nil {
+		panic(err)
+	}
+	if err := mux.HandlePath(http.MethodGet, health.ReadyzPath, health.Readyz(readiness)); err != nil {
+		panic(err)
+	}
+
+	if opt.catalog {
+		if err := mux.HandlePath(http.MethodGet, interceptor.CatalogPath, interceptor.CatalogHandler()); err != nil {
+			panic(err)
+		}
+	}
+
+	var handler http.Handler = interceptor.Transfer(logger)(mux)
+	if opt.cacheStore != nil {
+		handler = interceptor.Cache(logger, metrics, opt.cacheStore)(handler)
+	}
+	if opt.bridge != nil {
+		handler = interceptor.StreamBridge(logger, opt.bridge)(handler)
+	}
+
+	return &corsHandler{
+		Handler:        interceptor.CORS(logger, journals, opt.cors)(handler),
+		idempotency:    idempotency,
+		journals:       journals,
+		readinessConns: readinessConns,
+	}
+}
+
+// CorsHandler cors http handler
+type CorsHandler interface {
+	http.Handler
+	Close()
+	t()
 }
 
+type corsHandler struct {
+	http.Handler
+	idempotency    *interceptor.Idempotency
+	journals       *interceptor.JournalWriter
+	readinessConns []*grpc.ClientConn
+}
+
+// Close flush the journals and close the connections of readiness probes, called after the http server shut down
+func (c *corsHandler) Close() {
+	c.journals.Close()
+	c.idempotency.Close()
+
+	for _, conn := range c.readinessConns {
+		conn.Close()
+	}
+}
+
+func (c *corsHandler) t() {}
+
 func annotator(logger *zap.Logger) func(ctx context.Context, req *http.Request) metadata.MD {
 
 	return func(ctx context.Context, req *http.Request) metadata.MD {
@@ -173,12 +416,16 @@ func annotator(logger *zap.Logger) func(ctx context.Context, req *http.Request)
 			journalID = id.JournalID()
 		}
 
-		body, octet, err := multipart.Parse(req)
-		if err != nil {
-			logger.Error(fmt.Sprintf("parse multipart err [Journal-Id: %s]", journalID), zap.Error(err))
-		}
+		var body []byte
+		var octet bool
+		if !interceptor.StreamingBody(req.Context()) { // read by the stream
+			var err error
+			if body, octet, err = multipart.Parse(req); err != nil {
+				logger.Error(fmt.Sprintf("parse multipart err [Journal-Id: %s]", journalID), zap.Error(err))
+			}
 
-		req.Body = io.NopCloser(bytes.NewBuffer(body)) // re-construct req body
+			req.Body = io.NopCloser(bytes.NewBuffer(body)) // re-construct req body
+		}
 
 		return metadata.Pairs(
 			interceptor.JournalID, journalID,
@@ -195,6 +442,9 @@ func annotator(logger *zap.Logger) func(ctx context.Context, req *http.Request)
 			}(),
 			interceptor.XForwardedFor, req.Header.Get(interceptor.XForwardedFor),
 			interceptor.XForwardedHost, req.Header.Get(interceptor.XForwardedHost),
+			interceptor.Traceparent, req.Header.Get(trace.Traceparent),
+			interceptor.Tracestate, req.Header.Get(trace.Tracestate),
+			interceptor.IdempotencyKey, req.Header.Get(interceptor.IdempotencyKey),
 			interceptor.OctetStream, func() string {
 				if octet {
 					return "base64"
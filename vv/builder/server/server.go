@@ -1,19 +1,26 @@
 package server
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/bluekaki/pkg/rate"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/configs"
+	"github.com/bluekaki/pkg/vv/internal/health"
 	"github.com/bluekaki/pkg/vv/internal/interceptor"
 	"github.com/bluekaki/pkg/vv/proposal"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -28,18 +35,35 @@ var (
 		Time:              2 * time.Second,
 		Timeout:           1500 * time.Millisecond,
 	}
+
+	defaultShutdownDelay = 3 * time.Second
 )
 
-// RegisteAuthorizationValidator userinfo handler for interceptor options.authorization
+// RegisteAuthorizationValidator userinfo handler for interceptor options.authorization, e.g. jwt.NewValidator(config).UserinfoHandler
 func RegisteAuthorizationValidator(name string, handler proposal.UserinfoHandler) {
 	interceptor.RegisteAuthorizationValidator(name, handler)
 }
 
+// RegisteAuthorizer authorizer for interceptor options.roles and options.permissions, named as the authorization validator
+func RegisteAuthorizer(name string, authorizer proposal.Authorizer) {
+	interceptor.RegisteAuthorizer(name, authorizer)
+}
+
 // RegisteAuthorizationProxyValidator signature handler for interceptor options.authorization_proxy
 func RegisteAuthorizationProxyValidator(name string, handler proposal.SignatureHandler) {
 	interceptor.RegisteAuthorizationProxyValidator(name, handler)
 }
 
+// RegisteReadinessCheck a named readiness check, the grpc.health.v1 service is SERVING only if all checks passed
+func RegisteReadinessCheck(name string, check proposal.ReadinessCheck) {
+	health.RegisteReadinessCheck(name, check)
+}
+
+// RegisteRedactedHeaders the metadata masked in journal and alert, Authorization and Authorization-Proxy by default
+func RegisteRedactedHeaders(headers ...string) {
+	interceptor.SetRedactedHeaders(headers)
+}
+
 // Option some options for build a server
 type Option func(*option)
 
@@ -48,9 +72,22 @@ type option struct {
 	enforcementPolicy       *keepalive.EnforcementPolicy
 	keepalive               *keepalive.ServerParameters
 	metrics                 func(http.Handler)
+	metricsConfig           *proposal.MetricsConfig
 	projectName             string
 	fds                     []protoreflect.FileDescriptor
 	disableMessageValitator bool
+	collectAllViolations    bool
+	links                   []interceptor.ServerLink
+	rateLimitRedis          rate.Redis
+	trustedProxies          []*net.IPNet
+	idempotencyStore        proposal.IdempotencyStore
+	idempotencyTTL          time.Duration
+	tracer                  *trace.Tracer
+	readinessInterval       time.Duration
+	shutdownDelay           *time.Duration
+	reflection              bool
+	journalConfig           *proposal.JournalConfig
+	journalSinks            []proposal.JournalSink
 }
 
 // WithCredential setup credential for tls
@@ -81,6 +118,13 @@ func WithPrometheus(metrics func(http.Handler)) Option {
 	}
 }
 
+// WithMetrics customize the prometheus metrics, e.g. registry, namespace, buckets and extra labels
+func WithMetrics(config *proposal.MetricsConfig) Option {
+	return func(opt *option) {
+		opt.metricsConfig = config
+	}
+}
+
 // WithProjectName add project name into alert message
 func WithProjectName(name string) Option {
 	return func(opt *option) {
@@ -104,6 +148,128 @@ func WithDisableMessageValitator() Option {
 	}
 }
 
+// WithCollectAllViolations validate request message by ValidateAll, the violations returned as google.rpc.BadRequest
+func WithCollectAllViolations() Option {
+	return func(opt *option) {
+		opt.collectAllViolations = true
+	}
+}
+
+// WithRateLimitRedis setup redis for interceptor options.rate_limit, required if any rate_limit declared
+func WithRateLimitRedis(redis rate.Redis) Option {
+	return func(opt *option) {
+		opt.rateLimitRedis = redis
+	}
+}
+
+// WithTrustedProxies the cidrs (e.g. of gateway and load balancer) whose x-forwarded-for believed by options.rate_limit,
+// otherwise callers distinguished by the peer address; panic if illegal
+func WithTrustedProxies(cidrs ...string) Option {
+	return func(opt *option) {
+		for _, cidr := range cidrs {
+			_, proxy, err := net.ParseCIDR(cidr)
+			if err != nil {
+				panic(fmt.Sprintf("trusted proxy: %s illegal", cidr))
+			}
+			opt.trustedProxies = append(opt.trustedProxies, proxy)
+		}
+	}
+}
+
+// WithIdempotencyStore setup store for interceptor options.idempotent, required if any idempotent declared;
+// responses kept for ttl (default 24h), and the store closed by GracefulStop
+func WithIdempotencyStore(store proposal.IdempotencyStore, ttl time.Duration) Option {
+	return func(opt *option) {
+		opt.idempotencyStore = store
+		opt.idempotencyTTL = ttl
+	}
+}
+
+// WithTraceExporter enable tracing, spans are propagated by w3c traceparent and sent to exporter
+func WithTraceExporter(exporter trace.Exporter) Option {
+	return func(opt *option) {
+		if exporter != nil {
+			opt.tracer = trace.NewTracer(exporter)
+		}
+	}
+}
+
+// WithReadinessInterval setup the interval of readiness checks, default 5s
+func WithReadinessInterval(interval time.Duration) Option {
+	return func(opt *option) {
+		opt.readinessInterval = interval
+	}
+}
+
+// WithShutdownDelay setup the delay between flipping the health to NOT_SERVING and draining on GracefulStop,
+// for the load balancers to stop routing new calls, default 3s
+func WithShutdownDelay(delay time.Duration) Option {
+	return func(opt *option) {
+		opt.shutdownDelay = &delay
+	}
+}
+
+// WithReflection register the grpc server reflection service, e.g. for grpcurl
+func WithReflection() Option {
+	return func(opt *option) {
+		opt.reflection = true
+	}
+}
+
+// WithJournalSink write journals into sinks besides the logger, asynchronously in batches; flushed on GracefulStop
+func WithJournalSink(config *proposal.JournalConfig, sinks ...proposal.JournalSink) Option {
+	return func(opt *option) {
+		opt.journalConfig = config
+		opt.journalSinks = append(opt.journalSinks, sinks...)
+	}
+}
+
+// WithUnaryInterceptor insert a custom unary interceptor before or after one of the built-in stages,
+// stages are journal, validation, peer_certificate, authorization, authorization_proxy and rate_limit in order.
+func WithUnaryInterceptor(name string, position proposal.Position, stage proposal.Stage, unary grpc.UnaryServerInterceptor) Option {
+	return func(opt *option) {
+		if unary != nil {
+			opt.links = append(opt.links, interceptor.ServerLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Unary:     unary,
+			})
+		}
+	}
+}
+
+// WithStreamInterceptor insert a custom stream interceptor before or after one of the built-in stages,
+// stages are journal, validation, peer_certificate, authorization, authorization_proxy and rate_limit in order.
+func WithStreamInterceptor(name string, position proposal.Position, stage proposal.Stage, stream grpc.StreamServerInterceptor) Option {
+	return func(opt *option) {
+		if stream != nil {
+			opt.links = append(opt.links, interceptor.ServerLink{
+				Placement: interceptor.Placement{Name: name, Stage: stage, Position: position},
+				Stream:    stream,
+			})
+		}
+	}
+}
+
+// UnaryChain the names of unary interceptors in order, built-in stages included
+func UnaryChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ServerChain(opt.links, true)
+}
+
+// StreamChain the names of stream interceptors in order, built-in stages included
+func StreamChain(options ...Option) []string {
+	opt := new(option)
+	for _, f := range options {
+		f(opt)
+	}
+
+	return interceptor.ServerChain(opt.links, false)
+}
+
 // RegisterEndpoint the only entrance for register service
 type RegisterEndpoint func(server *grpc.Server)
 
@@ -134,13 +300,50 @@ func New(logger *zap.Logger, notify proposal.NotifyHandler, register RegisterEnd
 		keepalive = opt.keepalive
 	}
 
+	shutdownDelay := defaultShutdownDelay
+	if opt.shutdownDelay != nil {
+		shutdownDelay = *opt.shutdownDelay
+	}
+
+	var limiter *interceptor.RateLimiter
+	if opt.rateLimitRedis != nil {
+		limiter = interceptor.NewRateLimiter(opt.rateLimitRedis, opt.trustedProxies, logger)
+	}
+
+	var idempotency *interceptor.Idempotency
+	if opt.idempotencyStore != nil {
+		idempotency = interceptor.NewIdempotency(opt.idempotencyStore, opt.idempotencyTTL, logger)
+	}
+
+	var metrics *interceptor.Metrics
+	if opt.metrics != nil || opt.metricsConfig != nil {
+		metrics = interceptor.NewMetrics(interceptor.Server, opt.projectName, opt.metricsConfig)
+		if opt.metrics != nil {
+			opt.metrics(metrics.Handler())
+		}
+	}
+
+	var journals *interceptor.JournalWriter
+	if len(opt.journalSinks) > 0 {
+		journals = interceptor.NewJournalWriter(logger, opt.journalConfig, opt.journalSinks)
+	}
+
+	stages := interceptor.ServerOptions{
+		CollectAllViolations: opt.collectAllViolations,
+		Limiter:              limiter,
+		Idempotency:          idempotency,
+		Tracer:               opt.tracer,
+		Journals:             journals,
+		Links:                opt.links,
+	}
+
 	serverOptions := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(configs.MaxMsgSize),
 		grpc.MaxHeaderListSize(configs.MaxMsgSize),
 		grpc.KeepaliveEnforcementPolicy(*enforcementPolicy),
 		grpc.KeepaliveParams(*keepalive),
-		grpc.UnaryInterceptor(interceptor.UnaryServerInterceptor(logger, notify, opt.metrics, opt.projectName, opt.disableMessageValitator)),
-		grpc.StreamInterceptor(interceptor.StreamServerInterceptor(logger, notify, opt.metrics, opt.projectName, opt.disableMessageValitator)),
+		grpc.UnaryInterceptor(interceptor.UnaryServerInterceptor(logger, notify, metrics, opt.projectName, opt.disableMessageValitator, stages)),
+		grpc.StreamInterceptor(interceptor.StreamServerInterceptor(logger, notify, metrics, opt.projectName, opt.disableMessageValitator, stages)),
 	}
 
 	if opt.credential != nil {
@@ -148,12 +351,43 @@ func New(logger *zap.Logger, notify proposal.NotifyHandler, register RegisterEnd
 	}
 
 	srv := &grpcServer{
-		server: grpc.NewServer(serverOptions...),
+		server:      grpc.NewServer(serverOptions...),
+		limiter:     limiter,
+		idempotency: idempotency,
+		health:      health.NewServer(logger, opt.readinessInterval),
+		journals:    journals,
+
+		shutdownDelay: shutdownDelay,
 	}
 
 	register(srv.server)
+
+	var services []string
+	for name := range srv.server.GetServiceInfo() {
+		services = append(services, name)
+	}
+
+	if _, ok := srv.server.GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; !ok {
+		healthpb.RegisterHealthServer(srv.server, srv.health)
+	}
+	srv.health.Start(services)
+
+	ignores := append(opt.fds, healthpb.File_grpc_health_v1_health_proto)
+	if opt.reflection {
+		reflection.Register(srv.server)
+		ignores = append(ignores, reflectionpb.File_grpc_reflection_v1alpha_reflection_proto)
+	}
+
 	interceptor.ResloveFileDescriptor(interceptor.Server)
-	interceptor.IgnoreFileDescriptor(opt.fds)
+	interceptor.IgnoreFileDescriptor(ignores)
+
+	if limiter == nil && interceptor.RateLimitDeclared() {
+		panic("rate_limit declared but no redis set, see WithRateLimitRedis")
+	}
+
+	if idempotency == nil && interceptor.IdempotentDeclared() {
+		panic("idempotent declared but no store set, see WithIdempotencyStore")
+	}
 
 	return srv
 }
@@ -166,15 +400,30 @@ type GRPCServer interface {
 }
 
 type grpcServer struct {
-	server *grpc.Server
+	server      *grpc.Server
+	limiter     *interceptor.RateLimiter
+	idempotency *interceptor.Idempotency
+	health      *health.Server
+	journals    *interceptor.JournalWriter
+
+	shutdownDelay time.Duration
 }
 
 func (g *grpcServer) Serve(lis net.Listener) error {
 	return g.server.Serve(lis)
 }
 
+// GracefulStop flip the health to NOT_SERVING, still serving for the shutdown delay, then drain and flush the journals
 func (g *grpcServer) GracefulStop() {
+	g.health.Shutdown()
+	time.Sleep(g.shutdownDelay)
 	g.server.GracefulStop()
+	g.journals.Close()
+
+	if g.limiter != nil {
+		g.limiter.Close()
+	}
+	g.idempotency.Close()
 }
 
 func (g *grpcServer) t() {}
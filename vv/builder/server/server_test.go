@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChain(t *testing.T) {
+	assert := assert.New(t)
+
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+	stream := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, stream)
+	}
+
+	options := []Option{
+		WithUnaryInterceptor("tenant", proposal.Before, proposal.StageJournal, unary),
+		WithUnaryInterceptor("audit", proposal.After, proposal.StageAuthorization, unary),
+		WithUnaryInterceptor("feature", proposal.Before, proposal.StageAuthorizationProxy, unary),
+		WithUnaryInterceptor("trace", proposal.Before, proposal.StageJournal, unary),
+		WithStreamInterceptor("audit", proposal.After, proposal.StageAuthorizationProxy, stream),
+	}
+
+	assert.Equal([]string{"tenant", "trace", "journal", "validation", "peer_certificate", "authorization", "audit", "feature", "authorization_proxy", "rate_limit"}, UnaryChain(options...))
+	assert.Equal([]string{"journal", "validation", "peer_certificate", "authorization", "authorization_proxy", "audit", "rate_limit"}, StreamChain(options...))
+
+	assert.Panics(func() {
+		UnaryChain(WithUnaryInterceptor("ip", proposal.Before, proposal.StageWhitelisting, unary))
+	})
+	assert.Panics(func() {
+		UnaryChain(WithUnaryInterceptor("journal", proposal.After, proposal.StageJournal, unary))
+	})
+}
+
+func TestGracefulStop(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := New(zap.NewNop(), func(*proposal.AlertMessage) {}, func(*grpc.Server) {}, WithShutdownDelay(time.Millisecond*300))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(err) {
+		return
+	}
+	go srv.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(err) {
+		return
+	}
+	defer conn.Close()
+
+	check := func() (healthpb.HealthCheckResponse_ServingStatus, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, new(healthpb.HealthCheckRequest))
+		return resp.GetStatus(), err
+	}
+
+	status, err := check()
+	assert.NoError(err)
+	assert.Equal(healthpb.HealthCheckResponse_SERVING, status)
+
+	ts := time.Now()
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	// flipped first, still served during the delay
+	assert.Eventually(func() bool {
+		status, err := check()
+		return err == nil && status == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Millisecond*200, time.Millisecond*10)
+
+	<-stopped
+	assert.GreaterOrEqual(time.Since(ts), time.Millisecond*300)
+
+	_, err = check()
+	assert.Error(err)
+}
@@ -9,4 +9,5 @@ protoc -I/usr/local/include -I. --proto_path=. \
 --message-validator_out=gen \
 --grpc-gateway_out=logtostderr=true:gen \
 --openapiv2_out=json_names_for_fields=false,logtostderr=true:api \
+--openapi_out=api \
 *.proto
\ No newline at end of file
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+)
+
+// CheckTimeout the max duration of one readiness check
+const CheckTimeout = time.Second * 3
+
+var checks = &struct {
+	sync.RWMutex
+	handlers map[string]proposal.ReadinessCheck // Name : Check
+}{
+	handlers: make(map[string]proposal.ReadinessCheck),
+}
+
+// RegisteReadinessCheck a named readiness check, e.g. redis, downstreams
+func RegisteReadinessCheck(name string, check proposal.ReadinessCheck) {
+	if name == "" {
+		panic("readiness check name required")
+	}
+	if check == nil {
+		panic("readiness check required")
+	}
+
+	checks.Lock()
+	defer checks.Unlock()
+
+	if _, ok := checks.handlers[name]; ok {
+		panic(fmt.Sprintf("readiness check: %s has exists", name))
+	}
+
+	checks.handlers[name] = check
+}
+
+// Result the result of a readiness check
+type Result struct {
+	Name string `json:"name"`
+	Err  string `json:"error,omitempty"`
+}
+
+// Check run all registered checks and the extra ones concurrently, ready only if all of them passed
+func Check(ctx context.Context, extra map[string]proposal.ReadinessCheck) (ready bool, results []Result) {
+	checks.RLock()
+	handlers := make(map[string]proposal.ReadinessCheck, len(checks.handlers)+len(extra))
+	for name, check := range checks.handlers {
+		handlers[name] = check
+	}
+	checks.RUnlock()
+
+	for name, check := range extra {
+		handlers[name] = check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+
+	ready = true
+	for name, check := range handlers {
+		wg.Add(1)
+		go func(name string, check proposal.ReadinessCheck) {
+			defer wg.Done()
+
+			err := run(ctx, check)
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			result := Result{Name: name}
+			if err != nil {
+				ready = false
+				result.Err = err.Error()
+			}
+			results = append(results, result)
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return
+}
+
+func run(ctx context.Context, check proposal.ReadinessCheck) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.Panic(p)
+		}
+	}()
+
+	return check(ctx)
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Cleanup(func() {
+		checks.Lock()
+		defer checks.Unlock()
+
+		delete(checks.handlers, "redis")
+	})
+
+	var redisOK int32
+	RegisteReadinessCheck("redis", func(ctx context.Context) error {
+		if atomic.LoadInt32(&redisOK) == 0 {
+			return errors.New("redis unreachable")
+		}
+		return nil
+	})
+	assert.Panics(func() {
+		RegisteReadinessCheck("redis", func(ctx context.Context) error { return nil })
+	})
+
+	status := func(server *Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.GetStatus()
+	}
+
+	server := NewServer(zap.NewNop(), time.Millisecond*10)
+	server.Start([]string{"dummy.DummyService"})
+
+	assert.Eventually(func() bool {
+		return status(server, "dummy.DummyService") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, time.Millisecond*5)
+
+	atomic.StoreInt32(&redisOK, 1)
+	assert.Eventually(func() bool {
+		return status(server, "") == healthpb.HealthCheckResponse_SERVING &&
+			status(server, "dummy.DummyService") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, time.Millisecond*5)
+
+	server.Shutdown()
+	assert.Equal(healthpb.HealthCheckResponse_NOT_SERVING, status(server, ""))
+
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(healthpb.HealthCheckResponse_NOT_SERVING, status(server, "dummy.DummyService"))
+
+	// readyz with an extra failed check
+	recorder := httptest.NewRecorder()
+	Readyz(map[string]proposal.ReadinessCheck{
+		"endpoint:dummy": func(ctx context.Context) error { return errors.New("dummy is NOT_SERVING") },
+	})(recorder, httptest.NewRequest(http.MethodGet, ReadyzPath, nil), nil)
+	assert.Equal(http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(`{"status":"NOT_SERVING","checks":[{"name":"endpoint:dummy","error":"dummy is NOT_SERVING"},{"name":"redis"}]}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	Healthz(recorder, httptest.NewRequest(http.MethodGet, HealthzPath, nil), nil)
+	assert.Equal(http.StatusOK, recorder.Code)
+}
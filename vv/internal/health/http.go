@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// HealthzPath liveness probe
+	HealthzPath = "/healthz"
+	// ReadyzPath readiness probe
+	ReadyzPath = "/readyz"
+)
+
+type response struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, httpCode int, resp *response) {
+	raw, _ := json.Marshal(resp)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(httpCode)
+	w.Write(raw)
+}
+
+// Healthz always SERVING while the process alive
+func Healthz(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, &response{Status: healthpb.HealthCheckResponse_SERVING.String()})
+}
+
+// Readyz SERVING only if all registered checks and the extra ones passed, otherwise 503
+func Readyz(extra map[string]proposal.ReadinessCheck) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ready, results := Check(r.Context(), extra)
+		if !ready {
+			writeJSON(w, http.StatusServiceUnavailable, &response{Status: healthpb.HealthCheckResponse_NOT_SERVING.String(), Checks: results})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, &response{Status: healthpb.HealthCheckResponse_SERVING.String(), Checks: results})
+	}
+}
+
+// EndpointCheck use the grpc.health.v1 of endpoint as a readiness check
+func EndpointCheck(conn *grpc.ClientConn) proposal.ReadinessCheck {
+	client := healthpb.NewHealthClient(conn)
+
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, new(healthpb.HealthCheckRequest))
+		if err != nil {
+			return errors.Wrapf(err, "check health of %s err", conn.Target())
+		}
+
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return errors.Errorf("%s is %s", conn.Target(), resp.GetStatus())
+		}
+
+		return nil
+	}
+}
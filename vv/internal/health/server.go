@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DefaultInterval the default interval of readiness checks
+const DefaultInterval = time.Second * 5
+
+// Server the grpc.health.v1 service, the serving status kept by readiness checks
+type Server struct {
+	*health.Server
+	logger   *zap.Logger
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// NewServer create a health server, all services NOT_SERVING until Start
+func NewServer(logger *zap.Logger, interval time.Duration) *Server {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &Server{
+		Server:   health.NewServer(),
+		logger:   logger,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return server
+}
+
+// Start run readiness checks periodically, the status of the overall server ("") and each service follows the result
+func (s *Server) Start(services []string) {
+	s.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(s.interval)
+			defer ticker.Stop()
+
+			for {
+				s.update(services)
+
+				select {
+				case <-s.ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+func (s *Server) update(services []string) {
+	ready, results := Check(s.ctx, nil)
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if !ready {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+
+		for _, result := range results {
+			if result.Err != "" {
+				s.logger.Warn("readiness check failed", zap.String("name", result.Name), zap.String("error", result.Err))
+			}
+		}
+	}
+
+	s.SetServingStatus("", status)
+	for _, service := range services {
+		s.SetServingStatus(service, status)
+	}
+}
+
+// Shutdown flip all to NOT_SERVING and stop checks, the status never changes later
+func (s *Server) Shutdown() {
+	s.cancel()
+	s.Server.Shutdown()
+}
@@ -0,0 +1,80 @@
+package interceptor
+
+import (
+	"fmt"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+)
+
+// accessControlled whether options.roles or options.permissions declared
+func accessControlled(serviceHandler *options.ServiceHandler, methodHandler *options.MethodHandler) bool {
+	return serviceHandler.GetRoles() != nil || serviceHandler.GetPermissions() != nil ||
+		methodHandler.GetRoles() != nil || methodHandler.GetPermissions() != nil
+}
+
+func getAuthorizer(name string) (proposal.Authorizer, bool) {
+	fdLock.RLock()
+	defer fdLock.RUnlock()
+
+	authorizer, ok := handlers.Authorizers[name]
+	return authorizer, ok
+}
+
+// authorize the userinfo should be granted options.roles and options.permissions, the ones of method override the service;
+// denied with cuzerr.PermissionDenied, the missing one journaled
+func authorize(serviceName, fullMethod string, userinfo interface{}) error {
+	serviceHandler, _ := getServiceHandler(serviceName)
+	methodHandler, _ := getMethodHandler(fullMethod)
+	if !accessControlled(serviceHandler, methodHandler) {
+		return nil
+	}
+
+	authorization := serviceHandler.GetAuthorization()
+	roles, permissions := serviceHandler.GetRoles(), serviceHandler.GetPermissions()
+	if methodHandler.GetAuthorization() != "" {
+		authorization = methodHandler.GetAuthorization()
+	}
+	if methodHandler.GetRoles() != nil {
+		roles = methodHandler.GetRoles()
+	}
+	if methodHandler.GetPermissions() != nil {
+		permissions = methodHandler.GetPermissions()
+	}
+
+	authorizer, ok := getAuthorizer(authorization)
+	if !ok {
+		return cuzerr.NewBzError(cuzerr.PermissionDenied, errors.Errorf("authorizer %s of %s not found", authorization, fullMethod))
+	}
+
+	if missing, ok := granted(roles, func(role string) bool { return authorizer.HasRole(userinfo, role) }); !ok {
+		return cuzerr.NewBzError(cuzerr.PermissionDenied, errors.Errorf("role %s required by %s", missing, fullMethod))
+	}
+	if missing, ok := granted(permissions, func(permission string) bool { return authorizer.HasPermission(userinfo, permission) }); !ok {
+		return cuzerr.NewBzError(cuzerr.PermissionDenied, errors.Errorf("permission %s required by %s", missing, fullMethod))
+	}
+
+	return nil
+}
+
+// granted check all_of then any_of, the missing one returned if not granted
+func granted(control *options.AccessControl, has func(string) bool) (string, bool) {
+	for _, name := range control.GetAllOf() {
+		if !has(name) {
+			return name, false
+		}
+	}
+
+	if len(control.GetAnyOf()) == 0 {
+		return "", true
+	}
+	for _, name := range control.GetAnyOf() {
+		if has(name) {
+			return "", true
+		}
+	}
+
+	return fmt.Sprintf("one of %v", control.GetAnyOf()), false
+}
@@ -0,0 +1,110 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type staff struct {
+	roles       []string
+	permissions []string
+}
+
+type staffAuthorizer struct{}
+
+func (staffAuthorizer) HasRole(userinfo interface{}, role string) bool {
+	for _, granted := range userinfo.(*staff).roles {
+		if granted == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (staffAuthorizer) HasPermission(userinfo interface{}, permission string) bool {
+	for _, granted := range userinfo.(*staff).permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAuthorize(t *testing.T) {
+	assert := assert.New(t)
+
+	registerOnce("dummy_staff", func() {
+		RegisteAuthorizationValidator("dummy_staff", func(authorization string, payload proposal.Payload) (interface{}, error) {
+			switch authorization {
+			case "admin":
+				return &staff{roles: []string{"admin"}, permissions: []string{"refund:read", "refund:write"}}, nil
+			case "auditor":
+				return &staff{roles: []string{"auditor"}, permissions: []string{"refund:read"}}, nil
+			}
+			return &staff{}, nil
+		})
+		RegisteAuthorizer("dummy_staff", staffAuthorizer{})
+	})
+
+	setServiceHandler(t, "dummy.RefundService", &options.ServiceHandler{
+		Authorization: proto.String("dummy_staff"),
+		Roles:         &options.AccessControl{AnyOf: []string{"admin", "auditor"}},
+	})
+	setMethodHandler(t, "/dummy.RefundService/Approve", &options.MethodHandler{
+		Permissions: &options.AccessControl{AllOf: []string{"refund:read", "refund:write"}},
+	}, nil)
+	setMethodHandler(t, "/dummy.RefundService/Purge", &options.MethodHandler{
+		Roles: &options.AccessControl{AllOf: []string{"admin"}, AnyOf: []string{"owner", "operator"}},
+	}, nil)
+
+	core, logs := observer.New(zap.InfoLevel)
+	unary := UnaryServerInterceptor(zap.New(core), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{})
+	call := func(method, authorization string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Authorization, authorization))
+		_, err := unary(ctx, wrapperspb.String("refund"), &grpc.UnaryServerInfo{FullMethod: "/dummy.RefundService/" + method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+		return err
+	}
+
+	denied := func(err error) bool {
+		s, _ := status.FromError(err)
+		for _, detail := range s.Details() {
+			if code, ok := detail.(*pb.Code); ok {
+				return s.Code() == codes.Code(cuzerr.PermissionDenied.BzCode()) && code.HttpStatus == 403
+			}
+		}
+		return false
+	}
+
+	assert.NoError(call("List", "auditor")) // roles of service
+	assert.True(denied(call("List", "guest")))
+
+	assert.NoError(call("Approve", "admin"))
+	assert.True(denied(call("Approve", "auditor"))) // permissions of method
+
+	assert.True(denied(call("Purge", "admin"))) // overridden by roles of method
+	assert.True(denied(call("Purge", "auditor")))
+
+	journals := logs.FilterMessage("server unary interceptor").All()
+	if assert.Len(journals, 6) {
+		assert.Contains(journals[3].ContextMap()["journal"], "permission refund:write required by /dummy.RefundService/Approve")
+		assert.Contains(journals[4].ContextMap()["journal"], "role one of [owner operator] required by /dummy.RefundService/Purge")
+		assert.Contains(journals[5].ContextMap()["journal"], "role admin required by /dummy.RefundService/Purge")
+	}
+}
@@ -0,0 +1,185 @@
+package interceptor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/grpc/codes"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerHalfOpen:
+		return "half-open"
+	case breakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+var defaultBreakerFailureCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown}
+
+// breaker a circuit breaker of a scope (a method, a service or the whole conn)
+type breaker struct {
+	scope   string
+	config  proposal.BreakerConfig
+	failure map[codes.Code]bool
+	metrics *Metrics
+	now     func() time.Time
+
+	mux         sync.Mutex
+	state       breakerState
+	generation  uint64 // increased on every transition, results of the previous generation ignored
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int // in flight or finished in half-open
+	succeeded   int
+}
+
+func newBreaker(scope string, config *proposal.BreakerConfig, metrics *Metrics, now func() time.Time) *breaker {
+	b := &breaker{
+		scope:   scope,
+		metrics: metrics,
+		now:     now,
+	}
+
+	if config != nil {
+		b.config = *config
+	}
+	if b.config.Window <= 0 {
+		b.config.Window = time.Second * 10
+	}
+	if b.config.MinRequests <= 0 {
+		b.config.MinRequests = 20
+	}
+	if b.config.FailureRatio <= 0 || b.config.FailureRatio > 1 {
+		b.config.FailureRatio = 0.5
+	}
+	if b.config.OpenTimeout <= 0 {
+		b.config.OpenTimeout = time.Second * 5
+	}
+	if b.config.HalfOpenRequests <= 0 {
+		b.config.HalfOpenRequests = 1
+	}
+
+	failureCodes := b.config.FailureCodes
+	if len(failureCodes) == 0 {
+		failureCodes = defaultBreakerFailureCodes
+	}
+	b.failure = make(map[codes.Code]bool, len(failureCodes))
+	for _, code := range failureCodes {
+		b.failure[code] = true
+	}
+
+	b.windowStart = now()
+	metrics.observeBreakerState(scope, breakerClosed)
+	return b
+}
+
+// allow whether a call could be sent, call done with the generation returned when finished
+func (b *breaker) allow() (uint64, bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case breakerClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.windowStart = now
+			b.requests, b.failures = 0, 0
+		}
+
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.config.OpenTimeout {
+			b.metrics.observeBreakerRejected(b.scope)
+			return 0, false
+		}
+		b.transit(breakerHalfOpen)
+		fallthrough
+
+	case breakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			b.metrics.observeBreakerRejected(b.scope)
+			return 0, false
+		}
+		b.probes++
+	}
+
+	return b.generation, true
+}
+
+// done record the result of a call allowed, canceled is neutral unless declared as a failure code
+func (b *breaker) done(generation uint64, code codes.Code) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	failed := b.failure[code]
+	if code == codes.Canceled && !failed { // e.g. the hedged attempt lost, neither success nor failure
+		if b.state == breakerHalfOpen {
+			b.probes-- // another probe allowed
+		}
+		return
+	}
+
+	switch b.state {
+	case breakerClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+
+		if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+			b.transit(breakerOpen)
+		}
+
+	case breakerHalfOpen:
+		if failed {
+			b.transit(breakerOpen)
+			return
+		}
+
+		if b.succeeded++; b.succeeded >= b.config.HalfOpenRequests {
+			b.transit(breakerClosed)
+		}
+	}
+}
+
+func (b *breaker) transit(state breakerState) {
+	now := b.now()
+
+	b.state = state
+	b.generation++
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+	b.probes, b.succeeded = 0, 0
+	if state == breakerOpen {
+		b.openedAt = now
+	}
+
+	b.metrics.observeBreakerState(b.scope, state)
+}
+
+func (b *breaker) currentState() breakerState {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return b.state
+}
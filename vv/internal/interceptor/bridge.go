@@ -0,0 +1,561 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/id"
+	"github.com/bluekaki/pkg/vv/internal/pkg/websocket"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// BridgeSubprotocol the websocket subprotocol selected by bridge if offered
+	BridgeSubprotocol = "vv.bridge"
+	// bearerSubprotocol carry the bearer token by subprotocol e.g. bearer.<token>, as browsers could not set Authorization on websocket
+	bearerSubprotocol = "bearer."
+
+	eventStream = "text/event-stream"
+
+	defaultPingInterval       = time.Second * 30
+	defaultBridgeWriteTimeout = time.Second * 10
+	defaultMaxMessageSize     = 1 << 20
+	defaultMaxPendingMessages = 16
+)
+
+type streamingBodyKey struct{}
+
+// StreamingBody whether the body of request is a stream of messages, e.g. bridged from websocket or uploaded by chunks,
+// which should not be read ahead
+func StreamingBody(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamingBodyKey{}).(bool)
+	return streaming
+}
+
+type bridgeRoute struct {
+	fullMethod      string
+	method          string
+	path            *regexp.Regexp
+	body            bool
+	clientStreaming bool
+	serverStreaming bool
+}
+
+type bridge struct {
+	logger             *zap.Logger
+	pingInterval       time.Duration
+	pongTimeout        time.Duration
+	writeTimeout       time.Duration
+	maxMessageSize     int64
+	maxPendingMessages int
+	checkOrigin        func(r *http.Request, origin string) bool
+}
+
+// StreamBridge wrap the gateway mux, exposes the streaming methods over websocket and server-sent events;
+// the bridged requests go through the mux, so journal, whitelisting and authorization applied as usual.
+//
+// Over websocket each text message is a request message, an empty one half-closes the request stream,
+// and each response is sent as a message of {"result":...} or {"error":...}.
+// Over server-sent events (Accept: text/event-stream, server streaming only) each result is a data event,
+// and the error an event named error.
+func StreamBridge(logger *zap.Logger, config *proposal.BridgeConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = new(proposal.BridgeConfig)
+	}
+
+	b := &bridge{
+		logger:             logger,
+		pingInterval:       config.PingInterval,
+		pongTimeout:        config.PongTimeout,
+		writeTimeout:       config.WriteTimeout,
+		maxMessageSize:     config.MaxMessageSize,
+		maxPendingMessages: config.MaxPendingMessages,
+		checkOrigin:        sameOrigin,
+	}
+	if b.pingInterval <= 0 {
+		b.pingInterval = defaultPingInterval
+	}
+	if b.pongTimeout <= 0 {
+		b.pongTimeout = b.pingInterval * 2
+	}
+	if b.writeTimeout <= 0 {
+		b.writeTimeout = defaultBridgeWriteTimeout
+	}
+	if b.maxMessageSize <= 0 {
+		b.maxMessageSize = defaultMaxMessageSize
+	}
+	if b.maxPendingMessages <= 0 {
+		b.maxPendingMessages = defaultMaxPendingMessages
+	}
+	if config.CheckOrigin != nil {
+		b.checkOrigin = func(_ *http.Request, origin string) bool { return config.CheckOrigin(origin) }
+	}
+
+	var routes []*bridgeRoute
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		serivces := fd.Services()
+		for i := 0; i < serivces.Len(); i++ {
+			serivce := serivces.Get(i)
+
+			methods := serivce.Methods()
+			for k := 0; k < methods.Len(); k++ {
+				method := methods.Get(k)
+				if !method.IsStreamingClient() && !method.IsStreamingServer() {
+					continue
+				}
+
+				fullMethod := fmt.Sprintf("/%s/%s", serivce.FullName(), method.Name())
+				httpRule, _ := getHTTPRule(fullMethod)
+				for _, rule := range httpRules(httpRule) {
+					routes = append(routes, &bridgeRoute{
+						fullMethod:      fullMethod,
+						method:          rule.Method,
+						path:            templateRegexp(rule.Path),
+						body:            rule.Body != "",
+						clientStreaming: method.IsStreamingClient(),
+						serverStreaming: method.IsStreamingServer(),
+					})
+				}
+			}
+		}
+		return true
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if websocket.IsUpgrade(r) {
+				for _, route := range routes {
+					if route.path.MatchString(r.URL.Path) {
+						b.serveWebSocket(w, r, route, next)
+						return
+					}
+				}
+			}
+
+			if strings.Contains(r.Header.Get("Accept"), eventStream) {
+				for _, route := range routes {
+					if route.serverStreaming && !route.clientStreaming && route.method == r.Method && route.path.MatchString(r.URL.Path) {
+						b.serveEventStream(w, r, next)
+						return
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// sameOrigin the origin should be the host requested
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func bridgeJournalID(r *http.Request) string {
+	journalID := r.Header.Get(JournalID)
+	if journalID == "" {
+		journalID = id.JournalID()
+		r.Header.Set(JournalID, journalID) // picked up by the annotator of mux
+	}
+	return journalID
+}
+
+func (b *bridge) serveWebSocket(w http.ResponseWriter, r *http.Request, route *bridgeRoute, next http.Handler) {
+	journalID := bridgeJournalID(r)
+
+	if origin := r.Header.Get("Origin"); origin != "" && !b.checkOrigin(r, origin) {
+		b.logger.Warn("gateway stream bridge origin rejected",
+			zap.String("journal_id", journalID),
+			zap.String("method", route.fullMethod),
+			zap.String("origin", origin),
+		)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	var subprotocol string
+	for _, value := range r.Header.Values("Sec-Websocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			protocol = strings.TrimSpace(protocol)
+
+			switch {
+			case protocol == BridgeSubprotocol:
+				subprotocol = protocol
+
+			case strings.HasPrefix(protocol, bearerSubprotocol) && r.Header.Get(Authorization) == "":
+				r.Header.Set(Authorization, "Bearer "+strings.TrimPrefix(protocol, bearerSubprotocol))
+			}
+		}
+	}
+
+	conn, err := websocket.Upgrade(w, r, subprotocol, http.Header{http.CanonicalHeaderKey(JournalID): []string{journalID}})
+	if err != nil {
+		b.logger.Warn("gateway stream bridge upgrade err", zap.String("journal_id", journalID), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	alive := func() { conn.SetReadDeadline(time.Now().Add(b.pongTimeout)) }
+	alive()
+	conn.SetReadLimit(b.maxMessageSize)
+	conn.SetPongHandler(alive)
+
+	ctx, cancel := context.WithCancel(context.WithValue(r.Context(), streamingBodyKey{}, true))
+	defer cancel()
+
+	body := &bridgeBody{messages: make(chan []byte, b.maxPendingMessages)}
+
+	req := r.Clone(ctx)
+	req.Method = route.method
+	req.Body = body
+	req.ContentLength = -1
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Del("Connection")
+	req.Header.Del("Upgrade")
+
+	// the messages accepted as request, unlimited for client streaming
+	limit := -1
+	if !route.clientStreaming {
+		limit = 0
+		if route.body {
+			limit = 1
+		}
+	}
+
+	go func() {
+		defer cancel()
+		defer body.finish()
+
+		if limit == 0 {
+			body.finish()
+		}
+
+		accepted := 0
+		for {
+			_, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			switch {
+			case len(payload) == 0:
+				body.finish()
+				continue
+
+			case body.finished:
+				conn.WriteClose(websocket.ClosePolicyViolation, "request stream closed")
+				return
+			}
+
+			if !body.push(payload) {
+				b.logger.Warn("gateway stream bridge back-pressure exceeded",
+					zap.String("journal_id", journalID),
+					zap.String("method", route.fullMethod),
+					zap.Int("max_pending_messages", b.maxPendingMessages),
+				)
+				conn.WriteClose(websocket.ClosePolicyViolation, "too many pending messages")
+				return
+			}
+
+			if accepted++; accepted == limit {
+				body.finish()
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(b.pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(b.writeTimeout)); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	writer := &socketWriter{conn: conn, header: make(http.Header), timeout: b.writeTimeout}
+	next.ServeHTTP(writer, req)
+
+	if writer.buf.Len() > 0 && writer.err == nil { // not delimited, e.g. error of routing
+		writer.send(writer.buf.Bytes())
+	}
+
+	var reason string
+	if writer.status >= http.StatusBadRequest {
+		reason = http.StatusText(writer.status)
+	}
+	conn.WriteClose(websocket.CloseNormalClosure, reason)
+}
+
+// bridgeBody the request messages received by websocket, delimited by newline
+type bridgeBody struct {
+	messages chan []byte
+	finished bool // accessed by the receiving goroutine only
+	current  []byte
+}
+
+func (b *bridgeBody) push(message []byte) bool {
+	select {
+	case b.messages <- append(message, '\n'):
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *bridgeBody) finish() {
+	if !b.finished {
+		b.finished = true
+		close(b.messages)
+	}
+}
+
+func (b *bridgeBody) Read(p []byte) (int, error) {
+	if len(b.current) == 0 {
+		message, ok := <-b.messages
+		if !ok {
+			return 0, io.EOF
+		}
+		b.current = message
+	}
+
+	n := copy(p, b.current)
+	b.current = b.current[n:]
+	return n, nil
+}
+
+func (b *bridgeBody) Close() error {
+	return nil
+}
+
+// socketWriter send each delimited response as a websocket message
+type socketWriter struct {
+	conn    *websocket.Conn
+	header  http.Header
+	status  int
+	timeout time.Duration
+	buf     bytes.Buffer
+	err     error
+}
+
+func (s *socketWriter) Header() http.Header {
+	return s.header
+}
+
+func (s *socketWriter) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+}
+
+func (s *socketWriter) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	s.buf.Write(p)
+	for {
+		i := bytes.IndexByte(s.buf.Bytes(), '\n')
+		if i == -1 {
+			break
+		}
+
+		line := s.buf.Next(i + 1)[:i]
+		if len(line) == 0 {
+			continue
+		}
+		if s.send(line); s.err != nil {
+			return 0, s.err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (s *socketWriter) send(message []byte) {
+	s.err = s.conn.WriteMessage(websocket.TextMessage, message, time.Now().Add(s.timeout))
+}
+
+// Flush sent on delimited
+func (s *socketWriter) Flush() {}
+
+func (b *bridge) serveEventStream(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	bridgeJournalID(r)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	req := r.Clone(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	writer := &eventWriter{w: w, controller: http.NewResponseController(w), timeout: b.writeTimeout}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(b.pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if err := writer.comment("ping"); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	next.ServeHTTP(writer, req)
+
+	cancel()
+	<-done
+}
+
+// eventWriter send each delimited response as an event, the response of non 200 passed through
+type eventWriter struct {
+	sync.Mutex
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	timeout    time.Duration
+	status     int
+	buf        bytes.Buffer
+	err        error
+}
+
+func (e *eventWriter) Header() http.Header {
+	return e.w.Header()
+}
+
+func (e *eventWriter) WriteHeader(status int) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.writeHeader(status)
+}
+
+func (e *eventWriter) writeHeader(status int) {
+	if e.status != 0 {
+		return
+	}
+	e.status = status
+
+	if status == http.StatusOK {
+		e.w.Header().Set("Content-Type", eventStream)
+		e.w.Header().Set("Cache-Control", "no-cache")
+		e.w.Header().Set("X-Accel-Buffering", "no")
+		e.w.Header().Del("Content-Length")
+	}
+	e.w.WriteHeader(status)
+}
+
+func (e *eventWriter) Write(p []byte) (int, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.writeHeader(http.StatusOK)
+	if e.status != http.StatusOK {
+		return e.w.Write(p)
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	e.buf.Write(p)
+	for {
+		i := bytes.IndexByte(e.buf.Bytes(), '\n')
+		if i == -1 {
+			break
+		}
+
+		line := e.buf.Next(i + 1)[:i]
+		if len(line) == 0 {
+			continue
+		}
+		if e.send(event(line)); e.err != nil {
+			return 0, e.err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sent on delimited
+func (e *eventWriter) Flush() {}
+
+// comment keep the event stream alive, ignored before it started
+func (e *eventWriter) comment(text string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.status != http.StatusOK || e.err != nil {
+		return e.err
+	}
+
+	e.send([]byte(": " + text + "\n\n"))
+	return e.err
+}
+
+func (e *eventWriter) send(raw []byte) {
+	e.controller.SetWriteDeadline(time.Now().Add(e.timeout))
+	defer e.controller.SetWriteDeadline(time.Time{})
+
+	if _, e.err = e.w.Write(raw); e.err == nil {
+		e.err = e.controller.Flush()
+	}
+}
+
+// event unwrap the chunk of {"result":...} into data, and {"error":...} into an event named error
+func event(chunk []byte) []byte {
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	json.Unmarshal(chunk, &envelope)
+
+	var raw bytes.Buffer
+	switch {
+	case envelope.Result != nil:
+		chunk = envelope.Result
+
+	case envelope.Error != nil:
+		raw.WriteString("event: error\n")
+		chunk = envelope.Error
+	}
+
+	raw.WriteString("data: ")
+	raw.Write(chunk)
+	raw.WriteString("\n\n")
+	return raw.Bytes()
+}
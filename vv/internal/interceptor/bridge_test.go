@@ -0,0 +1,261 @@
+package interceptor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/internal/pkg/websocket"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestStreamBridge(t *testing.T) {
+	assert := assert.New(t)
+
+	method := func(name string, clientStreaming, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(name),
+			InputType:       proto.String(".google.protobuf.Empty"),
+			OutputType:      proto.String(".google.protobuf.Empty"),
+			ClientStreaming: proto.Bool(clientStreaming),
+			ServerStreaming: proto.Bool(serverStreaming),
+		}
+	}
+
+	registerFile(t, dummyFile("dummy/bridge.proto", "QuoteService",
+		method("Watch", false, true),
+		method("Chat", true, true),
+		method("Upload", true, false),
+	))
+	setMethodHandler(t, "/dummy.QuoteService/Watch", nil, &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/quotes/{symbol}"}})
+	setMethodHandler(t, "/dummy.QuoteService/Chat", nil, &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/chat"}, Body: "*"})
+	setMethodHandler(t, "/dummy.QuoteService/Upload", nil, &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/upload"}, Body: "*"})
+
+	var lock sync.Mutex
+	var journalIDs []string
+	uploadDone := make(chan struct{}, 1)
+
+	// behaves as the mux of grpc-gateway, responses delimited by newline
+	gatewayMux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		journalIDs = append(journalIDs, r.Header.Get(JournalID))
+		lock.Unlock()
+
+		flusher := w.(http.Flusher)
+		chunk := func(raw string) {
+			w.Write([]byte(raw))
+			w.Write([]byte("\n"))
+			flusher.Flush()
+		}
+
+		switch {
+		case r.URL.Path == "/v1/quotes/404":
+			w.WriteHeader(http.StatusNotFound)
+			chunk(`{"error":{"code":5,"message":"symbol not found"}}`)
+
+		case strings.HasPrefix(r.URL.Path, "/v1/quotes/"):
+			time.Sleep(time.Millisecond * 300) // longer than the pong timeout
+			for i := 0; i < 3; i++ {
+				chunk(fmt.Sprintf(`{"result":{"seq":%d,"auth":%q}}`, i, r.Header.Get(Authorization)))
+			}
+			chunk(`{"error":{"code":14,"message":"unavailable"}}`)
+
+		case r.URL.Path == "/v1/chat":
+			assert.True(StreamingBody(r.Context()))
+			assert.Equal(http.MethodPost, r.Method)
+
+			decoder := json.NewDecoder(r.Body)
+			for {
+				var message json.RawMessage
+				if decoder.Decode(&message) != nil {
+					return
+				}
+				chunk(`{"result":` + string(message) + `}`)
+			}
+
+		case r.URL.Path == "/v1/upload": // never consumes
+			<-r.Context().Done()
+			uploadDone <- struct{}{}
+		}
+	})
+
+	handler := StreamBridge(zap.NewNop(), &proposal.BridgeConfig{
+		PingInterval:       time.Millisecond * 50,
+		MaxPendingMessages: 2,
+	})(gatewayMux)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	read := func(conn *websocket.Conn) (string, int) {
+		_, payload, err := conn.ReadMessage()
+		if closeErr, ok := err.(*websocket.CloseError); ok {
+			return closeErr.Reason, closeErr.Code
+		}
+		return string(payload), 0
+	}
+
+	t.Run("websocket server streaming", func(t *testing.T) {
+		conn, resp, err := websocket.Dial(wsURL+"/v1/quotes/AAPL", http.Header{"Sec-Websocket-Protocol": []string{"vv.bridge, bearer.token-1"}})
+		if !assert.NoError(err) {
+			return
+		}
+		defer conn.Close()
+
+		assert.Equal(BridgeSubprotocol, resp.Header.Get("Sec-Websocket-Protocol"))
+		assert.NotEmpty(resp.Header.Get(JournalID))
+
+		// kept alive by answering pings, although nothing sent in the pong timeout
+		for i := 0; i < 3; i++ {
+			message, _ := read(conn)
+			assert.Equal(fmt.Sprintf(`{"result":{"seq":%d,"auth":"Bearer token-1"}}`, i), message)
+		}
+
+		message, _ := read(conn)
+		assert.Equal(`{"error":{"code":14,"message":"unavailable"}}`, message)
+
+		_, code := read(conn)
+		assert.Equal(websocket.CloseNormalClosure, code)
+
+		lock.Lock()
+		assert.Equal(resp.Header.Get(JournalID), journalIDs[len(journalIDs)-1])
+		lock.Unlock()
+	})
+
+	t.Run("websocket bidi streaming", func(t *testing.T) {
+		conn, _, err := websocket.Dial(wsURL+"/v1/chat", http.Header{http.CanonicalHeaderKey(JournalID): []string{"journal-1"}})
+		if !assert.NoError(err) {
+			return
+		}
+		defer conn.Close()
+
+		for _, text := range []string{"hello", "world"} {
+			assert.NoError(conn.WriteMessage(websocket.TextMessage, []byte(`{"text":"`+text+`"}`), time.Now().Add(time.Second)))
+
+			message, _ := read(conn)
+			assert.Equal(`{"result":{"text":"`+text+`"}}`, message)
+		}
+
+		// half-closed
+		assert.NoError(conn.WriteMessage(websocket.TextMessage, nil, time.Now().Add(time.Second)))
+		_, code := read(conn)
+		assert.Equal(websocket.CloseNormalClosure, code)
+
+		lock.Lock()
+		assert.Equal("journal-1", journalIDs[len(journalIDs)-1])
+		lock.Unlock()
+	})
+
+	t.Run("websocket back-pressure", func(t *testing.T) {
+		conn, _, err := websocket.Dial(wsURL+"/v1/upload", nil)
+		if !assert.NoError(err) {
+			return
+		}
+		defer conn.Close()
+
+		for i := 0; i < 3; i++ {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{}`), time.Now().Add(time.Second))
+		}
+
+		reason, code := read(conn)
+		assert.Equal(websocket.ClosePolicyViolation, code)
+		assert.Equal("too many pending messages", reason)
+
+		select {
+		case <-uploadDone:
+		case <-time.After(time.Second * 5):
+			t.Error("stream not cancelled")
+		}
+	})
+
+	t.Run("websocket pong timeout", func(t *testing.T) {
+		conn, _, err := websocket.Dial(wsURL+"/v1/upload", nil)
+		if !assert.NoError(err) {
+			return
+		}
+		defer conn.Close() // never reads, so pings not answered
+
+		select {
+		case <-uploadDone:
+		case <-time.After(time.Second * 5):
+			t.Error("stream not cancelled")
+		}
+	})
+
+	t.Run("websocket origin", func(t *testing.T) {
+		_, resp, err := websocket.Dial(wsURL+"/v1/chat", http.Header{"Origin": []string{"https://evil.example.com"}})
+		assert.Error(err)
+		if assert.NotNil(resp) {
+			assert.Equal(http.StatusForbidden, resp.StatusCode)
+		}
+
+		conn, _, err := websocket.Dial(wsURL+"/v1/chat", http.Header{"Origin": []string{server.URL}})
+		if assert.NoError(err) {
+			conn.Close()
+		}
+	})
+
+	t.Run("server-sent events", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/quotes/AAPL", nil)
+		req.Header.Set("Accept", eventStream)
+		req.Header.Set(Authorization, "Bearer token-2")
+
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(http.StatusOK, resp.StatusCode)
+		assert.Equal(eventStream, resp.Header.Get("Content-Type"))
+
+		var lines []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" && !strings.HasPrefix(line, ":") {
+				lines = append(lines, line)
+			}
+		}
+		assert.Equal([]string{
+			`data: {"seq":0,"auth":"Bearer token-2"}`,
+			`data: {"seq":1,"auth":"Bearer token-2"}`,
+			`data: {"seq":2,"auth":"Bearer token-2"}`,
+			`event: error`,
+			`data: {"code":14,"message":"unavailable"}`,
+		}, lines)
+
+		// passed through if failed before streaming
+		req, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/quotes/404", nil)
+		req.Header.Set("Accept", eventStream)
+
+		resp, err = http.DefaultClient.Do(req)
+		if !assert.NoError(err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(http.StatusNotFound, resp.StatusCode)
+		assert.NotEqual(eventStream, resp.Header.Get("Content-Type"))
+	})
+}
+
+func TestEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("data: {\"id\":1}\n\n", string(event([]byte(`{"result":{"id":1}}`))))
+	assert.Equal("event: error\ndata: {\"code\":5}\n\n", string(event([]byte(`{"error":{"code":5}}`))))
+	assert.Equal("data: plain\n\n", string(event([]byte(`plain`))))
+}
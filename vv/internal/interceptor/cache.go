@@ -0,0 +1,389 @@
+package interceptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// XCache the header responded by cache, HIT, MISS or BYPASS
+	XCache = "X-Cache"
+
+	cacheHit    = "hit"
+	cacheMiss   = "miss"
+	cacheBypass = "bypass"
+)
+
+type cacheRoute struct {
+	fullMethod   string
+	path         *regexp.Regexp
+	ttl          time.Duration
+	varyHeaders  []string
+	varyUserinfo bool
+}
+
+// cacheEntry the cached response
+type cacheEntry struct {
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	ETag     string      `json:"etag"`
+	ExpireAt time.Time   `json:"expire_at"`
+}
+
+// Cache wrap the gateway mux, the successful responses of GET declared options.cache kept in store,
+// revalidated by ETag/If-None-Match; the request Cache-Control no-cache skips the lookup, no-store bypasses the cache.
+func Cache(logger *zap.Logger, metrics *Metrics, store proposal.CacheStore) func(http.Handler) http.Handler {
+	if store == nil {
+		panic("cache store required")
+	}
+
+	var routes []*cacheRoute
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		serivces := fd.Services()
+		for i := 0; i < serivces.Len(); i++ {
+			serivce := serivces.Get(i)
+
+			methods := serivce.Methods()
+			for k := 0; k < methods.Len(); k++ {
+				method := methods.Get(k)
+				fullMethod := fmt.Sprintf("/%s/%s", serivce.FullName(), method.Name())
+
+				methodHandler, _ := getMethodHandler(fullMethod)
+				if methodHandler.GetCache() == nil || method.IsStreamingServer() {
+					continue
+				}
+
+				ttl, err := time.ParseDuration(methodHandler.GetCache().GetTtl())
+				if err != nil || ttl <= 0 {
+					panic(fmt.Sprintf("options.cache ttl of %s illegal", fullMethod))
+				}
+
+				varyHeaders := make([]string, len(methodHandler.GetCache().GetVaryHeaders()))
+				for i, header := range methodHandler.GetCache().GetVaryHeaders() {
+					varyHeaders[i] = http.CanonicalHeaderKey(header)
+				}
+				sort.Strings(varyHeaders)
+
+				httpRule, _ := getHTTPRule(fullMethod)
+				for _, rule := range httpRules(httpRule) {
+					if rule.Method != http.MethodGet {
+						continue
+					}
+
+					routes = append(routes, &cacheRoute{
+						fullMethod:   fullMethod,
+						path:         templateRegexp(rule.Path),
+						ttl:          ttl,
+						varyHeaders:  varyHeaders,
+						varyUserinfo: methodHandler.GetCache().GetVaryUserinfo(),
+					})
+				}
+			}
+		}
+		return true
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var route *cacheRoute
+			for _, candidate := range routes {
+				if candidate.path.MatchString(r.URL.Path) {
+					route = candidate
+					break
+				}
+			}
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			directives := cacheDirectives(r.Header.Get("Cache-Control"))
+			if directives["no-store"] {
+				metrics.observeCache(route.fullMethod, cacheBypass)
+				w.Header().Set(XCache, "BYPASS")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := route.key(store, r)
+			if err != nil {
+				logger.Error("gateway cache key err", zap.String("method", route.fullMethod), zap.Error(err))
+				metrics.observeCache(route.fullMethod, cacheBypass)
+				w.Header().Set(XCache, "BYPASS")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !directives["no-cache"] {
+				entry, err := lookupCache(store, key)
+				if err != nil {
+					logger.Error("gateway cache get err", zap.String("method", route.fullMethod), zap.Error(err))
+				}
+
+				if now := time.Now(); entry != nil && now.Before(entry.ExpireAt) {
+					metrics.observeCache(route.fullMethod, cacheHit)
+
+					header := w.Header()
+					for k, v := range entry.Header {
+						header[k] = v
+					}
+					route.writeCacheHeaders(header, entry, now)
+					header.Set("Age", strconv.Itoa(int(now.Add(route.ttl).Sub(entry.ExpireAt).Seconds())))
+					header.Set(XCache, "HIT")
+
+					if etagMatched(r.Header.Get("If-None-Match"), entry.ETag) {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+
+					w.WriteHeader(http.StatusOK)
+					w.Write(entry.Body)
+					return
+				}
+			}
+
+			metrics.observeCache(route.fullMethod, cacheMiss)
+
+			recorder := &cacheRecorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			header := w.Header()
+			for k, v := range recorder.header {
+				header[k] = v
+			}
+			header.Set(XCache, "MISS")
+
+			if recorder.status != http.StatusOK || recorder.header.Get("Set-Cookie") != "" {
+				w.WriteHeader(recorder.status)
+				w.Write(recorder.body.Bytes())
+				return
+			}
+
+			now := time.Now()
+			digest := sha256.Sum256(recorder.body.Bytes())
+			entry := &cacheEntry{
+				Header:   cachedHeader(recorder.header),
+				Body:     recorder.body.Bytes(),
+				ETag:     `"` + hex.EncodeToString(digest[:16]) + `"`,
+				ExpireAt: now.Add(route.ttl),
+			}
+
+			if raw, err := json.Marshal(entry); err != nil {
+				logger.Error("gateway cache marshal err", zap.String("method", route.fullMethod), zap.Error(err))
+			} else if err = store.Set(key, raw, route.ttl); err != nil {
+				logger.Error("gateway cache set err", zap.String("method", route.fullMethod), zap.Error(err))
+			}
+
+			route.writeCacheHeaders(header, entry, now)
+			if etagMatched(r.Header.Get("If-None-Match"), entry.ETag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write(entry.Body)
+		})
+	}
+}
+
+// key vv:cache:{method}:{version}:{digest of path, sorted query and vary values}
+func (c *cacheRoute) key(store proposal.CacheStore, r *http.Request) (string, error) {
+	version, err := cacheVersion(store, c.fullMethod)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.New()
+	digest.Write([]byte(r.URL.Path))
+	digest.Write([]byte{'\n'})
+	digest.Write([]byte(r.URL.Query().Encode())) // sorted by key
+	for _, header := range c.varyHeaders {
+		digest.Write([]byte{'\n'})
+		digest.Write([]byte(header + ":" + strings.Join(r.Header.Values(header), ",")))
+	}
+	if c.varyUserinfo {
+		digest.Write([]byte{'\n'})
+		digest.Write([]byte(r.Header.Get(Authorization)))
+	}
+
+	return fmt.Sprintf("vv:cache:%s:%s:%s", c.fullMethod, version, hex.EncodeToString(digest.Sum(nil))), nil
+}
+
+// checkCache the cached response served ahead of the whitelisting of gateway and the authorization of server,
+// so options.cache can't be declared with them; a hit would outlive an expired or revoked token
+func checkCache(fullMethod string, serviceHandler *options.ServiceHandler, methodHandler *options.MethodHandler) {
+	if methodHandler.GetCache() == nil {
+		return
+	}
+
+	switch {
+	case serviceHandler.GetWhitelisting() != "" || methodHandler.GetWhitelisting() != "":
+		panic(fmt.Sprintf("%s cache conflicts with whitelisting", fullMethod))
+
+	case serviceHandler.GetAuthorization() != "" || methodHandler.GetAuthorization() != "":
+		panic(fmt.Sprintf("%s cache conflicts with authorization", fullMethod))
+
+	case serviceHandler.GetAuthorizationProxy() != "" || methodHandler.GetAuthorizationProxy() != "":
+		panic(fmt.Sprintf("%s cache conflicts with authorization_proxy", fullMethod))
+	}
+}
+
+// writeCacheHeaders the ETag, Cache-Control and Vary of entry
+func (c *cacheRoute) writeCacheHeaders(header http.Header, entry *cacheEntry, now time.Time) {
+	scope := "public"
+	if c.varyUserinfo {
+		scope = "private"
+	}
+
+	header.Set("ETag", entry.ETag)
+	header.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int(entry.ExpireAt.Sub(now).Seconds())))
+
+	vary := append([]string{}, c.varyHeaders...)
+	if c.varyUserinfo {
+		vary = append(vary, http.CanonicalHeaderKey(Authorization))
+	}
+	if len(vary) > 0 {
+		header.Set("Vary", strings.Join(vary, ", "))
+	}
+}
+
+func cacheVersionKey(fullMethod string) string {
+	return "vv:cache:version:" + fullMethod
+}
+
+// cacheVersion the version of the cached responses of method, initialized if missing
+func cacheVersion(store proposal.CacheStore, fullMethod string) (string, error) {
+	version, ok, err := store.Get(cacheVersionKey(fullMethod))
+	if err != nil {
+		return "", errors.Wrapf(err, "get cache version of %s err", fullMethod)
+	}
+	if ok {
+		return string(version), nil
+	}
+
+	return bumpCacheVersion(store, fullMethod)
+}
+
+func bumpCacheVersion(store proposal.CacheStore, fullMethod string) (string, error) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := store.Set(cacheVersionKey(fullMethod), []byte(version), 0); err != nil {
+		return "", errors.Wrapf(err, "set cache version of %s err", fullMethod)
+	}
+
+	return version, nil
+}
+
+// InvalidateCache drop the cached responses of methods, by bumping their versions in store
+func InvalidateCache(store proposal.CacheStore, fullMethods ...string) error {
+	for _, fullMethod := range fullMethods {
+		if _, err := bumpCacheVersion(store, fullMethod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lookupCache(store proposal.CacheStore, key string) (*cacheEntry, error) {
+	raw, ok, err := store.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get %s err", key)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	entry := new(cacheEntry)
+	if err = json.Unmarshal(raw, entry); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s err", key)
+	}
+
+	return entry, nil
+}
+
+// cachedHeader the Content-Type and forwarded metadata, journal id excluded
+func cachedHeader(header http.Header) http.Header {
+	cached := make(http.Header)
+	for k, v := range header {
+		switch {
+		case k == "Content-Type":
+		case strings.HasPrefix(k, runtime.MetadataHeaderPrefix) && !strings.HasSuffix(strings.ToLower(k), JournalID):
+		default:
+			continue
+		}
+
+		cached[k] = v
+	}
+
+	return cached
+}
+
+// cacheDirectives the directives of Cache-Control, arguments dropped
+func cacheDirectives(value string) map[string]bool {
+	directives := make(map[string]bool)
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if i := strings.IndexByte(directive, '='); i != -1 {
+			directive = directive[:i]
+		}
+		if directive != "" {
+			directives[directive] = true
+		}
+	}
+
+	return directives
+}
+
+// etagMatched whether If-None-Match matches the etag, weak comparison
+func etagMatched(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheRecorder buffer the response, so that it could be cached before written
+type cacheRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *cacheRecorder) Header() http.Header {
+	return c.header
+}
+
+func (c *cacheRecorder) WriteHeader(status int) {
+	c.status = status
+}
+
+func (c *cacheRecorder) Write(p []byte) (int, error) {
+	return c.body.Write(p)
+}
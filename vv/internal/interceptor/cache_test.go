@@ -0,0 +1,194 @@
+package interceptor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+type mapStore struct {
+	sync.Mutex
+	values map[string][]byte
+}
+
+func (m *mapStore) Get(key string) ([]byte, bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	value, ok := m.values[key]
+	return value, ok, nil
+}
+
+func (m *mapStore) Set(key string, value []byte, ttl time.Duration) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapStore) Close() error {
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+
+	registerFile(t, dummyFile("dummy/cache.proto", "ArticleService", dummyMethod("Get"), dummyMethod("Mine")))
+	setMethodHandler(t, "/dummy.ArticleService/Get", &options.MethodHandler{
+		Cache: &options.Cache{Ttl: proto.String("1m"), VaryHeaders: []string{"accept-language"}},
+	}, &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/articles/{id}"}})
+	setMethodHandler(t, "/dummy.ArticleService/Mine", &options.MethodHandler{
+		Cache: &options.Cache{Ttl: proto.String("1m"), VaryUserinfo: proto.Bool(true)},
+	}, &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/mine/articles"}})
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(Gateway, "", &proposal.MetricsConfig{Registry: registry})
+	store := &mapStore{values: make(map[string][]byte)}
+
+	calls := 0
+	handler := Cache(zap.NewNop(), metrics, store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Grpc-Metadata-Journal-Id", "journal-1")
+		w.Header().Set("Grpc-Metadata-Tenant", "demo")
+		if r.URL.Path == "/v1/articles/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"title":"` + r.Header.Get("Accept-Language") + r.Header.Get(Authorization) + `"}`))
+	}))
+
+	get := func(path string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := get("/v1/articles/1?b=2&a=1")
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal("MISS", recorder.Header().Get(XCache))
+	assert.Equal("public, max-age=60", recorder.Header().Get("Cache-Control"))
+	assert.Equal("Accept-Language", recorder.Header().Get("Vary"))
+	etag := recorder.Header().Get("ETag")
+	assert.NotEmpty(etag)
+
+	recorder = get("/v1/articles/1?a=1&b=2") // query sorted
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal("HIT", recorder.Header().Get(XCache))
+	assert.Equal(`{"title":""}`, recorder.Body.String())
+	assert.Equal("application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal("demo", recorder.Header().Get("Grpc-Metadata-Tenant"))
+	assert.Empty(recorder.Header().Get("Grpc-Metadata-Journal-Id"))
+	assert.Equal(etag, recorder.Header().Get("ETag"))
+	assert.Equal("0", recorder.Header().Get("Age"))
+	assert.Equal(1, calls)
+
+	recorder = get("/v1/articles/1?a=1&b=2", "If-None-Match", "W/"+etag)
+	assert.Equal(http.StatusNotModified, recorder.Code)
+	assert.Empty(recorder.Body.String())
+
+	recorder = get("/v1/articles/1?a=1&b=2", "Accept-Language", "zh-CN") // vary by header
+	assert.Equal("MISS", recorder.Header().Get(XCache))
+	assert.Equal(`{"title":"zh-CN"}`, recorder.Body.String())
+	assert.Equal(2, calls)
+
+	recorder = get("/v1/articles/1?a=1&b=2", "Cache-Control", "no-cache") // revalidated by backend
+	assert.Equal("MISS", recorder.Header().Get(XCache))
+	assert.Equal(3, calls)
+
+	recorder = get("/v1/articles/1?a=1&b=2", "Cache-Control", "no-store")
+	assert.Equal("BYPASS", recorder.Header().Get(XCache))
+	assert.Empty(recorder.Header().Get("ETag"))
+	assert.Equal(4, calls)
+
+	get("/v1/articles/404")
+	recorder = get("/v1/articles/404") // errors not cached
+	assert.Equal(http.StatusNotFound, recorder.Code)
+	assert.Equal("MISS", recorder.Header().Get(XCache))
+	assert.Equal(6, calls)
+
+	// vary by userinfo
+	recorder = get("/v1/mine/articles", "Authorization", "Bearer alice")
+	assert.Equal("private, max-age=60", recorder.Header().Get("Cache-Control"))
+	assert.Equal("HIT", get("/v1/mine/articles", "Authorization", "Bearer alice").Header().Get(XCache))
+	recorder = get("/v1/mine/articles", "Authorization", "Bearer bob")
+	assert.Equal("MISS", recorder.Header().Get(XCache))
+	assert.Equal(`{"title":"Bearer bob"}`, recorder.Body.String())
+	assert.Equal(8, calls)
+
+	// invalidated by server
+	assert.NoError(InvalidateCache(store, "/dummy.ArticleService/Get"))
+	assert.Equal("MISS", get("/v1/articles/1?a=1&b=2").Header().Get(XCache))
+	assert.Equal("HIT", get("/v1/mine/articles", "Authorization", "Bearer bob").Header().Get(XCache))
+
+	counter := func(method, result string) float64 {
+		return testutil.ToFloat64(metrics.cacheCounter.WithLabelValues(method, result))
+	}
+	assert.Equal(float64(2), counter("/dummy.ArticleService/Get", cacheHit))
+	assert.Equal(float64(6), counter("/dummy.ArticleService/Get", cacheMiss))
+	assert.Equal(float64(1), counter("/dummy.ArticleService/Get", cacheBypass))
+	assert.Equal(float64(2), counter("/dummy.ArticleService/Mine", cacheHit))
+
+	recorder = get("/v1/echo") // not declared
+	assert.Empty(recorder.Header().Get(XCache))
+
+	setMethodHandler(t, "/dummy.ArticleService/Mine", &options.MethodHandler{Cache: &options.Cache{Ttl: proto.String("forever")}},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/mine/articles"}})
+
+	assert.PanicsWithValue("options.cache ttl of /dummy.ArticleService/Mine illegal", func() {
+		Cache(zap.NewNop(), nil, store)
+	})
+}
+
+func TestCheckCache(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := &options.Cache{Ttl: proto.String("1m"), VaryUserinfo: proto.Bool(true)}
+	assert.NotPanics(func() {
+		checkCache("/dummy.SecretService/Get", nil, &options.MethodHandler{Cache: cache})
+		checkCache("/dummy.SecretService/Get", &options.ServiceHandler{Authorization: proto.String("dummy_sso")}, &options.MethodHandler{})
+	})
+
+	// a hit served before the token validated, even if expired or revoked
+	assert.PanicsWithValue("/dummy.SecretService/Get cache conflicts with authorization", func() {
+		checkCache("/dummy.SecretService/Get", &options.ServiceHandler{Authorization: proto.String("dummy_sso")}, &options.MethodHandler{Cache: cache})
+	})
+	assert.PanicsWithValue("/dummy.SecretService/Get cache conflicts with authorization_proxy", func() {
+		checkCache("/dummy.SecretService/Get", nil, &options.MethodHandler{Cache: cache, AuthorizationProxy: proto.String("dummy_sign")})
+	})
+	assert.PanicsWithValue("/dummy.SecretService/Get cache conflicts with whitelisting", func() {
+		checkCache("/dummy.SecretService/Get", &options.ServiceHandler{Whitelisting: proto.String("dummy_iplist")}, &options.MethodHandler{Cache: cache})
+	})
+}
+
+func TestCacheDirectives(t *testing.T) {
+	assert := assert.New(t)
+
+	directives := cacheDirectives("No-Cache, max-age=0")
+	assert.True(directives["no-cache"])
+	assert.True(directives["max-age"])
+	assert.False(directives["no-store"])
+
+	assert.True(etagMatched(`"a", W/"b"`, `"b"`))
+	assert.True(etagMatched("*", `"b"`))
+	assert.False(etagMatched(`"a"`, `"b"`))
+	assert.False(etagMatched("", `"b"`))
+}
@@ -0,0 +1,265 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// CatalogPath the method catalog endpoint of gateway
+const CatalogPath = "/catalog"
+
+// ServiceCatalog what a service exposes and how it is protected
+type ServiceCatalog struct {
+	Name    string           `json:"name"`
+	Methods []*MethodCatalog `json:"methods"`
+}
+
+// MethodCatalog what a method exposes and how it is protected, the settings are effective ones (method overrides service)
+type MethodCatalog struct {
+	FullMethod         string          `json:"full_method"`
+	ClientStreaming    bool            `json:"client_streaming,omitempty"`
+	ServerStreaming    bool            `json:"server_streaming,omitempty"`
+	Input              string          `json:"input"`
+	Output             string          `json:"output"`
+	HTTPRules          []*HTTPRule     `json:"http_rules,omitempty"`
+	Authorization      string          `json:"authorization,omitempty"`
+	Scopes             []string        `json:"scopes,omitempty"`
+	Roles              json.RawMessage `json:"roles,omitempty"`
+	Permissions        json.RawMessage `json:"permissions,omitempty"`
+	PeerCertificate    json.RawMessage `json:"peer_certificate,omitempty"`
+	AuthorizationProxy string          `json:"authorization_proxy,omitempty"`
+	Whitelisting       string          `json:"whitelisting,omitempty"`
+	Journal            bool            `json:"journal,omitempty"`
+	MetricsAlias       string          `json:"metrics_alias,omitempty"`
+	RateLimit          json.RawMessage `json:"rate_limit,omitempty"`
+	Timeout            string          `json:"timeout,omitempty"`
+	Cors               json.RawMessage `json:"cors,omitempty"`
+	Upload             json.RawMessage `json:"upload,omitempty"`
+	Download           bool            `json:"download,omitempty"`
+	Media              json.RawMessage `json:"media,omitempty"`
+	Validators         []*FieldCatalog `json:"validators,omitempty"`
+}
+
+// HTTPRule one binding of google.api.http
+type HTTPRule struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+// FieldCatalog the validator constraints and redaction of a field, nested fields named by full path
+type FieldCatalog struct {
+	Field     string          `json:"field"`
+	Rules     json.RawMessage `json:"rules,omitempty"`
+	Sensitive string          `json:"sensitive,omitempty"`
+}
+
+// Catalog list every resolved method which has http rule or interceptor options, ignored ones excluded
+func Catalog() []*ServiceCatalog {
+	var catalog []*ServiceCatalog
+
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		serivces := fd.Services()
+		for i := 0; i < serivces.Len(); i++ {
+			serivce := serivces.Get(i)
+			serviceName := string(serivce.FullName())
+			serviceHandler, _ := getServiceHandler(serviceName)
+
+			serviceCatalog := &ServiceCatalog{Name: serviceName}
+
+			methods := serivce.Methods()
+			for k := 0; k < methods.Len(); k++ {
+				method := methods.Get(k)
+				fullMethod := fmt.Sprintf("/%s/%s", serivce.FullName(), method.Name())
+
+				methodHandler, _ := getMethodHandler(fullMethod)
+				if methodHandler.GetIgnore() {
+					continue
+				}
+
+				httpRule, _ := getHTTPRule(fullMethod)
+				if httpRule == nil && methodHandler == nil && serviceHandler == nil {
+					continue
+				}
+
+				serviceCatalog.Methods = append(serviceCatalog.Methods, methodCatalog(fullMethod, method, serviceHandler, methodHandler, httpRule))
+			}
+
+			if len(serviceCatalog.Methods) > 0 {
+				catalog = append(catalog, serviceCatalog)
+			}
+		}
+
+		return true
+	})
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog
+}
+
+func methodCatalog(fullMethod string, method protoreflect.MethodDescriptor, serviceHandler *options.ServiceHandler, methodHandler *options.MethodHandler, httpRule *annotations.HttpRule) *MethodCatalog {
+	catalog := &MethodCatalog{
+		FullMethod:      fullMethod,
+		ClientStreaming: method.IsStreamingClient(),
+		ServerStreaming: method.IsStreamingServer(),
+		Input:           string(method.Input().FullName()),
+		Output:          string(method.Output().FullName()),
+		HTTPRules:       httpRules(httpRule),
+		Journal:         methodHandler.GetJournal(),
+		MetricsAlias:    methodHandler.GetMetricsAlias(),
+		Scopes:          methodHandler.GetScopes(),
+		Download:        methodHandler.GetDownload(),
+	}
+
+	catalog.Authorization = serviceHandler.GetAuthorization()
+	if methodHandler.GetAuthorization() != "" {
+		catalog.Authorization = methodHandler.GetAuthorization()
+	}
+
+	catalog.AuthorizationProxy = serviceHandler.GetAuthorizationProxy()
+	if methodHandler.GetAuthorizationProxy() != "" {
+		catalog.AuthorizationProxy = methodHandler.GetAuthorizationProxy()
+	}
+
+	catalog.Whitelisting = serviceHandler.GetWhitelisting()
+	if methodHandler.GetWhitelisting() != "" {
+		catalog.Whitelisting = methodHandler.GetWhitelisting()
+	}
+
+	catalog.Timeout = serviceHandler.GetTimeout()
+	if methodHandler.GetTimeout() != "" {
+		catalog.Timeout = methodHandler.GetTimeout()
+	}
+
+	if roles := methodHandler.GetRoles(); roles != nil {
+		catalog.Roles = marshalOption(roles)
+	} else if roles = serviceHandler.GetRoles(); roles != nil {
+		catalog.Roles = marshalOption(roles)
+	}
+
+	if permissions := methodHandler.GetPermissions(); permissions != nil {
+		catalog.Permissions = marshalOption(permissions)
+	} else if permissions = serviceHandler.GetPermissions(); permissions != nil {
+		catalog.Permissions = marshalOption(permissions)
+	}
+
+	if peerCertificate := getPeerCertificate(string(method.Parent().FullName()), fullMethod); peerCertificate != nil {
+		catalog.PeerCertificate = marshalOption(peerCertificate)
+	}
+
+	if methodHandler.GetCors() != nil {
+		catalog.Cors = marshalOption(methodHandler.GetCors())
+	}
+
+	if methodHandler.GetUpload() != nil {
+		catalog.Upload = marshalOption(methodHandler.GetUpload())
+	}
+
+	if _, rateLimit := getRateLimit(string(method.Parent().FullName()), fullMethod); rateLimit != nil {
+		catalog.RateLimit = marshalOption(rateLimit)
+	}
+
+	if media, _ := proto.GetExtension(method.Input().Options(), validator.E_Media).(*validator.MediaValidator); media != nil {
+		catalog.Media = marshalOption(media)
+	}
+
+	catalog.Validators = fieldCatalog(method.Input(), "", make(map[protoreflect.FullName]bool))
+	return catalog
+}
+
+func httpRules(rule *annotations.HttpRule) []*HTTPRule {
+	if rule == nil {
+		return nil
+	}
+
+	var method, path string
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, path = "GET", pattern.Get
+	case *annotations.HttpRule_Put:
+		method, path = "PUT", pattern.Put
+	case *annotations.HttpRule_Post:
+		method, path = "POST", pattern.Post
+	case *annotations.HttpRule_Delete:
+		method, path = "DELETE", pattern.Delete
+	case *annotations.HttpRule_Patch:
+		method, path = "PATCH", pattern.Patch
+	case *annotations.HttpRule_Custom:
+		method, path = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	}
+
+	rules := []*HTTPRule{{Method: method, Path: path, Body: rule.GetBody()}}
+	for _, binding := range rule.GetAdditionalBindings() {
+		rules = append(rules, httpRules(binding)...)
+	}
+	return rules
+}
+
+// fieldCatalog walk the message recursively, visited guard against recursive message
+func fieldCatalog(message protoreflect.MessageDescriptor, prefix string, visited map[protoreflect.FullName]bool) []*FieldCatalog {
+	if visited[message.FullName()] {
+		return nil
+	}
+	visited[message.FullName()] = true
+	defer delete(visited, message.FullName())
+
+	var catalog []*FieldCatalog
+
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		path := prefix + string(field.Name())
+
+		rules, _ := proto.GetExtension(field.Options(), validator.E_Field).(*validator.FieldValidator)
+		sensitive := sensitiveOf(field)
+		if rules != nil || sensitive != nil {
+			fieldCatalog := &FieldCatalog{Field: path}
+			if rules != nil {
+				fieldCatalog.Rules = marshalOption(rules)
+			}
+			if sensitive != nil {
+				fieldCatalog.Sensitive = sensitive.GetMode().String()
+			}
+			catalog = append(catalog, fieldCatalog)
+		}
+
+		if field.Message() != nil && !field.IsMap() {
+			catalog = append(catalog, fieldCatalog(field.Message(), path+".", visited)...)
+		}
+	}
+
+	return catalog
+}
+
+func marshalOption(option proto.Message) json.RawMessage {
+	raw, _ := protojson.MarshalOptions{UseProtoNames: true}.Marshal(option)
+	return raw
+}
+
+// CatalogHandler serve the catalog as json, the catalog snapshot taken once after ResloveFileDescriptor
+func CatalogHandler() runtime.HandlerFunc {
+	raw, err := json.Marshal(Catalog())
+	if err != nil {
+		panic(err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(raw)
+	}
+}
@@ -0,0 +1,88 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"testing"
+
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestCatalog(t *testing.T) {
+	assert := assert.New(t)
+
+	rules := httpRules(&annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/v1/echo"},
+		Body:    "*",
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Get{Get: "/v1/echo/{message}"}},
+		},
+	})
+	assert.Equal([]*HTTPRule{
+		{Method: "POST", Path: "/v1/echo", Body: "*"},
+		{Method: "GET", Path: "/v1/echo/{message}"},
+	}, rules)
+
+	fieldOptions := func(rules *validator.FieldValidator) *descriptorpb.FieldOptions {
+		options := new(descriptorpb.FieldOptions)
+		proto.SetExtension(options, validator.E_Field, rules)
+		return options
+	}
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dummy/catalog.proto"),
+		Package:    proto.String("dummy"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{validator.File_validator_options_proto.Path()},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Request"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("message"),
+						Number:   proto.Int32(1),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						JsonName: proto.String("message"),
+						Options:  fieldOptions(&validator.FieldValidator{Require: proto.Bool(true), Ne: proto.String("")}),
+					},
+					{
+						Name:     proto.String("child"),
+						Number:   proto.Int32(2),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".dummy.Request"),
+						JsonName: proto.String("child"),
+					},
+					{
+						Name:     proto.String("cellphone"),
+						Number:   proto.Int32(3),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						JsonName: proto.String("cellphone"),
+						Options:  fieldOptions(&validator.FieldValidator{CnMobile: proto.Bool(true)}),
+					},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	if !assert.NoError(err) {
+		return
+	}
+
+	fields := fieldCatalog(file.Messages().ByName("Request"), "", make(map[protoreflect.FullName]bool))
+	if assert.Len(fields, 2) { // recursive child not expanded
+		assert.Equal("message", fields[0].Field)
+		assert.JSONEq(`{"require":true,"ne":""}`, string(fields[0].Rules))
+		assert.Equal("cellphone", fields[1].Field)
+		assert.JSONEq(`{"cn_mobile":true}`, string(fields[1].Rules))
+	}
+
+	raw, err := json.Marshal(&MethodCatalog{FullMethod: "/dummy.DummyService/Echo", Validators: fields})
+	assert.NoError(err)
+	assert.Contains(string(raw), `"field":"cellphone"`)
+}
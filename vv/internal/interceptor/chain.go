@@ -0,0 +1,242 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bluekaki/pkg/trace"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/grpc"
+)
+
+var builtinStages = map[Role][]proposal.Stage{
+	Gateway: {proposal.StageJournal, proposal.StageWhitelisting},
+	Client:  {proposal.StageJournal, proposal.StageAuthorizationProxy},
+	Server:  {proposal.StageJournal, proposal.StageValidation, proposal.StagePeerCertificate, proposal.StageAuthorization, proposal.StageAuthorizationProxy, proposal.StageRateLimit},
+}
+
+// Placement where a custom interceptor placed
+type Placement struct {
+	Name     string
+	Stage    proposal.Stage
+	Position proposal.Position
+}
+
+// ServerLink a custom interceptor for server
+type ServerLink struct {
+	Placement
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// ClientLink a custom interceptor for gateway or client
+type ClientLink struct {
+	Placement
+	Unary  grpc.UnaryClientInterceptor
+	Stream grpc.StreamClientInterceptor
+}
+
+// ServerOptions the optional stages of server interceptors, the zero value disables all
+type ServerOptions struct {
+	CollectAllViolations bool
+	Limiter              *RateLimiter
+	Idempotency          *Idempotency // unary only
+	Tracer               *trace.Tracer
+	Journals             *JournalWriter
+	Links                []ServerLink
+}
+
+// GatewayOptions the optional stages of gateway interceptors, the zero value disables all
+type GatewayOptions struct {
+	Idempotency *Idempotency // unary only
+	Tracer      *trace.Tracer
+	Journals    *JournalWriter
+	Links       []ClientLink
+}
+
+// ClientOptions the optional stages of client interceptors, the zero value disables all
+type ClientOptions struct {
+	Tracer *trace.Tracer
+	Links  []ClientLink
+}
+
+// node one element of a resolved chain, link is -1 if it's a built-in stage
+type node struct {
+	name  string
+	stage proposal.Stage
+	link  int
+}
+
+func resolveChain(role Role, placements []Placement) []node {
+	stages := builtinStages[role]
+
+	names := make(map[string]bool)
+	for _, stage := range stages {
+		names[string(stage)] = true
+	}
+
+	before := make(map[proposal.Stage][]int)
+	after := make(map[proposal.Stage][]int)
+	for i, placement := range placements {
+		if placement.Name == "" {
+			panic("interceptor name required")
+		}
+		if names[placement.Name] {
+			panic(fmt.Sprintf("interceptor: %s has exists", placement.Name))
+		}
+		names[placement.Name] = true
+
+		found := false
+		for _, stage := range stages {
+			if stage == placement.Stage {
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic(fmt.Sprintf("interceptor %s: stage %s not supported", placement.Name, placement.Stage))
+		}
+
+		switch placement.Position {
+		case proposal.Before:
+			before[placement.Stage] = append(before[placement.Stage], i)
+		case proposal.After:
+			after[placement.Stage] = append(after[placement.Stage], i)
+		default:
+			panic(fmt.Sprintf("interceptor %s: position %d illegal", placement.Name, placement.Position))
+		}
+	}
+
+	var chain []node
+	for _, stage := range stages {
+		for _, i := range before[stage] {
+			chain = append(chain, node{name: placements[i].Name, link: i})
+		}
+
+		chain = append(chain, node{name: string(stage), stage: stage, link: -1})
+
+		for _, i := range after[stage] {
+			chain = append(chain, node{name: placements[i].Name, link: i})
+		}
+	}
+
+	return chain
+}
+
+// Chain the names of resolved chain in order, built-in stages included
+func Chain(role Role, placements []Placement) []string {
+	chain := resolveChain(role, placements)
+
+	names := make([]string, len(chain))
+	for i, node := range chain {
+		names[i] = node.name
+	}
+	return names
+}
+
+// splitChain split chain into the custom interceptors outside journal and the rest inside journal
+func splitChain(chain []node) (outer, inner []node) {
+	for i, node := range chain {
+		if node.link == -1 && node.stage == proposal.StageJournal {
+			return chain[:i], chain[i+1:]
+		}
+	}
+	return nil, chain
+}
+
+func filterServerLinks(links []ServerLink, unary bool) []ServerLink {
+	var filtered []ServerLink
+	for _, link := range links {
+		if (unary && link.Unary != nil) || (!unary && link.Stream != nil) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+func filterClientLinks(links []ClientLink, unary bool) []ClientLink {
+	var filtered []ClientLink
+	for _, link := range links {
+		if (unary && link.Unary != nil) || (!unary && link.Stream != nil) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+func serverPlacements(links []ServerLink) []Placement {
+	placements := make([]Placement, len(links))
+	for i, link := range links {
+		placements[i] = link.Placement
+	}
+	return placements
+}
+
+func clientPlacements(links []ClientLink) []Placement {
+	placements := make([]Placement, len(links))
+	for i, link := range links {
+		placements[i] = link.Placement
+	}
+	return placements
+}
+
+// ServerChain the names of server's unary or stream interceptor chain in order
+func ServerChain(links []ServerLink, unary bool) []string {
+	return Chain(Server, serverPlacements(filterServerLinks(links, unary)))
+}
+
+// ClientChain the names of gateway's or client's unary or stream interceptor chain in order
+func ClientChain(role Role, links []ClientLink, unary bool) []string {
+	return Chain(role, clientPlacements(filterClientLinks(links, unary)))
+}
+
+func chainUnaryServer(interceptors []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+func chainStreamServer(interceptors []grpc.StreamServerInterceptor, info *grpc.StreamServerInfo, handler grpc.StreamHandler) grpc.StreamHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(srv interface{}, stream grpc.ServerStream) error {
+			return interceptor(srv, stream, info, next)
+		}
+	}
+	return handler
+}
+
+func chainUnaryClient(interceptors []grpc.UnaryClientInterceptor, invoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	return invoker
+}
+
+func chainStreamClient(interceptors []grpc.StreamClientInterceptor, streamer grpc.Streamer) grpc.Streamer {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], streamer
+		streamer = func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return interceptor(ctx, desc, cc, method, next, opts...)
+		}
+	}
+	return streamer
+}
+
+// wrappedServerStream carry a new context into the handler
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedServerStream) Context() context.Context {
+	return w.ctx
+}
@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerChain(t *testing.T) {
+	assert := assert.New(t)
+
+	var trace []string
+	link := func(name string, position proposal.Position, stage proposal.Stage) ServerLink {
+		return ServerLink{
+			Placement: Placement{Name: name, Stage: stage, Position: position},
+			Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				trace = append(trace, name)
+				return handler(ctx, req)
+			},
+		}
+	}
+
+	links := []ServerLink{
+		link("audit", proposal.After, proposal.StageAuthorization),
+		link("tenant", proposal.Before, proposal.StageJournal),
+		link("feature", proposal.After, proposal.StageJournal),
+	}
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{Links: links})
+	resp, err := unary(metadata.NewIncomingContext(context.Background(), metadata.MD{}), nil, &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		trace = append(trace, "handler")
+		return "ok", nil
+	})
+
+	assert.Nil(err)
+	assert.Equal("ok", resp)
+	assert.Equal([]string{"tenant", "feature", "audit", "handler"}, trace)
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/bluekaki/pkg/errors"
 	"github.com/bluekaki/pkg/id"
 	"github.com/bluekaki/pkg/pbutil"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/pb"
 	"github.com/bluekaki/pkg/vv/proposal"
 
@@ -24,13 +26,16 @@ import (
 )
 
 // UnaryClientInterceptor unary interceptor for client
-func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, signer proposal.Signer, projectName string) grpc.UnaryClientInterceptor {
+func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, signer proposal.Signer, projectName string, opt ClientOptions) grpc.UnaryClientInterceptor {
+	links := filterClientLinks(opt.Links, true)
+	outer, inner := splitChain(resolveChain(Client, clientPlacements(links)))
 
-	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+	journal := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
 		ts := time.Now()
 
 		doJournal := false
 		ignore := false
+		metricsMethod := method
 		if methodHandler, ok := getMethodHandler(method); ok {
 			if methodHandler.Journal != nil && *methodHandler.Journal {
 				doJournal = true
@@ -39,8 +44,14 @@ func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, s
 			if methodHandler.Ignore != nil && *methodHandler.Ignore {
 				ignore = true
 			}
+
+			if methodHandler.MetricsAlias != nil && *methodHandler.MetricsAlias != "" {
+				metricsMethod = *methodHandler.MetricsAlias
+			}
 		}
 
+		defer metrics.start(metricsMethod)()
+
 		var journalID string
 		meta, ok := metadata.FromOutgoingContext(ctx)
 		if !ok {
@@ -56,12 +67,24 @@ func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, s
 		meta.Set(JournalID, journalID)
 		ctx = metadata.NewOutgoingContext(ctx, meta)
 
+		parent := trace.SpanContextFromContext(ctx)
+		if !parent.IsValid() {
+			parent = spanContextFromMeta(meta)
+		}
+
+		span := startSpan(opt.Tracer, method, trace.KindClient, parent, journalID)
+		injectSpanContext(meta, span)
+
 		defer func() {
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       method,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -121,9 +144,10 @@ func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, s
 						}
 					}
 
-					err = status.New(s.Code(), s.Message()).Err() // reset detail
+					err = remoteError(s) // reset detail, the BzError reconstructed
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
 				if err == nil {
@@ -133,38 +157,81 @@ func UnaryClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, s
 					logger.Error("client unary interceptor", zap.Any("journal", marshalJournal(journal)))
 				}
 			}
+
+			metrics.observeSize(metricsMethod, "", true, req)
+			if err == nil {
+				metrics.observeSize(metricsMethod, "", false, reply)
+			}
+			metrics.observe(metricsMethod, "", ts, err)
+
+			finishSpan(span, err)
 		}()
 
-		if signer != nil {
-			var raw json.RawMessage
-			if req != nil {
-				if raw, err = pbutil.ProtoMessage2JSON(req.(protoV1.Message)); err != nil {
-					return
-				}
+		interceptors := make([]grpc.UnaryClientInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Unary
+				continue
 			}
 
-			var signature, date string
-			if signature, date, err = signer(method, []byte(raw)); err != nil {
-				return
+			switch node.stage {
+			case proposal.StageAuthorizationProxy:
+				interceptors[i] = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+					if signer == nil {
+						return invoker(ctx, method, req, reply, cc, opts...)
+					}
+
+					var raw json.RawMessage
+					if req != nil {
+						var err error
+						if raw, err = pbutil.ProtoMessage2JSON(req.(protoV1.Message)); err != nil {
+							return err
+						}
+					}
+
+					signature, date, err := signer(method, []byte(raw))
+					if err != nil {
+						return err
+					}
+
+					return invoker(sign(ctx, meta, signature, date), method, req, reply, cc, opts...)
+				}
 			}
+		}
 
-			meta.Set(AuthorizationProxy, signature)
-			meta.Set(Date, date)
-			ctx = metadata.NewOutgoingContext(ctx, meta)
+		// the remaining budget of upstream is forwarded by the deadline of ctx
+		ctx, cancel := withTimeout(ctx, getTimeout(strings.Split(method, "/")[1], method))
+		defer cancel()
+
+		if err = checkDeadline(ctx, method); err != nil {
+			return
 		}
 
-		return invoker(ctx, method, req, reply, cc, opts...)
+		return chainUnaryClient(interceptors, invoker)(ctx, method, req, reply, cc, opts...)
+	}
+
+	interceptors := make([]grpc.UnaryClientInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Unary)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return chainUnaryClient(interceptors, invoker)(ctx, method, req, reply, cc, opts...)
 	}
 }
 
 // StreamClientInterceptor stream interceptor for client
-func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, signer proposal.Signer, projectName string) grpc.StreamClientInterceptor {
+func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, signer proposal.Signer, projectName string, opt ClientOptions) grpc.StreamClientInterceptor {
+	links := filterClientLinks(opt.Links, false)
+	outer, inner := splitChain(resolveChain(Client, clientPlacements(links)))
 
-	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
+	journal := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
 		ts := time.Now()
 
 		doJournal := false
 		ignore := false
+		metricsMethod := fullMethod
 		if methodHandler, ok := getMethodHandler(fullMethod); ok {
 			if methodHandler.Journal != nil && *methodHandler.Journal {
 				doJournal = true
@@ -173,8 +240,19 @@ func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 			if methodHandler.Ignore != nil && *methodHandler.Ignore {
 				ignore = true
 			}
+
+			if methodHandler.MetricsAlias != nil && *methodHandler.MetricsAlias != "" {
+				metricsMethod = *methodHandler.MetricsAlias
+			}
 		}
 
+		inFlight := metrics.start(metricsMethod)
+		defer func() {
+			if err != nil {
+				inFlight() // otherwise the stream in flight until finished
+			}
+		}()
+
 		var journalID string
 		meta, ok := metadata.FromOutgoingContext(ctx)
 		if !ok {
@@ -191,12 +269,24 @@ func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 		meta.Set(JournalID, journalID)
 		ctx = metadata.NewOutgoingContext(ctx, meta)
 
+		parent := trace.SpanContextFromContext(ctx)
+		if !parent.IsValid() {
+			parent = spanContextFromMeta(meta)
+		}
+
+		span := startSpan(opt.Tracer, fullMethod, trace.KindClient, parent, journalID)
+		injectSpanContext(meta, span)
+
 		defer func() {
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       fullMethod,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -243,9 +333,10 @@ func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 						}
 					}
 
-					err = status.New(s.Code(), s.Message()).Err() // reset detail
+					err = remoteError(s) // reset detail, the BzError reconstructed
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
 				if err == nil {
@@ -255,40 +346,98 @@ func StreamClientInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 					logger.Error("client stream interceptor", zap.Any("journal", marshalJournal(journal)))
 				}
 			}
+
+			metrics.observe(metricsMethod, "", ts, err)
+
+			if err != nil {
+				finishSpan(span, err)
+			}
 		}()
 
-		if signer != nil {
-			var signature, date string
-			if signature, date, err = signer(fullMethod, []byte(journalID)); err != nil {
-				return
+		interceptors := make([]grpc.StreamClientInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Stream
+				continue
 			}
 
-			meta.Set(AuthorizationProxy, signature)
-			meta.Set(Date, date)
-			ctx = metadata.NewOutgoingContext(ctx, meta)
+			switch node.stage {
+			case proposal.StageAuthorizationProxy:
+				interceptors[i] = func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					if signer == nil {
+						return streamer(ctx, desc, cc, fullMethod, opts...)
+					}
+
+					signature, date, err := signer(fullMethod, []byte(journalID))
+					if err != nil {
+						return nil, err
+					}
+
+					return streamer(sign(ctx, meta, signature, date), desc, cc, fullMethod, opts...)
+				}
+			}
+		}
+
+		// the remaining budget of upstream is forwarded by the deadline of ctx
+		ctx, cancel := withTimeout(ctx, getTimeout(strings.Split(fullMethod, "/")[1], fullMethod))
+
+		if err = checkDeadline(ctx, fullMethod); err != nil {
+			cancel()
+			return
 		}
 
-		s, err := streamer(ctx, desc, cc, fullMethod, opts...)
+		s, err := chainStreamClient(interceptors, streamer)(ctx, desc, cc, fullMethod, opts...)
 		if err != nil {
+			cancel()
 			return nil, err
 		}
 
 		return &streamClientInterceptor{
 			ClientStream: s,
 			logger:       logger,
+			cancel:       cancel,
+			span:         span,
 
 			journalID: journalID,
 
 			ignore:    ignore,
 			doJournal: doJournal,
 			method:    fullMethod,
+
+			metrics:       metrics,
+			metricsMethod: metricsMethod,
+			inFlight:      inFlight,
+			serverStreams: desc.ServerStreams,
 		}, nil
 	}
+
+	interceptors := make([]grpc.StreamClientInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Stream)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return chainStreamClient(interceptors, streamer)(ctx, desc, cc, fullMethod, opts...)
+	}
+}
+
+// sign set signature into the outgoing metadata, the journaled meta also be updated
+func sign(ctx context.Context, journaled metadata.MD, signature, date string) context.Context {
+	journaled.Set(AuthorizationProxy, signature)
+	journaled.Set(Date, date)
+
+	meta, _ := metadata.FromOutgoingContext(ctx)
+	meta.Set(AuthorizationProxy, signature)
+	meta.Set(Date, date)
+	return metadata.NewOutgoingContext(ctx, meta)
 }
 
 type streamClientInterceptor struct {
 	grpc.ClientStream
 	logger *zap.Logger
+	cancel context.CancelFunc
+	span   *trace.Span
 
 	journalID string
 
@@ -300,10 +449,16 @@ type streamClientInterceptor struct {
 	ignore    bool
 	doJournal bool
 	method    string
+
+	metrics       *Metrics
+	metricsMethod string
+	inFlight      func()
+	serverStreams bool
 }
 
 func (s *streamClientInterceptor) SendMsg(m interface{}) (err error) {
 	s.counter.send++
+	s.metrics.observeStreamMsg(s.metricsMethod, "", "send", true, m)
 
 	ts := time.Now()
 	defer func() {
@@ -339,11 +494,28 @@ func (s *streamClientInterceptor) SendMsg(m interface{}) (err error) {
 func (s *streamClientInterceptor) RecvMsg(m interface{}) (err error) {
 	ts := time.Now()
 	defer func() {
+		if err != nil || !s.serverStreams { // io.EOF or the status, or the only response received
+			s.inFlight()
+		}
+
+		if err != nil {
+			s.cancel() // the stream has finished
+
+			if err == io.EOF {
+				finishSpan(s.span, nil)
+			} else {
+				finishSpan(s.span, err)
+			}
+		}
+
 		if err == io.EOF {
 			return
 		}
 
 		s.counter.recv++
+		if err == nil {
+			s.metrics.observeStreamMsg(s.metricsMethod, "", "recv", false, m)
+		}
 
 		if !s.ignore {
 			journal := &pb.Journal{
@@ -378,7 +550,7 @@ func (s *streamClientInterceptor) RecvMsg(m interface{}) (err error) {
 					}
 				}
 
-				err = status.New(s.Code(), s.Message()).Err() // reset detail
+				err = remoteError(s) // reset detail, the BzError reconstructed
 			}
 
 			s.logger.Info("client stream/recv interceptor", zap.Any("journal", marshalJournal(journal)))
@@ -1,6 +1,8 @@
 package interceptor
 
 import (
+	"github.com/bluekaki/pkg/trace"
+
 	"google.golang.org/grpc/metadata"
 )
 
@@ -25,6 +27,12 @@ const (
 	XForwardedHost = "x-forwarded-host"
 	// OctetStream binary files
 	OctetStream = "octet-stream"
+	// IdempotencyKey distinguish the retried requests of options.idempotent, both gateway and grpc
+	IdempotencyKey = "idempotency-key"
+	// Traceparent w3c trace context, both gateway and grpc
+	Traceparent = trace.Traceparent
+	// Tracestate w3c vendor-specific trace context, both gateway and grpc
+	Tracestate = trace.Tracestate
 )
 
 var toLoggedMetadata = map[string]bool{
@@ -37,6 +45,9 @@ var toLoggedMetadata = map[string]bool{
 	XForwardedFor:      true,
 	XForwardedHost:     true,
 	OctetStream:        true,
+	Traceparent:        true,
+	Tracestate:         true,
+	IdempotencyKey:     true,
 }
 
 var gwHeader = struct {
@@ -55,3 +66,10 @@ func forwardedByGrpcGateway(meta metadata.MD) bool {
 
 	return values[0] == gwHeader.value
 }
+
+func firstValue(meta metadata.MD, key string) string {
+	if values := meta.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
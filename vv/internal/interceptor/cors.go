@@ -0,0 +1,223 @@
+package interceptor
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bluekaki/pkg/id"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/rs/cors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	accessControlRequestMethod = "Access-Control-Request-Method"
+	accessControlAllowOrigin   = "Access-Control-Allow-Origin"
+)
+
+type corsRoute struct {
+	fullMethod string
+	method     string
+	path       *regexp.Regexp
+	cors       *cors.Cors
+}
+
+// CORS wrap the gateway mux, the policy of options.cors overrides the default one by method, nil policy means allow all;
+// the rejected preflight journaled as well
+func CORS(logger *zap.Logger, journals *JournalWriter, policy *proposal.CORSPolicy) func(http.Handler) http.Handler {
+	base := corsOptions(policy)
+	defaultCors := cors.New(base)
+
+	var routes []*corsRoute
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		serivces := fd.Services()
+		for i := 0; i < serivces.Len(); i++ {
+			serivce := serivces.Get(i)
+
+			methods := serivce.Methods()
+			for k := 0; k < methods.Len(); k++ {
+				fullMethod := fmt.Sprintf("/%s/%s", serivce.FullName(), methods.Get(k).Name())
+
+				methodHandler, _ := getMethodHandler(fullMethod)
+				if methodHandler.GetCors() == nil {
+					continue
+				}
+
+				httpRule, _ := getHTTPRule(fullMethod)
+				for _, rule := range httpRules(httpRule) {
+					routes = append(routes, &corsRoute{
+						fullMethod: fullMethod,
+						method:     rule.Method,
+						path:       templateRegexp(rule.Path),
+						cors:       cors.New(overrideCorsOptions(base, methodHandler.GetCors())),
+					})
+				}
+			}
+		}
+		return true
+	})
+
+	return func(next http.Handler) http.Handler {
+		defaultHandler := defaultCors.Handler(next)
+		routeHandlers := make([]http.Handler, len(routes))
+		for i, route := range routes {
+			routeHandlers[i] = route.cors.Handler(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			preflight := r.Method == http.MethodOptions && r.Header.Get(accessControlRequestMethod) != ""
+
+			method := r.Method
+			if preflight {
+				method = strings.ToUpper(r.Header.Get(accessControlRequestMethod))
+			}
+
+			handler, fullMethod := defaultHandler, ""
+			for i, route := range routes {
+				if route.method == method && route.path.MatchString(r.URL.Path) {
+					handler, fullMethod = routeHandlers[i], route.fullMethod
+					break
+				}
+			}
+
+			handler.ServeHTTP(w, r)
+
+			if preflight && w.Header().Get(accessControlAllowOrigin) == "" {
+				journalID := r.Header.Get(JournalID)
+				if journalID == "" {
+					journalID = id.JournalID()
+				}
+
+				journal := &pb.Journal{
+					Id: journalID,
+					Request: &pb.Request{
+						Restapi: true,
+						Method:  fullMethod,
+						Metadata: map[string]string{
+							"origin":                         r.Header.Get("Origin"),
+							"access-control-request-method":  r.Header.Get(accessControlRequestMethod),
+							"access-control-request-headers": r.Header.Get("Access-Control-Request-Headers"),
+							URI:                              r.RequestURI,
+						},
+					},
+					Response: &pb.Response{
+						Code:    codes.PermissionDenied.String(),
+						Message: "cors preflight rejected",
+					},
+				}
+
+				raw := marshalJournal(journal)
+				logger.Warn("gateway cors preflight rejected", zap.Any("journal", raw))
+				journals.Write("gateway cors interceptor", journal, raw)
+			}
+		})
+	}
+}
+
+func corsOptions(policy *proposal.CORSPolicy) cors.Options {
+	if policy == nil {
+		return cors.Options{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{
+				http.MethodHead,
+				http.MethodGet,
+				http.MethodPost,
+				http.MethodPut,
+				http.MethodPatch,
+				http.MethodDelete,
+			},
+			AllowedHeaders: []string{"*"},
+		}
+	}
+
+	return cors.Options{
+		AllowedOrigins:   policy.AllowedOrigins,
+		AllowOriginFunc:  policy.AllowOriginFunc,
+		AllowedMethods:   policy.AllowedMethods,
+		AllowedHeaders:   policy.AllowedHeaders,
+		ExposedHeaders:   policy.ExposedHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           int(policy.MaxAge.Seconds()),
+	}
+}
+
+// overrideCorsOptions the fields declared in options.cors override the base
+func overrideCorsOptions(base cors.Options, override *options.Cors) cors.Options {
+	if len(override.GetAllowedOrigins()) > 0 {
+		base.AllowedOrigins = override.GetAllowedOrigins()
+		base.AllowOriginFunc = nil
+	}
+	if len(override.GetAllowedMethods()) > 0 {
+		base.AllowedMethods = override.GetAllowedMethods()
+	}
+	if len(override.GetAllowedHeaders()) > 0 {
+		base.AllowedHeaders = override.GetAllowedHeaders()
+	}
+	if len(override.GetExposedHeaders()) > 0 {
+		base.ExposedHeaders = override.GetExposedHeaders()
+	}
+	if override.AllowCredentials != nil {
+		base.AllowCredentials = override.GetAllowCredentials()
+	}
+	if override.MaxAge != nil {
+		base.MaxAge = int(override.GetMaxAge())
+	}
+	return base
+}
+
+// templateRegexp the path template of google.api.http, e.g. /v1/{name=shelves/*}/books/{book}:publish
+func templateRegexp(template string) *regexp.Regexp {
+	segments := func(pattern string) string {
+		parts := strings.Split(pattern, "/")
+		for i, part := range parts {
+			switch part {
+			case "*":
+				parts[i] = "[^/]+"
+			case "**":
+				parts[i] = ".+"
+			default:
+				parts[i] = regexp.QuoteMeta(part)
+			}
+		}
+		return strings.Join(parts, "/")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for template != "" {
+		if template[0] == '{' {
+			end := strings.IndexByte(template, '}')
+			if end == -1 {
+				panic(fmt.Sprintf("path template %s illegal", template))
+			}
+
+			pattern := "*"
+			if i := strings.IndexByte(template[:end], '='); i != -1 {
+				pattern = template[i+1 : end]
+			}
+
+			expr.WriteString(segments(pattern))
+			template = template[end+1:]
+			continue
+		}
+
+		next := strings.IndexByte(template, '{')
+		if next == -1 {
+			next = len(template)
+		}
+
+		expr.WriteString(segments(template[:next]))
+		template = template[next:]
+	}
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
@@ -0,0 +1,121 @@
+package interceptor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/pkg/journal"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTemplateRegexp(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(templateRegexp("/v1/echo").MatchString("/v1/echo"))
+	assert.False(templateRegexp("/v1/echo").MatchString("/v1/echo/1"))
+	assert.True(templateRegexp("/v1/echo/{message}").MatchString("/v1/echo/hi"))
+	assert.False(templateRegexp("/v1/echo/{message}").MatchString("/v1/echo/hi/there"))
+	assert.True(templateRegexp("/v1/{name=shelves/*/books/*}:publish").MatchString("/v1/shelves/1/books/2:publish"))
+	assert.True(templateRegexp("/v1/files/{path=**}").MatchString("/v1/files/a/b/c.txt"))
+}
+
+func TestCORS(t *testing.T) {
+	assert := assert.New(t)
+
+	registerFile(t, dummyFile("dummy/cors.proto", "CorsService", dummyMethod("Upload")))
+	setMethodHandler(t, "/dummy.CorsService/Upload", &options.MethodHandler{
+		Cors: &options.Cors{
+			AllowedOrigins:   []string{"https://upload.example.com"},
+			AllowCredentials: proto.Bool(false),
+		},
+	}, &annotations.HttpRule{Pattern: &annotations.HttpRule_Put{Put: "/v1/upload/{name}"}})
+
+	core, logs := observer.New(zap.WarnLevel)
+	ring := journal.NewRingBuffer(10)
+	journals := NewJournalWriter(zap.NewNop(), nil, []proposal.JournalSink{ring})
+	handler := CORS(zap.New(core), journals, &proposal.CORSPolicy{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders:   []string{"Authorization"},
+		ExposedHeaders:   []string{"Journal-Id"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	preflight := func(origin, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set(accessControlRequestMethod, method)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := preflight("https://example.com", http.MethodGet, "/v1/echo")
+	assert.Equal("https://example.com", recorder.Header().Get(accessControlAllowOrigin))
+	assert.Equal("true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal("60", recorder.Header().Get("Access-Control-Max-Age"))
+
+	recorder = preflight("https://api.example.org", http.MethodGet, "/v1/echo")
+	assert.Equal("https://api.example.org", recorder.Header().Get(accessControlAllowOrigin))
+
+	recorder = preflight("https://evil.com", http.MethodGet, "/v1/echo")
+	assert.Empty(recorder.Header().Get(accessControlAllowOrigin))
+
+	recorder = preflight("https://example.com", http.MethodDelete, "/v1/echo")
+	assert.Empty(recorder.Header().Get(accessControlAllowOrigin))
+
+	// overridden by options.cors of method
+	recorder = preflight("https://upload.example.com", http.MethodPut, "/v1/upload/a.txt")
+	assert.Equal("https://upload.example.com", recorder.Header().Get(accessControlAllowOrigin))
+	assert.Empty(recorder.Header().Get("Access-Control-Allow-Credentials"))
+
+	recorder = preflight("https://example.com", http.MethodPut, "/v1/upload/a.txt")
+	assert.Empty(recorder.Header().Get(accessControlAllowOrigin))
+
+	rejected := logs.FilterMessage("gateway cors preflight rejected").All()
+	if assert.Len(rejected, 3) {
+		assert.Contains(rejected[2].ContextMap()["journal"], "/dummy.CorsService/Upload")
+	}
+
+	journals.Close() // flushed into sinks
+	if sunk := ring.Journals(); assert.Len(sunk, 3) {
+		assert.Equal("gateway cors interceptor", sunk[2].Interceptor)
+		assert.Equal("/dummy.CorsService/Upload", sunk[2].Method)
+		assert.False(sunk[2].Success)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/echo", nil)
+	req.Header.Set("Origin", "https://example.com")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal("Journal-Id", recorder.Header().Get("Access-Control-Expose-Headers"))
+
+	callback := CORS(zap.NewNop(), nil, &proposal.CORSPolicy{
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".internal")
+		},
+	})(http.NotFoundHandler())
+
+	req = httptest.NewRequest(http.MethodOptions, "/v1/echo", nil)
+	req.Header.Set("Origin", "http://admin.internal")
+	req.Header.Set(accessControlRequestMethod, http.MethodGet)
+	recorder = httptest.NewRecorder()
+	callback.ServeHTTP(recorder, req)
+	assert.Equal("http://admin.internal", recorder.Header().Get(accessControlAllowOrigin))
+}
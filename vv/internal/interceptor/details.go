@@ -0,0 +1,147 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// bzStatus the status of bzErr with the stack and the google.rpc details carried
+func bzStatus(bzErr proposal.BzError) *status.Status {
+	s := status.New(codes.Code(bzErr.BzCode()), bzErr.Desc()).Proto()
+
+	stack, _ := anypb.New(&pb.Stack{Verbose: fmt.Sprintf("%+v", bzErr.StackErr())})
+	s.Details = append(s.Details, stack)
+
+	if detailed, ok := bzErr.(proposal.DetailedError); ok {
+		for _, detail := range detailed.Details() {
+			if any, err := anypb.New(detail); err == nil {
+				s.Details = append(s.Details, any)
+			}
+		}
+	}
+
+	return status.FromProto(s)
+}
+
+// codeStatus the status of BzError with the http code attached, as responded by server
+func codeStatus(bzErr proposal.BzError) *status.Status {
+	s, _ := bzStatus(bzErr).WithDetails(&pb.Code{HttpStatus: uint32(bzErr.HTTPCode())})
+	return s
+}
+
+// publicStatus drop the details for internal use (the stack and the http code), others kept for the caller
+func publicStatus(s *status.Status) *status.Status {
+	public := s.Proto()
+
+	details := public.Details[:0]
+	for _, detail := range public.Details {
+		if detail.MessageIs((*pb.Stack)(nil)) || detail.MessageIs((*pb.Code)(nil)) {
+			continue
+		}
+		details = append(details, detail)
+	}
+	public.Details = details
+
+	return status.FromProto(public)
+}
+
+// remoteError reconstruct the BzError if responded by a vv server, otherwise the public status
+func remoteError(s *status.Status) error {
+	for _, detail := range s.Details() {
+		if code, ok := detail.(*pb.Code); ok {
+			return cuzerr.FromStatus(publicStatus(s), int(code.HttpStatus))
+		}
+	}
+
+	return publicStatus(s).Err()
+}
+
+// ErrorEnvelope the json object responded by gateway if the error carries google.rpc details
+type ErrorEnvelope struct {
+	Code         int32           `json:"code"`
+	Message      string          `json:"message"`
+	ErrorInfo    json.RawMessage `json:"error_info,omitempty"`
+	RetryInfo    json.RawMessage `json:"retry_info,omitempty"`
+	QuotaFailure json.RawMessage `json:"quota_failure,omitempty"`
+	ResourceInfo json.RawMessage `json:"resource_info,omitempty"`
+}
+
+// errorEnvelope nil if no ErrorInfo, RetryInfo, QuotaFailure or ResourceInfo in s
+func errorEnvelope(s *status.Status) (*ErrorEnvelope, string) {
+	marshal := func(detail proto.Message) json.RawMessage {
+		raw, _ := protojson.MarshalOptions{UseProtoNames: true}.Marshal(detail)
+		return raw
+	}
+
+	envelope := &ErrorEnvelope{Code: int32(s.Code()), Message: s.Message()}
+	found := false
+	retryAfter := ""
+
+	for _, detail := range s.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			envelope.ErrorInfo = marshal(detail)
+
+		case *errdetails.RetryInfo:
+			envelope.RetryInfo = marshal(detail)
+			if delay := detail.GetRetryDelay(); delay != nil {
+				retryAfter = strconv.Itoa(int(math.Ceil(delay.AsDuration().Seconds())))
+			}
+
+		case *errdetails.QuotaFailure:
+			envelope.QuotaFailure = marshal(detail)
+
+		case *errdetails.ResourceInfo:
+			envelope.ResourceInfo = marshal(detail)
+
+		default:
+			continue
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, ""
+	}
+	return envelope, retryAfter
+}
+
+func writeErrorEnvelope(ctx context.Context, w http.ResponseWriter, httpStatus int, envelope *ErrorEnvelope, retryAfter string) {
+	forwardServerMetadata(ctx, w)
+
+	if retryAfter != "" {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+func forwardServerMetadata(ctx context.Context, w http.ResponseWriter) {
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			for _, value := range values {
+				w.Header().Add(runtime.MetadataHeaderPrefix+key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var quotaExceeded = cuzerr.NewCode(10429, http.StatusTooManyRequests, "quota exceeded")
+
+func TestErrorDetails(t *testing.T) {
+	assert := assert.New(t)
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}
+
+	_, err := unary(ctx, wrapperspb.String("ping"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, cuzerr.NewBzError(quotaExceeded, errors.New("orders of user 1001 exceeded")).WithDetails(
+			cuzerr.ErrorInfo("QUOTA_EXCEEDED", "order.example.com", map[string]string{"user": "1001"}),
+			cuzerr.RetryInfo(time.Millisecond*1500),
+			cuzerr.QuotaFailure("user:1001", "daily orders limit exceeded"),
+		)
+	})
+
+	s, _ := status.FromError(err)
+	assert.Equal(codes.Code(10429), s.Code())
+	assert.Equal("quota exceeded", s.Message())
+
+	var types []string
+	for _, detail := range s.Details() {
+		switch detail.(type) {
+		case *pb.Stack:
+			types = append(types, "stack")
+		case *pb.Code:
+			types = append(types, "code")
+		case *errdetails.ErrorInfo:
+			types = append(types, "error_info")
+		case *errdetails.RetryInfo:
+			types = append(types, "retry_info")
+		case *errdetails.QuotaFailure:
+			types = append(types, "quota_failure")
+		}
+	}
+	assert.Equal([]string{"error_info", "retry_info", "quota_failure", "code"}, types) // stack dropped
+
+	// reconstructed by client
+	bzErr, ok := remoteError(s).(cuzerr.BzError)
+	if assert.True(ok) {
+		assert.Equal(10429, bzErr.BzCode())
+		assert.Equal(http.StatusTooManyRequests, bzErr.HTTPCode())
+		assert.Equal("quota exceeded", bzErr.Desc())
+		assert.Len(bzErr.Details(), 3)
+
+		retryInfo := new(errdetails.RetryInfo)
+		assert.True(cuzerr.FindDetail(bzErr, retryInfo))
+		assert.Equal(time.Millisecond*1500, retryInfo.RetryDelay.AsDuration())
+		assert.False(cuzerr.FindDetail(bzErr, new(errdetails.ResourceInfo)))
+
+		assert.Equal(codes.Code(10429), status.Code(bzErr))
+	}
+
+	plain := remoteError(status.New(codes.Unavailable, "backend down"))
+	_, ok = plain.(cuzerr.BzError)
+	assert.False(ok)
+	assert.Equal(codes.Unavailable, status.Code(plain))
+
+	// rendered by gateway
+	recorder := httptest.NewRecorder()
+	HTTPErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, recorder, httptest.NewRequest(http.MethodPost, "/", nil),
+		&runtime.HTTPStatusError{HTTPStatus: http.StatusTooManyRequests, Err: publicStatus(s).Err()})
+	assert.Equal(http.StatusTooManyRequests, recorder.Code)
+	assert.Equal("2", recorder.Header().Get("Retry-After"))
+
+	envelope := make(map[string]interface{})
+	assert.NoError(json.Unmarshal(recorder.Body.Bytes(), &envelope))
+	assert.Equal(map[string]interface{}{
+		"code":    float64(10429),
+		"message": "quota exceeded",
+		"error_info": map[string]interface{}{
+			"reason":   "QUOTA_EXCEEDED",
+			"domain":   "order.example.com",
+			"metadata": map[string]interface{}{"user": "1001"},
+		},
+		"retry_info": map[string]interface{}{"retry_delay": "1.500s"},
+		"quota_failure": map[string]interface{}{
+			"violations": []interface{}{map[string]interface{}{"subject": "user:1001", "description": "daily orders limit exceeded"}},
+		},
+	}, envelope)
+}
+
+func TestViolationsKept(t *testing.T) {
+	assert := assert.New(t)
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}
+
+	_, err := unary(ctx, wrapperspb.String("ping"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		s, _ := status.New(codes.InvalidArgument, "nickname required").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "nickname", Description: "nickname required"}},
+		})
+		return nil, s.Err()
+	})
+
+	s, _ := status.FromError(err)
+	assert.Equal(codes.InvalidArgument, s.Code())
+	if assert.Len(s.Details(), 1) {
+		assert.IsType(&errdetails.BadRequest{}, s.Details()[0])
+	}
+}
@@ -30,6 +30,18 @@ func RegisteAuthorizationValidator(name string, handler proposal.UserinfoHandler
 	handlers.Authorization[name] = handler
 }
 
+// RegisteAuthorizer authorizer for interceptor options.roles and options.permissions, named as the authorization validator
+func RegisteAuthorizer(name string, authorizer proposal.Authorizer) {
+	fdLock.Lock()
+	defer fdLock.Unlock()
+
+	if _, ok := handlers.Authorizers[name]; ok {
+		panic(fmt.Sprintf("authorizer: %s has exists", name))
+	}
+
+	handlers.Authorizers[name] = authorizer
+}
+
 // RegisteAuthorizationProxyValidator signature handler for interceptor options.authorization_proxy
 func RegisteAuthorizationProxyValidator(name string, handler proposal.SignatureHandler) {
 	fdLock.Lock()
@@ -74,6 +86,10 @@ func ResloveFileDescriptor(role Role) {
 			if serviceHandler, _ := proto.GetExtension(serivce.Options(), options.E_ServiceHandler).(*options.ServiceHandler); serviceHandler != nil {
 				handlers.Services[string(serivce.FullName())] = serviceHandler
 
+				if serviceHandler.Timeout != nil && *serviceHandler.Timeout != "" {
+					parseTimeout(string(serivce.FullName()), *serviceHandler.Timeout)
+				}
+
 				if role == Gateway {
 					if serviceHandler.Whitelisting != nil && *serviceHandler.Whitelisting != "" {
 						if _, ok := handlers.Whitelisting[*serviceHandler.Whitelisting]; !ok {
@@ -104,6 +120,10 @@ func ResloveFileDescriptor(role Role) {
 				if methodHandler, _ := proto.GetExtension(method.Options(), options.E_MethodHandler).(*options.MethodHandler); methodHandler != nil {
 					handlers.Methods[fullMethod] = methodHandler
 
+					if methodHandler.Timeout != nil && *methodHandler.Timeout != "" {
+						parseTimeout(fullMethod, *methodHandler.Timeout)
+					}
+
 					if role == Gateway {
 						if methodHandler.Whitelisting != nil && *methodHandler.Whitelisting != "" {
 							if _, ok := handlers.Whitelisting[*methodHandler.Whitelisting]; !ok {
@@ -126,6 +146,32 @@ func ResloveFileDescriptor(role Role) {
 					}
 				}
 
+				if role == Gateway {
+					checkCache(fullMethod, handlers.Services[string(serivce.FullName())], handlers.Methods[fullMethod])
+				}
+
+				if role == Server {
+					serviceHandler := handlers.Services[string(serivce.FullName())]
+					methodHandler := handlers.Methods[fullMethod]
+
+					checkRateLimitKey(fullMethod, serviceHandler, methodHandler)
+
+					if len(methodHandler.GetScopes()) > 0 && serviceHandler.GetAuthorization() == "" && methodHandler.GetAuthorization() == "" {
+						panic(fmt.Sprintf("%s scopes requires authorization", fullMethod))
+					}
+
+					if accessControlled(serviceHandler, methodHandler) {
+						authorization := serviceHandler.GetAuthorization()
+						if methodHandler.GetAuthorization() != "" {
+							authorization = methodHandler.GetAuthorization()
+						}
+
+						if _, ok := handlers.Authorizers[authorization]; !ok || authorization == "" {
+							panic(fmt.Sprintf("%s authorizer: %s not found", fullMethod, authorization))
+						}
+					}
+				}
+
 				if httpRule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule); httpRule != nil {
 					handlers.HTTPRules[fullMethod] = httpRule
 				}
@@ -167,6 +213,7 @@ var handlers = &struct {
 	HTTPRules map[string]*annotations.HttpRule   // FullMethod : Rule
 
 	Authorization      map[string]proposal.UserinfoHandler     // Name : Handler
+	Authorizers        map[string]proposal.Authorizer          // Name : Authorizer
 	AuthorizationProxy map[string]proposal.SignatureHandler    // Name : Handler
 	Whitelisting       map[string]proposal.WhitelistingHandler // Name : Handler
 }{
@@ -175,6 +222,7 @@ var handlers = &struct {
 	HTTPRules: make(map[string]*annotations.HttpRule),
 
 	Authorization:      make(map[string]proposal.UserinfoHandler),
+	Authorizers:        make(map[string]proposal.Authorizer),
 	AuthorizationProxy: make(map[string]proposal.SignatureHandler),
 	Whitelisting:       make(map[string]proposal.WhitelistingHandler),
 }
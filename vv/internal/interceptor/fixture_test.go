@@ -0,0 +1,120 @@
+package interceptor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var registered sync.Map // name : *sync.Once
+
+// registerOnce run register once by name in the test binary, for the registries refusing duplicates,
+// e.g. RegisteAuthorizationValidator, so that the tests could be rerun by -count
+func registerOnce(name string, register func()) {
+	once, _ := registered.LoadOrStore(name, new(sync.Once))
+	once.(*sync.Once).Do(register)
+}
+
+// registerFile register the dummy file into GlobalFiles once, the registered one returned on rerun
+func registerFile(t *testing.T, file *descriptorpb.FileDescriptorProto) protoreflect.FileDescriptor {
+	t.Helper()
+
+	registerOnce("file:"+file.GetName(), func() {
+		fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+		if err == nil {
+			err = protoregistry.GlobalFiles.RegisterFile(fd)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	fd, err := protoregistry.GlobalFiles.FindFileByPath(file.GetName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+// dummyMethod a unary method of google.protobuf.Empty
+func dummyMethod(name string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(".google.protobuf.Empty"),
+		OutputType: proto.String(".google.protobuf.Empty"),
+	}
+}
+
+// dummyFile a file of package dummy, with the service of methods
+func dummyFile(name, service string, methods ...*descriptorpb.MethodDescriptorProto) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String(name),
+		Package:    proto.String("dummy"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{emptypb.File_google_protobuf_empty_proto.Path()},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{Name: proto.String(service), Method: methods},
+		},
+	}
+}
+
+// setMethodHandler the options and http rule of fullMethod, nil means none; the previous ones restored on cleanup
+func setMethodHandler(t *testing.T, fullMethod string, handler *options.MethodHandler, rule *annotations.HttpRule) {
+	fdLock.Lock()
+	defer fdLock.Unlock()
+
+	restoreMethod, methodOK := handlers.Methods[fullMethod]
+	restoreRule, ruleOK := handlers.HTTPRules[fullMethod]
+	t.Cleanup(func() {
+		fdLock.Lock()
+		defer fdLock.Unlock()
+
+		delete(handlers.Methods, fullMethod)
+		if methodOK {
+			handlers.Methods[fullMethod] = restoreMethod
+		}
+
+		delete(handlers.HTTPRules, fullMethod)
+		if ruleOK {
+			handlers.HTTPRules[fullMethod] = restoreRule
+		}
+	})
+
+	delete(handlers.Methods, fullMethod)
+	if handler != nil {
+		handlers.Methods[fullMethod] = handler
+	}
+
+	delete(handlers.HTTPRules, fullMethod)
+	if rule != nil {
+		handlers.HTTPRules[fullMethod] = rule
+	}
+}
+
+// setServiceHandler the options of service, the previous one restored on cleanup
+func setServiceHandler(t *testing.T, service string, handler *options.ServiceHandler) {
+	fdLock.Lock()
+	defer fdLock.Unlock()
+
+	restore, ok := handlers.Services[service]
+	t.Cleanup(func() {
+		fdLock.Lock()
+		defer fdLock.Unlock()
+
+		delete(handlers.Services, service)
+		if ok {
+			handlers.Services[service] = restore
+		}
+	})
+
+	handlers.Services[service] = handler
+}
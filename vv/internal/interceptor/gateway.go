@@ -4,17 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/pb"
 	"github.com/bluekaki/pkg/vv/proposal"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/grpc"
@@ -25,17 +23,12 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
-var gatewayInitMetricsOnce sync.Once
-
 // UnaryGatewayInterceptor unary interceptor for gateway
-func UnaryGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics func(http.Handler), projectName string) grpc.UnaryClientInterceptor {
-	if metrics != nil {
-		gatewayInitMetricsOnce.Do(func() {
-			metrics(promhttp.Handler())
-		})
-	}
+func UnaryGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, projectName string, opt GatewayOptions) grpc.UnaryClientInterceptor {
+	links := filterClientLinks(opt.Links, true)
+	outer, inner := splitChain(resolveChain(Gateway, clientPlacements(links)))
 
-	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+	journal := func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
 		ts := time.Now()
 
 		meta, _ := metadata.FromOutgoingContext(ctx)
@@ -74,12 +67,21 @@ func UnaryGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 			}
 		}
 
+		defer metrics.start(method)()
+
+		span := startSpan(opt.Tracer, fullMethod, trace.KindClient, spanContextFromMeta(meta), journalID)
+		injectSpanContext(meta, span)
+
 		defer func() {
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       fullMethod,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -150,76 +152,70 @@ func UnaryGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 						}
 					}
 
-					err = status.New(s.Code(), s.Message()).Err() // reset detail
+					err = publicStatus(s).Err() // reset detail, violations and the google.rpc details kept for HTTPErrorHandler
 					if customStatus != nil {
 						customStatus.Err = err
 						err = customStatus
 					}
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
+				raw := marshalJournal(journal)
 				if err == nil {
-					logger.Info("gateway unary interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Info("gateway unary interceptor", zap.Any("journal", raw))
 
 				} else {
-					logger.Error("gateway unary interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Error("gateway unary interceptor", zap.Any("journal", raw))
 				}
+				opt.Journals.Write("gateway unary interceptor", journal, raw)
 			}
 
-			if metrics != nil {
-				if err == nil {
-					httpRequestSuccessCounter.WithLabelValues(method).Inc()
-					httpRequestSuccessDurationHistogram.WithLabelValues(method).Observe(time.Since(ts).Seconds())
-
-				} else {
-					s, _ := status.FromError(err)
-					code := s.Code().String()
-
-					httpRequestErrorCounter.WithLabelValues(method, code).Inc()
-					httpRequestErrorDurationHistogram.WithLabelValues(method, code).Observe(time.Since(ts).Seconds())
-				}
+			metrics.observeSize(method, "", true, req)
+			if err == nil {
+				metrics.observeSize(method, "", false, reply)
 			}
+			metrics.observe(method, "", ts, err)
+
+			finishSpan(span, err)
 		}()
 
 		serviceName := strings.Split(fullMethod, "/")[1]
 
-		var whitelistingValidator proposal.WhitelistingHandler
-		if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.Whitelisting != nil && *serviceHandler.Whitelisting != "" {
-			whitelistingValidator, _ = getWhitelistingHandler(*serviceHandler.Whitelisting)
-		}
-		if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.Whitelisting != nil && *methodHandler.Whitelisting != "" {
-			whitelistingValidator, _ = getWhitelistingHandler(*methodHandler.Whitelisting)
-		}
-
-		if whitelistingValidator != nil {
-			ok, err := whitelistingValidator(meta.Get(XForwardedFor)[0])
-			if err != nil {
-				errorVerbose := fmt.Sprintf("%+v", err)
-				notify(&proposal.AlertMessage{
-					ProjectName:  projectName,
-					JournalID:    journalID,
-					ErrorVerbose: errorVerbose,
-					Timestamp:    time.Now(),
-				})
-
-				s := status.New(codes.Aborted, codes.Aborted.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: errorVerbose})
-				return s.Err()
+		interceptors := make([]grpc.UnaryClientInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Unary
+				continue
 			}
 
-			if !ok {
-				return status.Error(codes.Aborted, "ip does not allow access")
+			switch node.stage {
+			case proposal.StageWhitelisting:
+				interceptors[i] = func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+					if err := checkWhitelisting(notify, projectName, journalID, serviceName, fullMethod, meta); err != nil {
+						return err
+					}
+
+					return invoker(ctx, fullMethod, req, reply, cc, opts...)
+				}
 			}
 		}
 
-		err = invoker(metadata.NewOutgoingContext(ctx, meta), fullMethod, req, reply, cc, opts...)
+		ctx, cancel := withTimeout(ctx, getTimeout(serviceName, fullMethod))
+		defer cancel()
+
+		err = chainUnaryClient(interceptors, opt.Idempotency.gatewayInvoker(meta, invoker))(metadata.NewOutgoingContext(ctx, meta), fullMethod, req, reply, cc, opts...)
 		if err != nil {
 			s, _ := status.FromError(err)
 			if s.Code() == codes.Unavailable {
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       fullMethod,
+					BzCode:       int(codes.Unavailable),
+					Origin:       cc.Target(), // the unavailable backend
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: s.Proto().String(),
 					Timestamp:    time.Now(),
 				})
@@ -228,17 +224,24 @@ func UnaryGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 
 		return
 	}
+
+	interceptors := make([]grpc.UnaryClientInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Unary)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return chainUnaryClient(interceptors, invoker)(ctx, fullMethod, req, reply, cc, opts...)
+	}
 }
 
 // StreamGatewayInterceptor stream interceptor for gateway
-func StreamGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics func(http.Handler), projectName string) grpc.StreamClientInterceptor {
-	if metrics != nil {
-		gatewayInitMetricsOnce.Do(func() {
-			metrics(promhttp.Handler())
-		})
-	}
+func StreamGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, projectName string, opt GatewayOptions) grpc.StreamClientInterceptor {
+	links := filterClientLinks(opt.Links, false)
+	outer, inner := splitChain(resolveChain(Gateway, clientPlacements(links)))
 
-	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
+	journal := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
 		ts := time.Now()
 
 		meta, _ := metadata.FromOutgoingContext(ctx)
@@ -277,12 +280,26 @@ func StreamGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 			}
 		}
 
+		inFlight := metrics.start(method)
+		defer func() {
+			if err != nil {
+				inFlight() // otherwise the stream in flight until finished
+			}
+		}()
+
+		span := startSpan(opt.Tracer, fullMethod, trace.KindClient, spanContextFromMeta(meta), journalID)
+		injectSpanContext(meta, span)
+
 		defer func() {
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       fullMethod,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -337,86 +354,132 @@ func StreamGatewayInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 						}
 					}
 
-					err = status.New(s.Code(), s.Message()).Err() // reset detail
+					err = publicStatus(s).Err() // reset detail
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
+				raw := marshalJournal(journal)
 				if err == nil {
-					logger.Info("gateway stream interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Info("gateway stream interceptor", zap.Any("journal", raw))
 
 				} else {
-					logger.Error("gateway stream interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Error("gateway stream interceptor", zap.Any("journal", raw))
 				}
+				opt.Journals.Write("gateway stream interceptor", journal, raw)
 			}
 
-			if metrics != nil {
-				if err == nil {
-					httpRequestSuccessCounter.WithLabelValues(method).Inc()
-					httpRequestSuccessDurationHistogram.WithLabelValues(method).Observe(time.Since(ts).Seconds())
+			metrics.observe(method, "", ts, err)
 
-				} else {
-					s, _ := status.FromError(err)
-					code := s.Code().String()
-
-					httpRequestErrorCounter.WithLabelValues(method, code).Inc()
-					httpRequestErrorDurationHistogram.WithLabelValues(method, code).Observe(time.Since(ts).Seconds())
-				}
+			if err != nil {
+				finishSpan(span, err)
 			}
 		}()
 
 		serviceName := strings.Split(fullMethod, "/")[1]
 
-		var whitelistingValidator proposal.WhitelistingHandler
-		if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.Whitelisting != nil && *serviceHandler.Whitelisting != "" {
-			whitelistingValidator, _ = getWhitelistingHandler(*serviceHandler.Whitelisting)
-		}
-		if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.Whitelisting != nil && *methodHandler.Whitelisting != "" {
-			whitelistingValidator, _ = getWhitelistingHandler(*methodHandler.Whitelisting)
-		}
-
-		if whitelistingValidator != nil {
-			ok, err := whitelistingValidator(meta.Get(XForwardedFor)[0])
-			if err != nil {
-				errorVerbose := fmt.Sprintf("%+v", err)
-				notify(&proposal.AlertMessage{
-					ProjectName:  projectName,
-					JournalID:    journalID,
-					ErrorVerbose: errorVerbose,
-					Timestamp:    time.Now(),
-				})
-
-				s := status.New(codes.Aborted, codes.Aborted.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: errorVerbose})
-				return nil, s.Err()
+		interceptors := make([]grpc.StreamClientInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Stream
+				continue
 			}
 
-			if !ok {
-				return nil, status.Error(codes.Aborted, "ip does not allow access")
+			switch node.stage {
+			case proposal.StageWhitelisting:
+				interceptors[i] = func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					if err := checkWhitelisting(notify, projectName, journalID, serviceName, fullMethod, meta); err != nil {
+						return nil, err
+					}
+
+					return streamer(ctx, desc, cc, fullMethod, opts...)
+				}
 			}
 		}
 
-		s, err := streamer(metadata.NewOutgoingContext(ctx, meta), desc, cc, fullMethod, opts...)
+		ctx, cancel := withTimeout(ctx, getTimeout(serviceName, fullMethod))
+
+		s, err := chainStreamClient(interceptors, streamer)(metadata.NewOutgoingContext(ctx, meta), desc, cc, fullMethod, opts...)
 		if err != nil {
+			cancel()
 			return nil, err
 		}
 
 		return &streamGatewayInterceptor{
 			ClientStream: s,
 			logger:       logger,
+			journals:     opt.Journals,
+			cancel:       cancel,
+			span:         span,
 
 			journalID: journalID,
 
 			ignore:    ignore,
 			doJournal: doJournal,
 			method:    fullMethod,
+
+			metrics:       metrics,
+			metricsMethod: method,
+			inFlight:      inFlight,
+			serverStreams: desc.ServerStreams,
 		}, nil
 	}
+
+	interceptors := make([]grpc.StreamClientInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Stream)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return chainStreamClient(interceptors, streamer)(ctx, desc, cc, fullMethod, opts...)
+	}
+}
+
+func checkWhitelisting(notify proposal.NotifyHandler, projectName, journalID, serviceName, fullMethod string, meta metadata.MD) error {
+	var whitelistingValidator proposal.WhitelistingHandler
+	if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.Whitelisting != nil && *serviceHandler.Whitelisting != "" {
+		whitelistingValidator, _ = getWhitelistingHandler(*serviceHandler.Whitelisting)
+	}
+	if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.Whitelisting != nil && *methodHandler.Whitelisting != "" {
+		whitelistingValidator, _ = getWhitelistingHandler(*methodHandler.Whitelisting)
+	}
+
+	if whitelistingValidator == nil {
+		return nil
+	}
+
+	ok, err := whitelistingValidator(meta.Get(XForwardedFor)[0])
+	if err != nil {
+		errorVerbose := fmt.Sprintf("%+v", err)
+		notify(&proposal.AlertMessage{
+			ProjectName:  projectName,
+			JournalID:    journalID,
+			Method:       fullMethod,
+			Origin:       errors.Origin(err),
+			ErrorVerbose: errorVerbose,
+			Timestamp:    time.Now(),
+		})
+
+		s := status.New(codes.Aborted, codes.Aborted.String())
+		s, _ = s.WithDetails(&pb.Stack{Verbose: errorVerbose})
+		return s.Err()
+	}
+
+	if !ok {
+		return status.Error(codes.Aborted, "ip does not allow access")
+	}
+
+	return nil
 }
 
 type streamGatewayInterceptor struct {
 	grpc.ClientStream
-	logger *zap.Logger
+	logger   *zap.Logger
+	journals *JournalWriter
+	cancel   context.CancelFunc
+	span     *trace.Span
 
 	journalID string
 
@@ -428,10 +491,16 @@ type streamGatewayInterceptor struct {
 	ignore    bool
 	doJournal bool
 	method    string
+
+	metrics       *Metrics
+	metricsMethod string
+	inFlight      func()
+	serverStreams bool
 }
 
 func (s *streamGatewayInterceptor) SendMsg(m interface{}) (err error) {
 	s.counter.recv++
+	s.metrics.observeStreamMsg(s.metricsMethod, "", "send", true, m)
 
 	ts := time.Now()
 	defer func() {
@@ -458,7 +527,9 @@ func (s *streamGatewayInterceptor) SendMsg(m interface{}) (err error) {
 				CostSeconds: time.Since(ts).Seconds(),
 			}
 
-			s.logger.Info("gateway stream/recv interceptor", zap.Any("journal", marshalJournal(journal)))
+			raw := marshalJournal(journal)
+			s.logger.Info("gateway stream/recv interceptor", zap.Any("journal", raw))
+			s.journals.Write("gateway stream/recv interceptor", journal, raw)
 		}
 	}()
 
@@ -468,11 +539,28 @@ func (s *streamGatewayInterceptor) SendMsg(m interface{}) (err error) {
 func (s *streamGatewayInterceptor) RecvMsg(m interface{}) (err error) {
 	ts := time.Now()
 	defer func() {
+		if err != nil || !s.serverStreams { // io.EOF or the status, or the only response received
+			s.inFlight()
+		}
+
+		if err != nil {
+			s.cancel() // the stream has finished
+
+			if err == io.EOF {
+				finishSpan(s.span, nil)
+			} else {
+				finishSpan(s.span, err)
+			}
+		}
+
 		if err == io.EOF {
 			return
 		}
 
 		s.counter.send++
+		if err == nil {
+			s.metrics.observeStreamMsg(s.metricsMethod, "", "recv", false, m)
+		}
 
 		if !s.ignore {
 			journal := &pb.Journal{
@@ -508,10 +596,12 @@ func (s *streamGatewayInterceptor) RecvMsg(m interface{}) (err error) {
 					}
 				}
 
-				err = status.New(s.Code(), s.Message()).Err() // reset detail
+				err = publicStatus(s).Err() // reset detail
 			}
 
-			s.logger.Info("gateway stream/send interceptor", zap.Any("journal", marshalJournal(journal)))
+			raw := marshalJournal(journal)
+			s.logger.Info("gateway stream/send interceptor", zap.Any("journal", raw))
+			s.journals.Write("gateway stream/send interceptor", journal, raw)
 		}
 	}()
 
@@ -0,0 +1,108 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bluekaki/pkg/id"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newGatewayServer a grpc server behind the gateway, connected in memory; the unary method served by handler,
+// routed at GET and POST path with the json body of POST decoded into newReq(), and the handler responds wrapperspb.StringValue
+func newGatewayServer(t *testing.T, serverOpt ServerOptions, gatewayOpt GatewayOptions, fullMethod, path string, newReq func() proto.Message, handler grpc.UnaryHandler) *runtime.ServeMux {
+	notify := func(*proposal.AlertMessage) {}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(zap.NewNop(), notify, nil, "", false, serverOpt)))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: strings.Split(fullMethod, "/")[1],
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: strings.Split(fullMethod, "/")[2],
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := newReq()
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+			},
+		}},
+	}, struct{}{})
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryGatewayInterceptor(zap.NewNop(), notify, nil, "", gatewayOpt)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	// as the annotator of gateway builder
+	mux := runtime.NewServeMux(
+		runtime.WithErrorHandler(HTTPErrorHandler),
+		runtime.WithMetadata(func(ctx context.Context, req *http.Request) metadata.MD {
+			return metadata.Pairs(
+				JournalID, id.JournalID(),
+				Authorization, req.Header.Get(Authorization),
+				AuthorizationProxy, req.Header.Get(AuthorizationProxy),
+				Date, req.Header.Get(Date),
+				Method, req.Method,
+				URI, req.RequestURI,
+				Body, "",
+				IdempotencyKey, req.Header.Get(IdempotencyKey),
+				OctetStream, "",
+			)
+		}),
+	)
+
+	forward := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		inbound, outbound := runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, fullMethod)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+
+		req := newReq()
+		if r.Method == http.MethodPost {
+			if err := inbound.NewDecoder(r.Body).Decode(req); err != nil {
+				runtime.HTTPError(ctx, mux, outbound, w, r, err)
+				return
+			}
+		}
+
+		var meta runtime.ServerMetadata
+		reply := new(wrapperspb.StringValue)
+		if err := conn.Invoke(ctx, fullMethod, req, reply, grpc.Header(&meta.HeaderMD), grpc.Trailer(&meta.TrailerMD)); err != nil {
+			runtime.HTTPError(runtime.NewServerMetadataContext(ctx, meta), mux, outbound, w, r, err)
+			return
+		}
+
+		runtime.ForwardResponseMessage(runtime.NewServerMetadataContext(ctx, meta), mux, outbound, w, r, reply)
+	}
+	mux.HandlePath(http.MethodGet, path, forward)
+	mux.HandlePath(http.MethodPost, path, forward)
+
+	return mux
+}
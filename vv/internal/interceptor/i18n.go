@@ -0,0 +1,48 @@
+package interceptor
+
+import (
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/status"
+)
+
+const acceptLanguage = "Accept-Language"
+
+// localizeError replace the desc of a cuzerr code with the one matched by accept-language, the customized desc (by WithDesc) kept
+func localizeError(err error, acceptLanguage string) error {
+	if acceptLanguage == "" {
+		return err
+	}
+
+	httpErr, wrapped := err.(*runtime.HTTPStatusError)
+	if wrapped {
+		err = httpErr.Err
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return restoreHTTPStatusError(httpErr, err)
+	}
+
+	code, ok := cuzerr.Lookup(int(s.Code()))
+	if !ok || code.Desc() != s.Message() {
+		return restoreHTTPStatusError(httpErr, err)
+	}
+
+	desc, _ := cuzerr.LocalizedDesc(code.BzCode(), acceptLanguage)
+	if desc == s.Message() {
+		return restoreHTTPStatusError(httpErr, err)
+	}
+
+	localized := s.Proto()
+	localized.Message = desc
+	return restoreHTTPStatusError(httpErr, status.ErrorProto(localized))
+}
+
+func restoreHTTPStatusError(httpErr *runtime.HTTPStatusError, err error) error {
+	if httpErr == nil {
+		return err
+	}
+	return &runtime.HTTPStatusError{HTTPStatus: httpErr.HTTPStatus, Err: err}
+}
@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var orderNotFound = cuzerr.NewCode(10404, http.StatusNotFound, "order not found")
+
+func init() {
+	cuzerr.RegisteDesc(orderNotFound, "zh-CN", "订单不存在")
+}
+
+func TestLocalizeError(t *testing.T) {
+	assert := assert.New(t)
+
+	render := func(languages string, err error) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/orders/1", nil)
+		if languages != "" {
+			req.Header.Set(acceptLanguage, languages)
+		}
+
+		recorder := httptest.NewRecorder()
+		HTTPErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, recorder, req, err)
+		return recorder
+	}
+
+	bzErr := func(code codes.Code, desc string) error {
+		return &runtime.HTTPStatusError{HTTPStatus: http.StatusNotFound, Err: status.Error(code, desc)}
+	}
+
+	recorder := render("zh-CN,zh;q=0.9", bzErr(codes.Code(orderNotFound.BzCode()), orderNotFound.Desc()))
+	assert.Equal(http.StatusNotFound, recorder.Code)
+	assert.Contains(recorder.Body.String(), `"message":"订单不存在"`)
+	assert.Contains(recorder.Body.String(), `"code":10404`)
+
+	recorder = render("en", bzErr(codes.Code(orderNotFound.BzCode()), orderNotFound.Desc()))
+	assert.Contains(recorder.Body.String(), `"message":"order not found"`)
+
+	recorder = render("", bzErr(codes.Code(orderNotFound.BzCode()), orderNotFound.Desc()))
+	assert.Contains(recorder.Body.String(), `"message":"order not found"`)
+
+	// customized by WithDesc
+	recorder = render("zh-CN", bzErr(codes.Code(orderNotFound.BzCode()), "order 1 not found"))
+	assert.Contains(recorder.Body.String(), `"message":"order 1 not found"`)
+
+	recorder = render("zh-CN", status.Error(codes.Code(orderNotFound.BzCode()), orderNotFound.Desc()))
+	assert.Contains(recorder.Body.String(), `"message":"订单不存在"`)
+}
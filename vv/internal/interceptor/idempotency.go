@@ -0,0 +1,243 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// IdempotentReplayed the header responded if replayed by a stored response
+	IdempotentReplayed = "idempotent-replayed"
+
+	defaultIdempotencyTTL = time.Hour * 24
+	idempotencyLockTTL    = time.Minute
+	maxIdempotencyKeyLen  = 255
+)
+
+// Idempotency enforce options.idempotent, the first successful response of an Idempotency-Key stored and replayed,
+// failed ones released so that could be retried
+type Idempotency struct {
+	logger *zap.Logger
+	store  proposal.IdempotencyStore
+	ttl    time.Duration
+}
+
+// NewIdempotency create an Idempotency, responses kept for ttl (default 24h) in store
+func NewIdempotency(store proposal.IdempotencyStore, ttl time.Duration, logger *zap.Logger) *Idempotency {
+	if store == nil {
+		panic("idempotency store required")
+	}
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	return &Idempotency{
+		logger: logger,
+		store:  store,
+		ttl:    ttl,
+	}
+}
+
+// Close the store
+func (i *Idempotency) Close() error {
+	if i == nil {
+		return nil
+	}
+	return i.store.Close()
+}
+
+// IdempotentDeclared whether any method declared options.idempotent
+func IdempotentDeclared() bool {
+	fdLock.RLock()
+	defer fdLock.RUnlock()
+
+	for _, methodHandler := range handlers.Methods {
+		if methodHandler.GetIdempotent() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// idempotencyKey the key of store, empty if the method not idempotent or no Idempotency-Key
+func (i *Idempotency) idempotencyKey(role, fullMethod, identity string, meta metadata.MD) (string, error) {
+	if i == nil {
+		return "", nil
+	}
+
+	if methodHandler, ok := getMethodHandler(fullMethod); !ok || !methodHandler.GetIdempotent() {
+		return "", nil
+	}
+
+	key := firstValue(meta, IdempotencyKey)
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return "", status.Errorf(codes.InvalidArgument, "idempotency key longer than %d", maxIdempotencyKeyLen)
+	}
+
+	digest := sha256.Sum256([]byte(identity))
+	return fmt.Sprintf("vv:idempotency:%s:%s:%s:%s", role, fullMethod, hex.EncodeToString(digest[:16]), key), nil
+}
+
+// fingerprint the digest of request, bound to the key so that it can't be reused by another request
+func fingerprint(req interface{}) []byte {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return make([]byte, sha256.Size)
+	}
+
+	raw, _ := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	digest := sha256.Sum256(raw)
+	return digest[:]
+}
+
+// do call once for a key, the stored response returned with replayed true for duplicates of the same fingerprint;
+// the response stored prefixed by the fingerprint
+func (i *Idempotency) do(key string, fingerprint []byte, call func() (proto.Message, error)) (resp proto.Message, replayed bool, err error) {
+	acquired, response, err := i.store.Acquire(key, idempotencyLockTTL)
+	if err != nil {
+		s, _ := status.New(codes.Unavailable, "idempotency store unavailable").WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", errors.Wrap(err, "acquire idempotency key err"))})
+		return nil, false, s.Err()
+	}
+
+	if response != nil {
+		if len(response) < sha256.Size || !bytes.Equal(response[:sha256.Size], fingerprint) {
+			return nil, false, cuzerr.NewBzError(cuzerr.IdempotencyMismatch, errors.Errorf("%s reused by a different request", key))
+		}
+
+		any := new(anypb.Any)
+		if err = proto.Unmarshal(response[sha256.Size:], any); err == nil {
+			resp, err = any.UnmarshalNew()
+		}
+		if err != nil {
+			s, _ := status.New(codes.Internal, "idempotency response illegal").WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", errors.Wrap(err, "unmarshal idempotency response err"))})
+			return nil, false, s.Err()
+		}
+
+		return resp, true, nil
+	}
+
+	if !acquired {
+		return nil, false, cuzerr.NewBzError(cuzerr.IdempotencyConflict, errors.Errorf("%s in flight", key))
+	}
+
+	resp, err = call()
+	if err != nil || resp == nil {
+		if err := i.store.Release(key); err != nil {
+			i.logger.Error("release idempotency key err", zap.String("key", key), zap.Error(err))
+		}
+		return resp, false, err
+	}
+
+	any, err := anypb.New(resp)
+	if err == nil {
+		response, err = proto.Marshal(any)
+	}
+	if err == nil {
+		err = i.store.Complete(key, append(fingerprint, response...), i.ttl)
+	}
+	if err != nil {
+		i.logger.Error("complete idempotency key err", zap.String("key", key), zap.Error(err))
+	}
+
+	return resp, false, nil
+}
+
+// serverHandler replay the response by the key plus the signature identifier or userinfo
+func (i *Idempotency) serverHandler(fullMethod string, meta metadata.MD, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	if i == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ := ctx.Value(SignatureIdentifier{}).(string)
+		if identity == "" {
+			if userinfo := ctx.Value(SessionUserinfo{}); userinfo != nil {
+				raw, _ := json.Marshal(userinfo)
+				identity = string(raw)
+			}
+		}
+
+		key, err := i.idempotencyKey("server", fullMethod, identity, meta)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return handler(ctx, req)
+		}
+
+		resp, replayed, err := i.do(key, fingerprint(req), func() (proto.Message, error) {
+			resp, err := handler(ctx, req)
+			if resp == nil {
+				return nil, err
+			}
+			return resp.(proto.Message), err
+		})
+		if replayed {
+			grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayed, "true"))
+		}
+		return resp, err
+	}
+}
+
+// gatewayInvoker replay the response by the key plus the authorization headers, for the callers not authorized on gateway
+func (i *Idempotency) gatewayInvoker(meta metadata.MD, invoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	if i == nil {
+		return invoker
+	}
+
+	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		message, ok := reply.(proto.Message)
+		if !ok {
+			return invoker(ctx, fullMethod, req, reply, cc, opts...)
+		}
+
+		key, err := i.idempotencyKey("gateway", fullMethod, firstValue(meta, Authorization)+"\n"+firstValue(meta, AuthorizationProxy), meta)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return invoker(ctx, fullMethod, req, reply, cc, opts...)
+		}
+
+		resp, replayed, err := i.do(key, fingerprint(req), func() (proto.Message, error) {
+			if err := invoker(ctx, fullMethod, req, reply, cc, opts...); err != nil {
+				return nil, err
+			}
+			return message, nil
+		})
+		if bzErr, ok := err.(proposal.BzError); ok {
+			return codeStatus(bzErr).Err() // the http code attached as responded by server
+		}
+		if err != nil {
+			return err
+		}
+
+		if replayed {
+			proto.Reset(message)
+			proto.Merge(message, resp)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/idempotency"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const createOrder = "/dummy.OrderService/Create"
+
+func init() {
+	fdLock.Lock()
+	handlers.Methods[createOrder] = &options.MethodHandler{Idempotent: proto.Bool(true)}
+	fdLock.Unlock()
+}
+
+func TestIdempotencyServer(t *testing.T) {
+	assert := assert.New(t)
+
+	store := idempotency.NewMemoryStore()
+	defer store.Close()
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{Idempotency: NewIdempotency(store, 0, zap.NewNop())})
+	info := &grpc.UnaryServerInfo{FullMethod: createOrder}
+	call := func(key string, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKey, key))
+		return unary(ctx, wrapperspb.String("order"), info, handler)
+	}
+
+	orders := 0
+	create := func(ctx context.Context, req interface{}) (interface{}, error) {
+		orders++
+		return wrapperspb.String("order-1"), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := call("key-1", create)
+		assert.NoError(err)
+		assert.Equal("order-1", resp.(*wrapperspb.StringValue).Value)
+	}
+	assert.Equal(1, orders) // replayed
+
+	_, err := call("key-2", func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, err := call("key-2", create) // concurrent duplicate
+		s, _ := status.FromError(err)
+		assert.Equal(codes.Code(cuzerr.IdempotencyConflict.BzCode()), s.Code())
+
+		var httpStatus uint32
+		for _, detail := range s.Details() {
+			if code, ok := detail.(*pb.Code); ok {
+				httpStatus = code.HttpStatus
+			}
+		}
+		assert.Equal(uint32(409), httpStatus)
+
+		return nil, status.Error(codes.Unavailable, "db down")
+	})
+	assert.Equal(codes.Unavailable, status.Code(err))
+
+	_, err = call("key-2", create) // released after failed
+	assert.NoError(err)
+	assert.Equal(2, orders)
+
+	_, err = call("", create) // no key
+	assert.NoError(err)
+	_, err = call("", create)
+	assert.NoError(err)
+	assert.Equal(4, orders)
+}
+
+func TestIdempotencyGateway(t *testing.T) {
+	assert := assert.New(t)
+
+	store := idempotency.NewMemoryStore()
+	defer store.Close()
+
+	idempotent := NewIdempotency(store, 0, zap.NewNop())
+
+	orders := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		orders++
+		reply.(*wrapperspb.StringValue).Value = "order-1"
+		return nil
+	}
+
+	call := func(authorization string) *wrapperspb.StringValue {
+		meta := metadata.Pairs(IdempotencyKey, "key-1", Authorization, authorization)
+		reply := new(wrapperspb.StringValue)
+		assert.NoError(idempotent.gatewayInvoker(meta, invoker)(context.Background(), createOrder, wrapperspb.String("order"), reply, nil))
+		return reply
+	}
+
+	assert.Equal("order-1", call("Bearer alice").Value)
+	assert.Equal("order-1", call("Bearer alice").Value)
+	assert.Equal(1, orders)
+
+	call("Bearer bob") // another caller
+	assert.Equal(2, orders)
+}
+
+func TestIdempotencyGatewayHTTP(t *testing.T) {
+	assert := assert.New(t)
+
+	store := idempotency.NewMemoryStore()
+	defer store.Close()
+
+	var mux http.Handler
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+		req.Header.Set(IdempotencyKey, key)
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	var conflict *httptest.ResponseRecorder
+	mux = newGatewayServer(t, ServerOptions{}, GatewayOptions{Idempotency: NewIdempotency(store, 0, zap.NewNop())}, createOrder, "/v1/orders",
+		func() proto.Message { return new(wrapperspb.StringValue) },
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			if conflict == nil {
+				conflict = post("key-1", `"order"`) // concurrent duplicate
+			}
+			return wrapperspb.String("order-1"), nil
+		})
+
+	recorder := post("key-1", `"order"`)
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal(`"order-1"`, recorder.Body.String())
+
+	if assert.NotNil(conflict) {
+		assert.Equal(http.StatusConflict, conflict.Code)
+		assert.Contains(conflict.Body.String(), `"code":99990409`)
+	}
+
+	recorder = post("key-1", `"order"`) // replayed
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal(`"order-1"`, recorder.Body.String())
+
+	recorder = post("key-1", `"another order"`)
+	assert.Equal(http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(recorder.Body.String(), `"code":99990422`)
+}
@@ -0,0 +1,210 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultJournalBatchSize     = 100
+	defaultJournalFlushInterval = time.Second
+	defaultJournalQueueSize     = 10000
+	defaultJournalMaxWait       = time.Millisecond * 100
+)
+
+// JournalWriter sample journals and write them into sinks asynchronously in batches
+type JournalWriter struct {
+	logger        *zap.Logger
+	sinks         []proposal.JournalSink
+	successRatio  float64
+	batchSize     int
+	flushInterval time.Duration
+	maxWait       time.Duration
+
+	queue   chan *proposal.Journal
+	done    chan struct{}
+	once    sync.Once
+	mux     sync.RWMutex // guard queue against close
+	closed  bool
+	dropped uint64
+}
+
+// NewJournalWriter create a JournalWriter, call Close to flush and release the sinks
+func NewJournalWriter(logger *zap.Logger, config *proposal.JournalConfig, sinks []proposal.JournalSink) *JournalWriter {
+	if logger == nil {
+		panic("logger required")
+	}
+	if len(sinks) == 0 {
+		panic("sinks required")
+	}
+	if config == nil {
+		config = new(proposal.JournalConfig)
+	}
+
+	w := &JournalWriter{
+		logger:        logger,
+		sinks:         sinks,
+		successRatio:  config.SuccessRatio,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		maxWait:       config.MaxWait,
+		done:          make(chan struct{}),
+	}
+
+	if w.successRatio == 0 || w.successRatio > 1 {
+		w.successRatio = 1
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = defaultJournalBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultJournalFlushInterval
+	}
+	if w.maxWait <= 0 {
+		w.maxWait = defaultJournalMaxWait
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultJournalQueueSize
+	}
+	w.queue = make(chan *proposal.Journal, queueSize)
+
+	go w.consumer()
+	return w
+}
+
+// sampled failed ones always, successful ones by the hash of journal id, so the journals of a stream kept or dropped together
+func (w *JournalWriter) sampled(journalID string, success bool) bool {
+	if !success || w.successRatio >= 1 {
+		return true
+	}
+	if w.successRatio < 0 {
+		return false
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(journalID))
+	return float64(hash.Sum32()%10000) < w.successRatio*10000
+}
+
+// Write enqueue the journal marshaled by marshalJournal, blocked at most MaxWait if the queue is full
+func (w *JournalWriter) Write(interceptor string, journal *pb.Journal, raw interface{}) {
+	if w == nil || !w.sampled(journal.GetId(), journal.GetSuccess()) {
+		return
+	}
+
+	entry := &proposal.Journal{
+		ID:          journal.GetId(),
+		Interceptor: interceptor,
+		Method:      journal.GetRequest().GetMethod(),
+		Success:     journal.GetSuccess(),
+	}
+
+	switch raw := raw.(type) {
+	case json.RawMessage:
+		entry.Raw = raw
+	case string:
+		entry.Raw = []byte(raw)
+	case []byte:
+		entry.Raw = raw
+	}
+
+	w.mux.RLock()
+	defer w.mux.RUnlock()
+
+	if w.closed {
+		atomic.AddUint64(&w.dropped, 1)
+		return
+	}
+
+	select {
+	case w.queue <- entry:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(w.maxWait)
+	defer timer.Stop()
+
+	select {
+	case w.queue <- entry:
+	case <-timer.C:
+		if atomic.AddUint64(&w.dropped, 1)%1000 == 1 {
+			w.logger.Warn("journal queue full, dropped", zap.String("journal_id", entry.ID), zap.Uint64("dropped", atomic.LoadUint64(&w.dropped)))
+		}
+	}
+}
+
+// Dropped the number of journals dropped for the queue full or writer closed
+func (w *JournalWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+func (w *JournalWriter) consumer() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*proposal.Journal, 0, w.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		for _, sink := range w.sinks {
+			if err := sink.Write(batch); err != nil {
+				w.logger.Error("write journal sink err", zap.Int("journals", len(batch)), zap.Error(err))
+			}
+		}
+		batch = make([]*proposal.Journal, 0, w.batchSize)
+	}
+
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			if batch = append(batch, entry); len(batch) >= w.batchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close flush the pending journals then close the sinks, journals written after closed are dropped
+func (w *JournalWriter) Close() {
+	if w == nil {
+		return
+	}
+
+	w.once.Do(func() {
+		w.mux.Lock()
+		w.closed = true
+		close(w.queue)
+		w.mux.Unlock()
+
+		<-w.done
+
+		for _, sink := range w.sinks {
+			if err := sink.Close(); err != nil {
+				w.logger.Error("close journal sink err", zap.Error(err))
+			}
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/journal"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type batchSink struct {
+	sync.Mutex
+	batches [][]*proposal.Journal
+	blocked chan struct{}
+	closed  bool
+}
+
+func (b *batchSink) Write(journals []*proposal.Journal) error {
+	if b.blocked != nil {
+		<-b.blocked
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.batches = append(b.batches, append([]*proposal.Journal(nil), journals...))
+	return nil
+}
+
+func (b *batchSink) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.closed = true
+	return nil
+}
+
+func TestJournalWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := new(batchSink)
+	ring := journal.NewRingBuffer(3)
+	writer := NewJournalWriter(zap.NewNop(), &proposal.JournalConfig{BatchSize: 2, FlushInterval: time.Hour}, []proposal.JournalSink{sink, ring})
+
+	for i := 0; i < 5; i++ {
+		writer.Write("server unary interceptor", &pb.Journal{Id: fmt.Sprintf("journal-%d", i), Success: true}, fmt.Sprintf(`{"id":"journal-%d"}`, i))
+	}
+	writer.Close()
+	writer.Close()
+	writer.Write("server unary interceptor", &pb.Journal{Id: "journal-5"}, "")
+
+	assert.True(sink.closed)
+	if assert.Len(sink.batches, 3) {
+		assert.Len(sink.batches[0], 2)
+		assert.Len(sink.batches[2], 1) // flushed on close
+		assert.Equal("journal-4", sink.batches[2][0].ID)
+		assert.Equal(`{"id":"journal-4"}`, string(sink.batches[2][0].Raw))
+	}
+
+	journals := ring.Journals()
+	if assert.Len(journals, 3) {
+		assert.Equal("journal-2", journals[0].ID)
+		assert.Equal("journal-4", journals[2].ID)
+	}
+	assert.Equal(uint64(1), writer.Dropped())
+}
+
+func TestJournalSampling(t *testing.T) {
+	assert := assert.New(t)
+
+	none := &JournalWriter{successRatio: -1}
+	assert.False(none.sampled("journal", true))
+	assert.True(none.sampled("journal", false))
+
+	half := &JournalWriter{successRatio: 0.5}
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		journalID := fmt.Sprintf("journal-%d", i)
+		if half.sampled(journalID, true) {
+			sampled++
+			assert.True(half.sampled(journalID, true), "the same journal id sampled consistently")
+		}
+		assert.True(half.sampled(journalID, false))
+	}
+	assert.InDelta(5000, sampled, 300)
+}
+
+func TestJournalBackPressure(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := &batchSink{blocked: make(chan struct{})}
+	writer := NewJournalWriter(zap.NewNop(), &proposal.JournalConfig{BatchSize: 1, QueueSize: 1, MaxWait: time.Millisecond * 10}, []proposal.JournalSink{sink})
+
+	ts := time.Now()
+	for i := 0; i < 4; i++ { // one blocked in sink, one in queue, the others waited then dropped
+		writer.Write("server unary interceptor", &pb.Journal{Id: fmt.Sprintf("journal-%d", i)}, "{}")
+	}
+	assert.GreaterOrEqual(time.Since(ts), time.Millisecond*10)
+	assert.Equal(uint64(2), writer.Dropped())
+
+	close(sink.blocked)
+	writer.Close()
+	assert.Len(sink.batches, 2)
+}
+
+func TestJournalSink(t *testing.T) {
+	assert := assert.New(t)
+
+	ring := journal.NewRingBuffer(10)
+	writer := NewJournalWriter(zap.NewNop(), &proposal.JournalConfig{SuccessRatio: -1}, []proposal.JournalSink{ring})
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{Journals: writer})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}
+
+	_, err := unary(ctx, wrapperspb.String("ping"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("pong"), nil
+	})
+	assert.Nil(err)
+
+	_, err = unary(ctx, wrapperspb.String("ping"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "backend down")
+	})
+	assert.NotNil(err)
+	writer.Close()
+
+	journals := ring.Journals()
+	if assert.Len(journals, 1) { // successful one not sampled
+		assert.Equal("server unary interceptor", journals[0].Interceptor)
+		assert.Equal("/dummy.DummyService/Echo", journals[0].Method)
+		assert.False(journals[0].Success)
+		assert.NotEmpty(journals[0].ID)
+		assert.Contains(string(journals[0].Raw), "backend down")
+	}
+}
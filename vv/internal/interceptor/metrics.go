@@ -1,7 +1,17 @@
 package interceptor
 
 import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -9,65 +19,320 @@ const (
 	subsystem = "vv"
 )
 
-func init() {
-	prometheus.MustRegister(httpRequestSuccessCounter)
-	prometheus.MustRegister(grpcRequestSuccessCounter)
-	prometheus.MustRegister(httpRequestErrorCounter)
-	prometheus.MustRegister(grpcRequestErrorCounter)
-	prometheus.MustRegister(httpRequestSuccessDurationHistogram)
-	prometheus.MustRegister(grpcRequestSuccessDurationHistogram)
-	prometheus.MustRegister(httpRequestErrorDurationHistogram)
-	prometheus.MustRegister(grpcRequestErrorDurationHistogram)
+var (
+	defaultBuckets     = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+	defaultSizeBuckets = prometheus.ExponentialBuckets(64, 4, 10) // 64B ~ 16MB
+)
+
+// prefixes of metrics name
+var metricsPrefix = map[Role]string{
+	Gateway: "http",
+	Server:  "grpc",
+	Client:  "grpc_client",
+}
+
+// Metrics the prometheus collectors of a gateway, server or client
+type Metrics struct {
+	projectName string
+	project     bool
+	identifier  bool
+	handler     http.Handler
+	registerer  prometheus.Registerer
+	gatherer    prometheus.Gatherer
+	private     bool
+
+	requestSuccessCounter           *prometheus.CounterVec
+	requestErrorCounter             *prometheus.CounterVec
+	requestSuccessDurationHistogram *prometheus.HistogramVec
+	requestErrorDurationHistogram   *prometheus.HistogramVec
+	requestSizeHistogram            *prometheus.HistogramVec
+	responseSizeHistogram           *prometheus.HistogramVec
+	streamMsgCounter                *prometheus.CounterVec
+	inFlightGauge                   *prometheus.GaugeVec
+
+	// client only
+	retryCounter           *prometheus.CounterVec
+	breakerStateGauge      *prometheus.GaugeVec
+	breakerRejectedCounter *prometheus.CounterVec
+
+	// gateway only
+	cacheCounter *prometheus.CounterVec
+}
+
+// NewMetrics create and register the collectors, collectors registered already are reused;
+// if labelled differently from the registered, they are kept in a registry of their own and gathered along with the shared one
+func NewMetrics(role Role, projectName string, config *proposal.MetricsConfig) *Metrics {
+	if config == nil {
+		config = new(proposal.MetricsConfig)
+	}
+
+	m := &Metrics{
+		projectName: projectName,
+		project:     config.ProjectLabel,
+		identifier:  config.IdentifierLabel,
+	}
+
+	m.registerer, m.gatherer = prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+	m.handler = promhttp.Handler()
+	if config.Registry != nil {
+		m.registerer, m.gatherer = config.Registry, config.Registry
+		m.handler = promhttp.HandlerFor(config.Registry, promhttp.HandlerOpts{})
+	}
+
+	ns, sub := config.Namespace, config.Subsystem
+	if ns == "" {
+		ns = namespace
+	}
+	if sub == "" {
+		sub = subsystem
+	}
+
+	buckets := config.Buckets
+	if len(buckets) == 0 {
+		buckets = defaultBuckets
+	}
+
+	sizeBuckets := config.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = defaultSizeBuckets
+	}
+
+	prefix := metricsPrefix[role]
+	labels := m.labelNames("method")
+	codeLabels := m.labelNames("method", "code")
+
+	m.requestSuccessCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_request_success_total",
+	}, labels)).(*prometheus.CounterVec)
+
+	m.requestErrorCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_request_error_total",
+	}, codeLabels)).(*prometheus.CounterVec)
+
+	m.requestSuccessDurationHistogram = m.register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_request_success_duration_seconds",
+		Buckets:   buckets,
+	}, labels)).(*prometheus.HistogramVec)
+
+	m.requestErrorDurationHistogram = m.register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_request_error_duration_seconds",
+		Buckets:   buckets,
+	}, codeLabels)).(*prometheus.HistogramVec)
+
+	m.requestSizeHistogram = m.register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_request_size_bytes",
+		Buckets:   sizeBuckets,
+	}, labels)).(*prometheus.HistogramVec)
+
+	m.responseSizeHistogram = m.register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_response_size_bytes",
+		Buckets:   sizeBuckets,
+	}, labels)).(*prometheus.HistogramVec)
+
+	m.streamMsgCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      prefix + "_stream_msg_total",
+	}, m.labelNames("method", "direction"))).(*prometheus.CounterVec)
+
+	if config.InFlight {
+		m.inFlightGauge = m.register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: sub,
+			Name:      prefix + "_requests_in_flight",
+		}, labels)).(*prometheus.GaugeVec)
+	}
+
+	if role == Client {
+		// policy is retry or hedging
+		m.retryCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: sub,
+			Name:      prefix + "_retries_total",
+		}, m.labelNames("method", "policy"))).(*prometheus.CounterVec)
+
+		// 0 closed, 1 half-open, 2 open
+		m.breakerStateGauge = m.register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: sub,
+			Name:      prefix + "_circuit_breaker_state",
+		}, m.labelNames("scope"))).(*prometheus.GaugeVec)
+
+		m.breakerRejectedCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: sub,
+			Name:      prefix + "_circuit_breaker_rejected_total",
+		}, m.labelNames("scope"))).(*prometheus.CounterVec)
+	}
+
+	if role == Gateway {
+		// result is hit, miss or bypass, the hit ratio is hit / (hit + miss)
+		m.cacheCounter = m.register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: sub,
+			Name:      prefix + "_cache_total",
+		}, m.labelNames("method", "result"))).(*prometheus.CounterVec)
+	}
+
+	return m
+}
+
+// register reuse the existing collector, so that several servers in one binary do not collide
+func (m *Metrics) register(collector prometheus.Collector) prometheus.Collector {
+	err := m.registerer.Register(collector)
+	if err == nil {
+		return collector
+	}
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return are.ExistingCollector
+	}
+	if m.private {
+		panic(err)
+	}
+
+	// the same name registered with other labels, e.g. by an instance without project label
+	private := prometheus.NewRegistry()
+	m.registerer, m.private = private, true
+	m.handler = promhttp.HandlerFor(prometheus.Gatherers{m.gatherer, private}, promhttp.HandlerOpts{})
+
+	return m.register(collector)
 }
 
-var httpRequestSuccessCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "http_request_success_total",
-}, []string{"method"})
-
-var grpcRequestSuccessCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "grpc_request_success_total",
-}, []string{"method"})
-
-var httpRequestErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "http_request_error_total",
-}, []string{"method", "code"})
-
-var grpcRequestErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "grpc_request_error_total",
-}, []string{"method", "code"})
-
-var httpRequestSuccessDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "http_request_success_duration_seconds",
-	Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
-}, []string{"method"})
-
-var grpcRequestSuccessDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "grpc_request_success_duration_seconds",
-	Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
-}, []string{"method"})
-
-var httpRequestErrorDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "http_request_error_duration_seconds",
-	Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
-}, []string{"method", "code"})
-
-var grpcRequestErrorDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Namespace: namespace,
-	Subsystem: subsystem,
-	Name:      "grpc_request_error_duration_seconds",
-	Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
-}, []string{"method", "code"})
+// Handler serve the metrics
+func (m *Metrics) Handler() http.Handler {
+	return m.handler
+}
+
+func (m *Metrics) labelNames(names ...string) []string {
+	if m.project {
+		names = append(names, "project")
+	}
+	if m.identifier {
+		names = append(names, "identifier")
+	}
+	return names
+}
+
+func (m *Metrics) labelValues(identifier string, values ...string) []string {
+	if m.project {
+		values = append(values, m.projectName)
+	}
+	if m.identifier {
+		values = append(values, identifier)
+	}
+	return values
+}
+
+// start count in-flight, call the returned func when finished, only the first call counted
+func (m *Metrics) start(method string) func() {
+	if m == nil || m.inFlightGauge == nil {
+		return func() {}
+	}
+
+	gauge := m.inFlightGauge.WithLabelValues(m.labelValues("", method)...)
+	gauge.Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(gauge.Dec)
+	}
+}
+
+// observe the result of a request
+func (m *Metrics) observe(method, identifier string, ts time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	if err == nil {
+		m.requestSuccessCounter.WithLabelValues(m.labelValues(identifier, method)...).Inc()
+		m.requestSuccessDurationHistogram.WithLabelValues(m.labelValues(identifier, method)...).Observe(time.Since(ts).Seconds())
+
+	} else {
+		if httpErr, ok := err.(*runtime.HTTPStatusError); ok {
+			err = httpErr.Err
+		}
+
+		s, _ := status.FromError(err)
+		code := s.Code().String()
+
+		m.requestErrorCounter.WithLabelValues(m.labelValues(identifier, method, code)...).Inc()
+		m.requestErrorDurationHistogram.WithLabelValues(m.labelValues(identifier, method, code)...).Observe(time.Since(ts).Seconds())
+	}
+}
+
+// observeSize the size of request or response message
+func (m *Metrics) observeSize(method, identifier string, request bool, msg interface{}) {
+	if m == nil {
+		return
+	}
+
+	message, ok := msg.(proto.Message)
+	if !ok || message == nil {
+		return
+	}
+
+	histogram := m.responseSizeHistogram
+	if request {
+		histogram = m.requestSizeHistogram
+	}
+	histogram.WithLabelValues(m.labelValues(identifier, method)...).Observe(float64(proto.Size(message)))
+}
+
+// observeStreamMsg count a message sent or received by stream
+func (m *Metrics) observeStreamMsg(method, identifier, direction string, request bool, msg interface{}) {
+	if m == nil {
+		return
+	}
+
+	m.streamMsgCounter.WithLabelValues(m.labelValues(identifier, method, direction)...).Inc()
+	m.observeSize(method, identifier, request, msg)
+}
+
+// observeRetry count an attempt sent besides the first one
+func (m *Metrics) observeRetry(method, policy string) {
+	if m == nil || m.retryCounter == nil {
+		return
+	}
+
+	m.retryCounter.WithLabelValues(m.labelValues("", method, policy)...).Inc()
+}
+
+// observeBreakerState the current state of the circuit breaker of scope
+func (m *Metrics) observeBreakerState(scope string, state breakerState) {
+	if m == nil || m.breakerStateGauge == nil {
+		return
+	}
+
+	m.breakerStateGauge.WithLabelValues(m.labelValues("", scope)...).Set(float64(state))
+}
+
+// observeBreakerRejected count a call rejected by the opened circuit breaker of scope
+func (m *Metrics) observeBreakerRejected(scope string) {
+	if m == nil || m.breakerRejectedCounter == nil {
+		return
+	}
+
+	m.breakerRejectedCounter.WithLabelValues(m.labelValues("", scope)...).Inc()
+}
+
+// observeCache count a lookup of the response cache of method
+func (m *Metrics) observeCache(method, result string) {
+	if m == nil || m.cacheCounter == nil {
+		return
+	}
+
+	m.cacheCounter.WithLabelValues(m.labelValues("", method, result)...).Inc()
+}
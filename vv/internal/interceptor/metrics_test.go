@@ -0,0 +1,146 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := prometheus.NewRegistry()
+	config := &proposal.MetricsConfig{
+		Registry:     registry,
+		Namespace:    "dummy",
+		Buckets:      []float64{1, 5, 30},
+		ProjectLabel: true,
+		InFlight:     true,
+	}
+
+	metrics := NewMetrics(Server, "demo", config)
+	NewMetrics(Server, "demo", config) // reuse the registered collectors
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, metrics, "demo", false, ServerOptions{})
+	_, err := unary(metadata.NewIncomingContext(context.Background(), metadata.MD{}), wrapperspb.String("ping"), &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("pong"), nil
+	})
+	assert.Nil(err)
+
+	families, err := registry.Gather()
+	assert.Nil(err)
+
+	found := make(map[string]map[string]string)
+	for _, family := range families {
+		labels := make(map[string]string)
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		found[family.GetName()] = labels
+	}
+
+	for _, name := range []string{
+		"dummy_vv_grpc_request_success_total",
+		"dummy_vv_grpc_request_success_duration_seconds",
+		"dummy_vv_grpc_request_size_bytes",
+		"dummy_vv_grpc_response_size_bytes",
+		"dummy_vv_grpc_requests_in_flight",
+	} {
+		assert.Contains(found, name)
+		assert.Equal(map[string]string{"method": "/dummy.DummyService/Echo", "project": "demo"}, found[name], name)
+	}
+}
+
+type recvClientStream struct {
+	grpc.ClientStream
+	recv []error
+}
+
+func (r *recvClientStream) RecvMsg(m interface{}) error {
+	err := r.recv[0]
+	r.recv = r.recv[1:]
+	return err
+}
+
+func TestMetricsStreamInFlight(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := NewMetrics(Client, "", &proposal.MetricsConfig{Registry: prometheus.NewRegistry(), InFlight: true})
+	interceptor := StreamClientInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, metrics, nil, "", ClientOptions{})
+
+	open := func(desc *grpc.StreamDesc, recv ...error) grpc.ClientStream {
+		stream, err := interceptor(context.Background(), desc, nil, "/dummy.DummyService/Watch", func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &recvClientStream{recv: recv}, nil
+		})
+		assert.Nil(err)
+		return stream
+	}
+	inFlight := func() float64 {
+		return testutil.ToFloat64(metrics.inFlightGauge.WithLabelValues("/dummy.DummyService/Watch"))
+	}
+
+	watch := open(&grpc.StreamDesc{ServerStreams: true}, nil, io.EOF)
+	assert.Equal(float64(1), inFlight()) // still in flight after opened
+	assert.Nil(watch.RecvMsg(wrapperspb.String("quote")))
+	assert.Equal(float64(1), inFlight())
+	assert.Equal(io.EOF, watch.RecvMsg(wrapperspb.String("quote")))
+	assert.Equal(float64(0), inFlight())
+
+	upload := open(&grpc.StreamDesc{ClientStreams: true}, nil)
+	assert.Equal(float64(1), inFlight())
+	assert.Nil(upload.RecvMsg(wrapperspb.String("done"))) // the only response
+	assert.Equal(float64(0), inFlight())
+
+	failed := open(&grpc.StreamDesc{ServerStreams: true}, status.Error(codes.Unavailable, "gone"), status.Error(codes.Unavailable, "gone"))
+	assert.NotNil(failed.RecvMsg(wrapperspb.String("quote")))
+	assert.NotNil(failed.RecvMsg(wrapperspb.String("quote"))) // decreased once
+	assert.Equal(float64(0), inFlight())
+}
+
+func TestMetricsLabelledDifferently(t *testing.T) {
+	assert := assert.New(t)
+
+	// both on a shared registry, one with project label and one without
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector())
+
+	labelled := NewMetrics(Server, "demo", &proposal.MetricsConfig{Registry: registry, Namespace: "dummy_labelled", ProjectLabel: true})
+	var plain *Metrics
+	assert.NotPanics(func() {
+		plain = NewMetrics(Server, "other", &proposal.MetricsConfig{Registry: registry, Namespace: "dummy_labelled"})
+	})
+
+	labelled.observe("/dummy.DummyService/Echo", "", time.Now(), nil)
+	plain.observe("/dummy.DummyService/Ping", "", time.Now(), nil)
+
+	scrape := func(m *Metrics) string {
+		recorder := httptest.NewRecorder()
+		m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+		return recorder.Body.String()
+	}
+
+	body := scrape(labelled)
+	assert.Contains(body, `dummy_labelled_vv_grpc_request_success_total{method="/dummy.DummyService/Echo",project="demo"} 1`)
+	assert.Contains(body, "go_goroutines")
+
+	body = scrape(plain) // the shared gathered along
+	assert.Contains(body, `dummy_labelled_vv_grpc_request_success_total{method="/dummy.DummyService/Ping"} 1`)
+	assert.Contains(body, `dummy_labelled_vv_grpc_request_success_total{method="/dummy.DummyService/Echo",project="demo"} 1`)
+}
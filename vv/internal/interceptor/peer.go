@@ -0,0 +1,162 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// VerifiedPeerIdentity the identity of the client certificate verified by mtls, nil if no verified one
+func VerifiedPeerIdentity(ctx context.Context) *proposal.PeerIdentity {
+	if ctx == nil {
+		return nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	identity := &proposal.PeerIdentity{
+		DNSNames:            cert.DNSNames,
+		CommonName:          cert.Subject.CommonName,
+		OrganizationalUnits: cert.Subject.OrganizationalUnit,
+		Certificate:         cert,
+	}
+
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+		if uri.Scheme == "spiffe" && identity.SPIFFEID == "" {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+
+	return identity
+}
+
+func getPeerCertificate(serviceName, fullMethod string) *options.PeerCertificate {
+	methodHandler, _ := getMethodHandler(fullMethod)
+	if methodHandler.GetPeerCertificate() != nil {
+		return methodHandler.GetPeerCertificate()
+	}
+
+	serviceHandler, _ := getServiceHandler(serviceName)
+	return serviceHandler.GetPeerCertificate()
+}
+
+// checkPeerCertificate the verified client certificate should match options.peer_certificate
+func checkPeerCertificate(ctx context.Context, serviceName, fullMethod string) error {
+	allowed := getPeerCertificate(serviceName, fullMethod)
+	if allowed == nil {
+		return nil
+	}
+
+	identity := VerifiedPeerIdentity(ctx)
+	if identity == nil {
+		return status.Error(codes.Unauthenticated, "verified client certificate required")
+	}
+
+	if !peerAllowed(allowed, identity) {
+		s := status.New(codes.PermissionDenied, "client certificate not allowed")
+		s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", errors.Errorf("peer %s not allowed by %s", peerName(identity), fullMethod))})
+		return s.Err()
+	}
+
+	return nil
+}
+
+func peerAllowed(allowed *options.PeerCertificate, identity *proposal.PeerIdentity) bool {
+	for _, pattern := range allowed.GetDnsNames() {
+		for _, name := range identity.DNSNames {
+			if dnsMatched(pattern, name) {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range allowed.GetUris() {
+		for _, uri := range identity.URIs {
+			if uriMatched(pattern, uri) {
+				return true
+			}
+		}
+	}
+
+	if identity.SPIFFEID != "" {
+		for _, pattern := range allowed.GetSpiffeIds() {
+			if uriMatched(pattern, identity.SPIFFEID) {
+				return true
+			}
+		}
+	}
+
+	for _, cn := range allowed.GetCommonNames() {
+		if identity.CommonName != "" && cn == identity.CommonName {
+			return true
+		}
+	}
+
+	for _, allowedOU := range allowed.GetOrganizationalUnits() {
+		for _, ou := range identity.OrganizationalUnits {
+			if allowedOU == ou {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// dnsMatched exact or wildcard of the leftmost label, case insensitive
+func dnsMatched(pattern, name string) bool {
+	pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	if !strings.HasPrefix(pattern, "*.") {
+		return pattern == name
+	}
+
+	i := strings.IndexByte(name, '.')
+	return i > 0 && name[i:] == pattern[1:]
+}
+
+// uriMatched exact or prefix ended with /*, compared as parsed so that .. segments do not escape the prefix
+func uriMatched(pattern, uri string) bool {
+	if !strings.HasSuffix(pattern, "/*") {
+		return pattern == uri
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil || strings.Contains(parsed.Path, "/../") || strings.HasSuffix(parsed.Path, "/..") {
+		return false
+	}
+
+	return strings.HasPrefix(uri, pattern[:len(pattern)-1])
+}
+
+func peerName(identity *proposal.PeerIdentity) string {
+	switch {
+	case identity.SPIFFEID != "":
+		return identity.SPIFFEID
+	case len(identity.DNSNames) > 0:
+		return identity.DNSNames[0]
+	case len(identity.URIs) > 0:
+		return identity.URIs[0]
+	}
+	return "CN=" + identity.CommonName
+}
@@ -0,0 +1,86 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func peerContext(cert *x509.Certificate) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	if cert == nil {
+		return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	}
+
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+}
+
+func TestPeerCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	spiffe, _ := url.Parse("spiffe://example.org/ns/prod/sa/orders")
+	orders := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "orders", OrganizationalUnit: []string{"payments"}},
+		DNSNames: []string{"orders.prod.svc"},
+		URIs:     []*url.URL{spiffe},
+	}
+
+	identity := VerifiedPeerIdentity(peerContext(orders))
+	if assert.NotNil(identity) {
+		assert.Equal("spiffe://example.org/ns/prod/sa/orders", identity.SPIFFEID)
+		assert.Equal("orders", identity.CommonName)
+		assert.Equal([]string{"payments"}, identity.OrganizationalUnits)
+	}
+	assert.Nil(VerifiedPeerIdentity(peerContext(nil)))
+	assert.Nil(VerifiedPeerIdentity(context.Background()))
+
+	fdLock.Lock()
+	handlers.Services["dummy.LedgerService"] = &options.ServiceHandler{
+		PeerCertificate: &options.PeerCertificate{SpiffeIds: []string{"spiffe://example.org/ns/prod/*"}},
+	}
+	handlers.Methods["/dummy.LedgerService/Close"] = &options.MethodHandler{
+		PeerCertificate: &options.PeerCertificate{DnsNames: []string{"*.ops.svc"}, OrganizationalUnits: []string{"finance"}},
+	}
+	fdLock.Unlock()
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{})
+	call := func(ctx context.Context, method string) error {
+		_, err := unary(ctx, wrapperspb.String("ledger"), &grpc.UnaryServerInfo{FullMethod: "/dummy.LedgerService/" + method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+		return err
+	}
+
+	assert.NoError(call(peerContext(orders), "Post"))
+	assert.Equal(codes.Unauthenticated, status.Code(call(peerContext(nil), "Post")))
+	assert.Equal(codes.PermissionDenied, status.Code(call(peerContext(orders), "Close"))) // overridden by method
+
+	cron := &x509.Certificate{DNSNames: []string{"cron.ops.svc"}}
+	assert.NoError(call(peerContext(cron), "Close"))
+	assert.Equal(codes.PermissionDenied, status.Code(call(peerContext(cron), "Post")))
+
+	assert.True(dnsMatched("*.ops.svc", "Cron.OPS.svc"))
+	assert.False(dnsMatched("*.ops.svc", "a.cron.ops.svc"))
+	assert.False(dnsMatched("*.ops.svc", ".ops.svc"))
+	assert.True(uriMatched("spiffe://example.org/ns/prod/*", "spiffe://example.org/ns/prod/sa/orders"))
+	assert.False(uriMatched("spiffe://example.org/ns/prod/*", "spiffe://example.org/ns/prod/../admin"))
+	assert.False(uriMatched("spiffe://example.org/ns/prod/*", "spiffe://example.org/ns/production"))
+}
@@ -0,0 +1,333 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/rate"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	rateLimitIdleTTL       = time.Minute * 5
+	rateLimitSweepInterval = time.Minute
+	maxRateLimitKeys       = 10000 // beyond it, the new callers share the overflow bucket of scope, as each limiter runs goroutines
+	rateLimitOverflow      = "overflow"
+)
+
+// RateLimiter enforce options.rate_limit by the redis-distributed rate.Limiter
+type RateLimiter struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *zap.Logger
+	redis  rate.Redis
+	// trustedProxies whose x-forwarded-for believed, e.g. the gateway
+	trustedProxies []*net.IPNet
+
+	mux      sync.Mutex
+	maxKeys  int
+	limiters map[string]*keyedLimiter // Scope:Key : Limiter
+}
+
+type keyedLimiter struct {
+	rate.Limiter
+	lastSeen int64
+}
+
+// sharedRedis keep the redis alive when a keyed limiter closed
+type sharedRedis struct {
+	rate.Redis
+}
+
+func (sharedRedis) Close() error {
+	return nil
+}
+
+// NewRateLimiter create a RateLimiter, call Close to release; the x-forwarded-for only believed if the peer is one of trustedProxies
+func NewRateLimiter(redis rate.Redis, trustedProxies []*net.IPNet, logger *zap.Logger) *RateLimiter {
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter := &RateLimiter{
+		ctx:            ctx,
+		cancel:         cancel,
+		logger:         logger,
+		redis:          redis,
+		trustedProxies: trustedProxies,
+		maxKeys:        maxRateLimitKeys,
+		limiters:       make(map[string]*keyedLimiter),
+	}
+
+	go limiter.sweep()
+	return limiter
+}
+
+// Close release all limiters, the redis also be closed
+func (r *RateLimiter) Close() error {
+	r.cancel()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for key, limiter := range r.limiters {
+		limiter.Close()
+		delete(r.limiters, key)
+	}
+
+	return r.redis.Close()
+}
+
+// sweep close limiters which idle too long, ip or userinfo may create lots of them
+func (r *RateLimiter) sweep() {
+	ticker := time.NewTicker(rateLimitSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+
+		case <-ticker.C:
+			deadline := time.Now().Add(-rateLimitIdleTTL).UnixNano()
+
+			r.mux.Lock()
+			for key, limiter := range r.limiters {
+				if atomic.LoadInt64(&limiter.lastSeen) < deadline {
+					limiter.Close()
+					delete(r.limiters, key)
+				}
+			}
+			r.mux.Unlock()
+		}
+	}
+}
+
+func (r *RateLimiter) allow(scope, key string, conf *options.RateLimit) (bool, error) {
+	identifier := fmt.Sprintf("vv:%s:%s", scope, key)
+
+	r.mux.Lock()
+	limiter, ok := r.limiters[identifier]
+	if !ok && len(r.limiters) >= r.maxKeys {
+		identifier = fmt.Sprintf("vv:%s:%s", scope, rateLimitOverflow)
+		if limiter, ok = r.limiters[identifier]; !ok {
+			r.logger.Warn(fmt.Sprintf("rate limit keys exceeded %d, new callers of %s share one bucket", r.maxKeys, scope))
+		}
+	}
+	if !ok {
+		rps := conf.GetRps()
+
+		config := &rate.Config{
+			Identifier:       identifier,
+			DisableSlowStart: true,
+		}
+		config.Limit.Upper = rps
+		config.Limit.Lower = uint16(math.Min(float64(rps), math.MaxUint16)) // local rate when lose redis
+		config.Limit.Burst = conf.GetBurst()
+		config.Log.Logger = r.logger
+
+		instance, err := rate.NewLimiter(config, sharedRedis{r.redis})
+		if err != nil {
+			r.mux.Unlock()
+			return false, errors.Wrapf(err, "create rate limiter of %s err", identifier)
+		}
+
+		limiter = &keyedLimiter{Limiter: instance}
+		r.limiters[identifier] = limiter
+	}
+	r.mux.Unlock()
+
+	atomic.StoreInt64(&limiter.lastSeen, time.Now().UnixNano())
+	return limiter.TryAllow(), nil
+}
+
+// getRateLimit the method's rate_limit takes precedence over the service's one
+func getRateLimit(serviceName, fullMethod string) (scope string, conf *options.RateLimit) {
+	if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.RateLimit.GetRps() > 0 {
+		scope, conf = serviceName, serviceHandler.RateLimit
+	}
+
+	if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.RateLimit.GetRps() > 0 {
+		scope, conf = fullMethod, methodHandler.RateLimit
+	}
+
+	return
+}
+
+// checkRateLimitKey panic if the key of rate_limit can't be distinguished, e.g. by identifier without authorization_proxy
+func checkRateLimitKey(fullMethod string, serviceHandler *options.ServiceHandler, methodHandler *options.MethodHandler) {
+	conf := serviceHandler.GetRateLimit()
+	if methodHandler.GetRateLimit().GetRps() > 0 {
+		conf = methodHandler.GetRateLimit()
+	}
+	if conf.GetRps() == 0 {
+		return
+	}
+
+	switch conf.GetKey() {
+	case options.RateLimit_IDENTIFIER:
+		if serviceHandler.GetAuthorizationProxy() == "" && methodHandler.GetAuthorizationProxy() == "" {
+			panic(fmt.Sprintf("%s rate_limit by identifier requires authorization_proxy", fullMethod))
+		}
+
+	case options.RateLimit_USERINFO:
+		if serviceHandler.GetAuthorization() == "" && methodHandler.GetAuthorization() == "" {
+			panic(fmt.Sprintf("%s rate_limit by userinfo requires authorization", fullMethod))
+		}
+		if conf.GetUserinfoField() == "" {
+			panic(fmt.Sprintf("%s rate_limit by userinfo requires userinfo_field", fullMethod))
+		}
+	}
+}
+
+// RateLimitDeclared whether any service or method declared options.rate_limit
+func RateLimitDeclared() bool {
+	fdLock.RLock()
+	defer fdLock.RUnlock()
+
+	for _, serviceHandler := range handlers.Services {
+		if serviceHandler.RateLimit.GetRps() > 0 {
+			return true
+		}
+	}
+
+	for _, methodHandler := range handlers.Methods {
+		if methodHandler.RateLimit.GetRps() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkRateLimit(ctx context.Context, limiter *RateLimiter, meta metadata.MD, serviceName, fullMethod string) error {
+	scope, conf := getRateLimit(serviceName, fullMethod)
+	if conf == nil {
+		return nil
+	}
+
+	if limiter == nil { // checked by RateLimitDeclared on startup
+		return nil
+	}
+
+	key := limiter.key(ctx, meta, conf)
+	ok, err := limiter.allow(scope, key, conf)
+	if err != nil {
+		s, _ := status.New(codes.Unavailable, "rate limiter unavailable").WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", errors.Wrap(err, "rate limit err"))})
+		return s.Err()
+	}
+	if !ok {
+		return cuzerr.NewBzError(cuzerr.TooManyRequests, errors.Errorf("%s exceeded rate limit of %d/s by [%s]", fullMethod, conf.GetRps(), key))
+	}
+
+	return nil
+}
+
+// key distinguish callers by conf.key, prefixed by its kind; the client ip used if the identifier or userinfo absent
+func (r *RateLimiter) key(ctx context.Context, meta metadata.MD, conf *options.RateLimit) string {
+	switch conf.GetKey() {
+	case options.RateLimit_GLOBAL:
+		return "global"
+
+	case options.RateLimit_IDENTIFIER:
+		if identifier, _ := ctx.Value(SignatureIdentifier{}).(string); identifier != "" {
+			return "identifier:" + identifier
+		}
+
+	case options.RateLimit_USERINFO:
+		if field := userinfoField(ctx.Value(SessionUserinfo{}), conf.GetUserinfoField()); field != "" {
+			return "userinfo:" + field
+		}
+	}
+
+	return "ip:" + r.clientIP(ctx, meta)
+}
+
+// clientIP the peer address; if the peer is a trusted proxy, the rightmost untrusted address of x-forwarded-for.
+// The first value of x-forwarded-for used, as grpc-gateway appends the remote address of request to it.
+func (r *RateLimiter) clientIP(ctx context.Context, meta metadata.MD) string {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	if !r.trusted(ip) {
+		return ip
+	}
+
+	hops := strings.Split(firstValue(meta, XForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		ip = hop
+		if !r.trusted(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (r *RateLimiter) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range r.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// userinfoField get value by struct field name or map key
+func userinfoField(userinfo interface{}, field string) string {
+	if userinfo == nil || field == "" {
+		return ""
+	}
+
+	value := reflect.ValueOf(userinfo)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if f := value.FieldByName(field); f.IsValid() && f.CanInterface() {
+			return fmt.Sprint(f.Interface())
+		}
+
+	case reflect.Map:
+		if value.Type().Key().Kind() == reflect.String {
+			if f := value.MapIndex(reflect.ValueOf(field).Convert(value.Type().Key())); f.IsValid() {
+				return fmt.Sprint(f.Interface())
+			}
+		}
+	}
+
+	return ""
+}
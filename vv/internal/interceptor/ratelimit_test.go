@@ -0,0 +1,100 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeRateRedis struct{}
+
+func (fakeRateRedis) Close() error { return nil }
+
+func (fakeRateRedis) IncrBy(key string, value int64) *redis.IntCmd {
+	return redis.NewIntResult(value, nil)
+}
+
+func (fakeRateRedis) Get(key string) *redis.StringCmd {
+	return redis.NewStringResult("0", nil)
+}
+
+func TestRateLimitKey(t *testing.T) {
+	assert := assert.New(t)
+
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	limiter := NewRateLimiter(fakeRateRedis{}, []*net.IPNet{proxies}, zap.NewNop())
+	defer limiter.Close()
+
+	key := func(peerIP, forwarded string, ctx context.Context, conf *options.RateLimit) string {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(peerIP), Port: 8080}})
+		return limiter.key(ctx, metadata.Pairs(XForwardedFor, forwarded), conf)
+	}
+
+	byIP := &options.RateLimit{Rps: proto.Uint32(10), Key: options.RateLimit_IP.Enum()}
+	assert.Equal("ip:1.2.3.4", key("1.2.3.4", "9.9.9.9", context.Background(), byIP)) // not from a trusted proxy
+	assert.Equal("ip:5.6.7.8", key("10.0.0.1", "9.9.9.9, 5.6.7.8", context.Background(), byIP))
+	assert.Equal("ip:9.9.9.9", key("10.0.0.1", "9.9.9.9, 10.0.0.2", context.Background(), byIP))
+	assert.Equal("ip:10.0.0.2", key("10.0.0.1", "junk, 10.0.0.2", context.Background(), byIP))
+	assert.Equal("ip:10.0.0.1", key("10.0.0.1", "", context.Background(), byIP))
+
+	byIdentifier := &options.RateLimit{Rps: proto.Uint32(10), Key: options.RateLimit_IDENTIFIER.Enum()}
+	assert.Equal("identifier:app-1", key("1.2.3.4", "", context.WithValue(context.Background(), SignatureIdentifier{}, "app-1"), byIdentifier))
+	assert.Equal("ip:1.2.3.4", key("1.2.3.4", "", context.Background(), byIdentifier)) // absent
+
+	byUserinfo := &options.RateLimit{Rps: proto.Uint32(10), Key: options.RateLimit_USERINFO.Enum(), UserinfoField: proto.String("ID")}
+	assert.Equal("userinfo:1001", key("1.2.3.4", "", context.WithValue(context.Background(), SessionUserinfo{}, map[string]int{"ID": 1001}), byUserinfo))
+	assert.Equal("ip:1.2.3.4", key("1.2.3.4", "", context.WithValue(context.Background(), SessionUserinfo{}, map[string]int{}), byUserinfo))
+
+	assert.Equal("global", key("1.2.3.4", "", context.Background(), &options.RateLimit{Rps: proto.Uint32(10)}))
+}
+
+func TestRateLimitOverflow(t *testing.T) {
+	assert := assert.New(t)
+
+	limiter := NewRateLimiter(fakeRateRedis{}, nil, zap.NewNop())
+	defer limiter.Close()
+	limiter.maxKeys = 2
+
+	conf := &options.RateLimit{Rps: proto.Uint32(100), Burst: proto.Uint32(100)}
+	for i := 0; i < 10; i++ {
+		ok, err := limiter.allow("/dummy.DummyService/Echo", fmt.Sprintf("ip:1.2.3.%d", i), conf)
+		assert.NoError(err)
+		assert.True(ok)
+	}
+
+	limiter.mux.Lock()
+	assert.Len(limiter.limiters, 3) // and the overflow shared
+	_, ok := limiter.limiters["vv:/dummy.DummyService/Echo:overflow"]
+	assert.True(ok)
+	limiter.mux.Unlock()
+}
+
+func TestCheckRateLimitKey(t *testing.T) {
+	assert := assert.New(t)
+
+	byIdentifier := &options.RateLimit{Rps: proto.Uint32(10), Key: options.RateLimit_IDENTIFIER.Enum()}
+	assert.Panics(func() {
+		checkRateLimitKey("/dummy.DummyService/Echo", nil, &options.MethodHandler{RateLimit: byIdentifier})
+	})
+	assert.NotPanics(func() {
+		checkRateLimitKey("/dummy.DummyService/Echo", &options.ServiceHandler{AuthorizationProxy: proto.String("dummy")}, &options.MethodHandler{RateLimit: byIdentifier})
+	})
+
+	byUserinfo := &options.RateLimit{Rps: proto.Uint32(10), Key: options.RateLimit_USERINFO.Enum()}
+	assert.Panics(func() {
+		checkRateLimitKey("/dummy.DummyService/Echo", nil, &options.MethodHandler{RateLimit: byUserinfo, Authorization: proto.String("dummy")}) // no userinfo_field
+	})
+	assert.Panics(func() {
+		checkRateLimitKey("/dummy.DummyService/Echo", &options.ServiceHandler{RateLimit: byUserinfo}, nil)
+	})
+}
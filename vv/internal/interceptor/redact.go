@@ -0,0 +1,379 @@
+package interceptor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const masked = "******"
+
+var redaction = struct {
+	sync.RWMutex
+	headers   map[string]bool
+	sensitive sync.Map // protoreflect.FullName : bool, the message contains sensitive field or not
+}{
+	headers: map[string]bool{
+		Authorization:      true,
+		AuthorizationProxy: true,
+	},
+}
+
+// SetRedactedHeaders the metadata masked in journal, Authorization and Authorization-Proxy by default
+func SetRedactedHeaders(headers []string) {
+	redaction.Lock()
+	defer redaction.Unlock()
+
+	redaction.headers = make(map[string]bool, len(headers))
+	for _, header := range headers {
+		if header = strings.ToLower(strings.TrimSpace(header)); header != "" {
+			redaction.headers[header] = true
+		}
+	}
+}
+
+func redactedHeader(key string) bool {
+	redaction.RLock()
+	defer redaction.RUnlock()
+
+	return redaction.headers[key]
+}
+
+// redactJournal redact the metadata and payloads of journal in place
+func redactJournal(journal *pb.Journal) {
+	if request := journal.GetRequest(); request != nil {
+		var message protoreflect.MessageType
+		if payload := request.GetPayload(); payload != nil {
+			message, _ = protoregistry.GlobalTypes.FindMessageByURL(payload.GetTypeUrl())
+		}
+
+		for key, value := range request.Metadata {
+			switch {
+			case redactedHeader(key):
+				request.Metadata[key] = masked
+
+			case key == Body && message != nil:
+				request.Metadata[key] = redactBody(value, message)
+
+			case key == URI && message != nil:
+				request.Metadata[key] = redactURI(value, message.Descriptor())
+			}
+		}
+
+		redactAny(request.GetPayload())
+	}
+
+	if response := journal.GetResponse(); response != nil {
+		redactAny(response.GetPayload())
+	}
+}
+
+// redactAlert mask the values of redacted headers in error verbose, and the parameters if they are json of req
+func redactAlert(alert *proposal.AlertMessage, req interface{}, meta metadata.MD) {
+	for key, values := range meta {
+		if !redactedHeader(key) {
+			continue
+		}
+
+		for _, value := range values {
+			if value != "" {
+				alert.ErrorVerbose = strings.ReplaceAll(alert.ErrorVerbose, value, masked)
+			}
+		}
+	}
+
+	message, ok := req.(proto.Message)
+	if !ok || alert.Meta == nil || alert.Meta.Parameters == "" {
+		return
+	}
+
+	if !containsSensitive(message.ProtoReflect().Descriptor(), make(map[protoreflect.FullName]bool)) {
+		return
+	}
+	if parameters, ok := redactJSON(alert.Meta.Parameters, message.ProtoReflect().Type()); ok {
+		alert.Meta.Parameters = parameters
+	}
+}
+
+func redactAny(any *anypb.Any) {
+	if any != nil {
+		redactMessage(any.ProtoReflect())
+	}
+}
+
+// redactMessage mask, hash or drop the sensitive fields recursively, including the ones inside google.protobuf.Any
+func redactMessage(message protoreflect.Message) {
+	desc := message.Descriptor()
+	if desc.FullName() == anyFullName {
+		redactAnyMessage(message)
+		return
+	}
+
+	if !containsSensitive(desc, make(map[protoreflect.FullName]bool)) {
+		return
+	}
+
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if sensitive := sensitiveOf(field); sensitive != nil {
+			redactField(message, field, value, sensitive.GetMode())
+			return true
+		}
+
+		switch {
+		case field.IsMap():
+			if field.MapValue().Message() != nil {
+				value.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+					redactMessage(value.Message())
+					return true
+				})
+			}
+
+		case field.IsList():
+			if field.Message() != nil {
+				list := value.List()
+				for i := 0; i < list.Len(); i++ {
+					redactMessage(list.Get(i).Message())
+				}
+			}
+
+		case field.Message() != nil:
+			redactMessage(value.Message())
+		}
+		return true
+	})
+}
+
+const anyFullName = protoreflect.FullName("google.protobuf.Any")
+
+func redactAnyMessage(message protoreflect.Message) {
+	fields := message.Descriptor().Fields()
+	typeURL, value := fields.ByName("type_url"), fields.ByName("value")
+
+	messageType, err := protoregistry.GlobalTypes.FindMessageByURL(message.Get(typeURL).String())
+	if err != nil || !containsSensitive(messageType.Descriptor(), make(map[protoreflect.FullName]bool)) {
+		return
+	}
+
+	inner := messageType.New()
+	if err := proto.Unmarshal(message.Get(value).Bytes(), inner.Interface()); err != nil {
+		message.Set(value, protoreflect.ValueOfBytes(nil)) // could not be redacted
+		return
+	}
+
+	redactMessage(inner)
+
+	raw, _ := proto.Marshal(inner.Interface())
+	message.Set(value, protoreflect.ValueOfBytes(raw))
+}
+
+func redactField(message protoreflect.Message, field protoreflect.FieldDescriptor, value protoreflect.Value, mode options.Sensitive_Mode) {
+	if mode == options.Sensitive_DROP {
+		message.Clear(field)
+		return
+	}
+
+	switch {
+	case field.IsMap():
+		entries := value.Map()
+
+		var keys []protoreflect.MapKey
+		entries.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, key)
+			return true
+		})
+
+		for _, key := range keys {
+			redacted, ok := redactValue(field.MapValue().Kind(), entries.Get(key), mode)
+			if !ok {
+				message.Clear(field)
+				return
+			}
+			entries.Set(key, redacted)
+		}
+
+	case field.IsList():
+		list := value.List()
+		for i := 0; i < list.Len(); i++ {
+			redacted, ok := redactValue(field.Kind(), list.Get(i), mode)
+			if !ok {
+				message.Clear(field)
+				return
+			}
+			list.Set(i, redacted)
+		}
+
+	default:
+		redacted, ok := redactValue(field.Kind(), value, mode)
+		if !ok {
+			message.Clear(field)
+			return
+		}
+		message.Set(field, redacted)
+	}
+}
+
+// redactValue only string and bytes could be masked or hashed, false means the value should be cleared
+func redactValue(kind protoreflect.Kind, value protoreflect.Value, mode options.Sensitive_Mode) (protoreflect.Value, bool) {
+	switch kind {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(redactString(value.String(), mode)), true
+
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(redactString(string(value.Bytes()), mode))), true
+	}
+
+	return protoreflect.Value{}, false
+}
+
+func redactString(value string, mode options.Sensitive_Mode) string {
+	if mode == options.Sensitive_HASH {
+		digest := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(digest[:])
+	}
+	return masked
+}
+
+func sensitiveOf(field protoreflect.FieldDescriptor) *options.Sensitive {
+	sensitive, _ := proto.GetExtension(field.Options(), options.E_Sensitive).(*options.Sensitive)
+	return sensitive
+}
+
+// containsSensitive the message or its sub messages have sensitive field, google.protobuf.Any always treated as yes
+func containsSensitive(desc protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) bool {
+	if cached, ok := redaction.sensitive.Load(desc.FullName()); ok {
+		return cached.(bool)
+	}
+	if desc.FullName() == anyFullName {
+		return true
+	}
+	if visiting[desc.FullName()] {
+		return false
+	}
+	visiting[desc.FullName()] = true
+
+	contains := false
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len() && !contains; i++ {
+		field := fields.Get(i)
+		if sensitiveOf(field) != nil {
+			contains = true
+			break
+		}
+
+		if field.IsMap() {
+			field = field.MapValue()
+		}
+		if field.Message() != nil {
+			contains = containsSensitive(field.Message(), visiting)
+		}
+	}
+
+	if contains || len(visiting) == 1 {
+		redaction.sensitive.Store(desc.FullName(), contains) // false of a recursive one is not certain until the root done
+	}
+	delete(visiting, desc.FullName())
+	return contains
+}
+
+// redactBody the json body of rest request, masked entirely if it could not be parsed
+func redactBody(body string, messageType protoreflect.MessageType) string {
+	if body == "" || !containsSensitive(messageType.Descriptor(), make(map[protoreflect.FullName]bool)) {
+		return body
+	}
+
+	redacted, ok := redactJSON(body, messageType)
+	if !ok {
+		return masked
+	}
+	return redacted
+}
+
+// redactJSON unmarshal raw as the message, false if it could not be parsed
+func redactJSON(raw string, messageType protoreflect.MessageType) (string, bool) {
+	message := messageType.New()
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(raw), message.Interface()); err != nil {
+		return "", false
+	}
+
+	redactMessage(message)
+
+	redacted, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message.Interface())
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
+
+// redactURI the query parameters of sensitive fields, e.g. ?password=xxx
+func redactURI(uri string, desc protoreflect.MessageDescriptor) string {
+	index := strings.IndexByte(uri, '?')
+	if index == -1 || !containsSensitive(desc, make(map[protoreflect.FullName]bool)) {
+		return uri
+	}
+
+	query, err := url.ParseQuery(uri[index+1:])
+	if err != nil {
+		return uri[:index+1] + masked
+	}
+
+	changed := false
+	for key, values := range query {
+		field := findQueryField(desc, key)
+		if field == nil {
+			continue
+		}
+
+		sensitive := sensitiveOf(field)
+		if sensitive == nil {
+			continue
+		}
+
+		changed = true
+		if sensitive.GetMode() == options.Sensitive_DROP {
+			query.Del(key)
+			continue
+		}
+
+		for i := range values {
+			values[i] = redactString(values[i], sensitive.GetMode())
+		}
+	}
+
+	if !changed {
+		return uri
+	}
+	return queryUnescape(uri[:index+1] + query.Encode())
+}
+
+// findQueryField by dotted path, proto name or json name, e.g. user.password
+func findQueryField(desc protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var field protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if desc == nil {
+			return nil
+		}
+
+		fields := desc.Fields()
+		if field = fields.ByName(protoreflect.Name(name)); field == nil {
+			if field = fields.ByJSONName(name); field == nil {
+				return nil
+			}
+		}
+		desc = field.Message()
+	}
+	return field
+}
@@ -0,0 +1,187 @@
+package interceptor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func loginType(t *testing.T) protoreflect.MessageType {
+	if messageType, err := protoregistry.GlobalTypes.FindMessageByName("dummy.Login"); err == nil {
+		return messageType
+	}
+
+	sensitive := func(mode options.Sensitive_Mode) *descriptorpb.FieldOptions {
+		fieldOptions := new(descriptorpb.FieldOptions)
+		proto.SetExtension(fieldOptions, options.E_Sensitive, &options.Sensitive{Mode: mode.Enum()})
+		return fieldOptions
+	}
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string, fieldOptions *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     kind.Enum(),
+			Label:    label.Enum(),
+			JsonName: proto.String(name),
+			Options:  fieldOptions,
+		}
+		if typeName != "" {
+			field.TypeName = proto.String(typeName)
+		}
+		return field
+	}
+
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dummy/redact.proto"),
+		Package:    proto.String("dummy"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{options.File_options_proto.Path(), anypb.File_google_protobuf_any_proto.Path()},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Login"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("username", 1, str, optional, "", nil),
+					field("password", 2, str, optional, "", sensitive(options.Sensitive_MASK)),
+					field("id_number", 3, str, optional, "", sensitive(options.Sensitive_HASH)),
+					field("token", 4, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, "", sensitive(options.Sensitive_DROP)),
+					field("age", 5, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, "", sensitive(options.Sensitive_MASK)),
+					field("secrets", 6, str, repeated, "", sensitive(options.Sensitive_MASK)),
+					field("labels", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".dummy.Login.LabelsEntry", sensitive(options.Sensitive_MASK)),
+					field("child", 8, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".dummy.Login", nil),
+					field("extra", 9, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".google.protobuf.Any", nil),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("LabelsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("key", 1, str, optional, "", nil),
+							field("value", 2, str, optional, "", nil),
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messageType := dynamicpb.NewMessageType(file.Messages().ByName("Login"))
+	if err := protoregistry.GlobalTypes.RegisterMessage(messageType); err != nil {
+		t.Fatal(err)
+	}
+	return messageType
+}
+
+func TestRedactMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	messageType := loginType(t)
+	login := func(json string) proto.Message {
+		message := messageType.New().Interface()
+		assert.NoError(protojson.Unmarshal([]byte(json), message))
+		return message
+	}
+
+	inner, err := anypb.New(login(`{"username":"inner","password":"p@ss"}`))
+	assert.NoError(err)
+
+	message := login(`{"username":"bluekaki","password":"p@ss","id_number":"110101","token":"dG9rZW4=","age":18,
+		"secrets":["a","b"],"labels":{"k":"v"},"child":{"password":"p@ss"}}`)
+	message.ProtoReflect().Set(messageType.Descriptor().Fields().ByName("extra"), protoreflect.ValueOfMessage(inner.ProtoReflect()))
+
+	redactMessage(message.ProtoReflect())
+
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	assert.NoError(err)
+
+	var redacted map[string]interface{}
+	assert.NoError(json.Unmarshal(raw, &redacted))
+
+	digest := sha256.Sum256([]byte("110101"))
+	assert.Equal(map[string]interface{}{
+		"username":  "bluekaki",
+		"password":  masked,
+		"id_number": "sha256:" + hex.EncodeToString(digest[:]),
+		"secrets":   []interface{}{masked, masked},
+		"labels":    map[string]interface{}{"k": masked},
+		"child":     map[string]interface{}{"password": masked},
+		"extra":     map[string]interface{}{"@type": "type.googleapis.com/dummy.Login", "username": "inner", "password": masked},
+	}, redacted)
+}
+
+func TestRedactJournal(t *testing.T) {
+	assert := assert.New(t)
+
+	messageType := loginType(t)
+	message := messageType.New().Interface()
+	assert.NoError(protojson.Unmarshal([]byte(`{"username":"bluekaki","password":"p@ss"}`), message))
+
+	payload, err := anypb.New(message)
+	assert.NoError(err)
+
+	journal := &pb.Journal{
+		Request: &pb.Request{
+			Metadata: map[string]string{
+				Authorization: "Bearer token",
+				Date:          "Mon, 02 Jan 2006 15:04:05 GMT",
+				Body:          `{"username":"bluekaki","password":"p@ss"}`,
+				URI:           "/v1/login?username=bluekaki&password=p@ss",
+			},
+			Payload: payload,
+		},
+	}
+	redactJournal(journal)
+
+	metadata := journal.Request.Metadata
+	assert.Equal(masked, metadata[Authorization])
+	assert.Equal("Mon, 02 Jan 2006 15:04:05 GMT", metadata[Date])
+	assert.JSONEq(`{"username":"bluekaki","password":"******"}`, metadata[Body])
+	assert.Equal("/v1/login?password=******&username=bluekaki", metadata[URI])
+	assert.NotContains(fmt.Sprintf("%s", marshalJournal(journal)), "p@ss")
+
+	SetRedactedHeaders([]string{"Date"})
+	defer SetRedactedHeaders([]string{Authorization, AuthorizationProxy})
+
+	journal.Request.Metadata[Authorization] = "Bearer token"
+	redactJournal(journal)
+	assert.Equal("Bearer token", journal.Request.Metadata[Authorization])
+	assert.Equal(masked, journal.Request.Metadata[Date])
+}
+
+func TestRedactAlert(t *testing.T) {
+	assert := assert.New(t)
+
+	messageType := loginType(t)
+	message := messageType.New().Interface()
+
+	alert := &proposal.AlertMessage{
+		ErrorVerbose: "sso failed with Bearer token",
+		Meta:         &proposal.AlertMessageMeta{Parameters: `{"username":"bluekaki","password":"p@ss"}`},
+	}
+	redactAlert(alert, message, metadata.Pairs(Authorization, "Bearer token"))
+
+	assert.Equal("sso failed with ******", alert.ErrorVerbose)
+	assert.JSONEq(`{"username":"bluekaki","password":"******"}`, alert.Meta.Parameters)
+}
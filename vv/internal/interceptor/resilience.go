@@ -0,0 +1,277 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Resilience the retry, hedging policies and circuit breakers of client, keyed by a full method (e.g. /dummy.DummyService/Echo),
+// a service (e.g. dummy.DummyService) or empty for all methods; the most specific one takes effect.
+type Resilience struct {
+	Retries  map[string]*proposal.RetryPolicy
+	Hedgings map[string]*proposal.HedgingPolicy
+	Breakers map[string]*proposal.BreakerConfig
+}
+
+// Declared whether any policy or breaker declared
+func (r *Resilience) Declared() bool {
+	return r != nil && len(r.Retries)+len(r.Hedgings)+len(r.Breakers) > 0
+}
+
+// lookupScope the most specific key declared of fullMethod
+func lookupScope(declared func(key string) bool, fullMethod string) (string, bool) {
+	service := ""
+	if parts := strings.Split(fullMethod, "/"); len(parts) == 3 {
+		service = parts[1]
+	}
+
+	for _, key := range []string{fullMethod, service, ""} {
+		if declared(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// UnaryResilienceInterceptor retry or hedge unary calls, every attempt guarded by the circuit breaker;
+// placed after the client interceptor, so that one journal for all attempts.
+func UnaryResilienceInterceptor(metrics *Metrics, resilience *Resilience) grpc.UnaryClientInterceptor {
+	if resilience == nil {
+		resilience = new(Resilience)
+	}
+
+	for key := range resilience.Hedgings {
+		if _, ok := resilience.Retries[key]; ok {
+			panic(fmt.Sprintf("retry and hedging policy of [%s] are mutually exclusive", key))
+		}
+	}
+
+	breakers := make(map[string]*breaker, len(resilience.Breakers))
+	for scope, config := range resilience.Breakers {
+		breakers[scope] = newBreaker(scopeName(scope), config, metrics, time.Now)
+	}
+
+	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		attempt := func(ctx context.Context, reply interface{}) error {
+			return invoker(ctx, fullMethod, req, reply, cc, opts...)
+		}
+
+		if scope, ok := lookupScope(func(key string) bool { _, ok := breakers[key]; return ok }, fullMethod); ok {
+			breaker := breakers[scope]
+			invoke := attempt
+			attempt = func(ctx context.Context, reply interface{}) error {
+				generation, ok := breaker.allow()
+				if !ok {
+					return circuitOpenError(breaker.scope)
+				}
+
+				err := invoke(ctx, reply)
+				breaker.done(generation, status.Code(err))
+				return err
+			}
+		}
+
+		if scope, ok := lookupScope(func(key string) bool { _, ok := resilience.Hedgings[key]; return ok }, fullMethod); ok {
+			if message, ok := reply.(proto.Message); ok {
+				return hedge(ctx, metrics, fullMethod, resilience.Hedgings[scope], message, attempt)
+			}
+		}
+
+		if scope, ok := lookupScope(func(key string) bool { _, ok := resilience.Retries[key]; return ok }, fullMethod); ok {
+			return retry(ctx, metrics, fullMethod, resilience.Retries[scope], reply, attempt)
+		}
+
+		return attempt(ctx, reply)
+	}
+}
+
+func scopeName(scope string) string {
+	if scope == "" {
+		return "*"
+	}
+	return scope
+}
+
+// circuitOpenError a cuzerr.CircuitOpen responded like a vv server, so that reconstructed as a BzError by client interceptor
+func circuitOpenError(scope string) error {
+	bzErr := cuzerr.NewBzError(cuzerr.CircuitOpen, errors.Errorf("circuit breaker of [%s] open", scope))
+
+	s, _ := bzStatus(bzErr).WithDetails(&pb.Code{HttpStatus: uint32(bzErr.HTTPCode())})
+	return s.Err()
+}
+
+func codeSet(items []codes.Code, defaults ...codes.Code) map[codes.Code]bool {
+	if len(items) == 0 {
+		items = defaults
+	}
+
+	set := make(map[codes.Code]bool, len(items))
+	for _, code := range items {
+		set[code] = true
+	}
+	return set
+}
+
+func retry(ctx context.Context, metrics *Metrics, fullMethod string, policy *proposal.RetryPolicy, reply interface{}, attempt func(context.Context, interface{}) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	initialBackoff := policy.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = time.Millisecond * 10
+	}
+
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Second
+	}
+
+	multiplier := policy.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	jitter := policy.Jitter
+	if jitter <= 0 || jitter >= 1 {
+		jitter = 0.2
+	}
+
+	retryable := codeSet(policy.Codes, codes.Unavailable)
+
+	for n := 1; ; n++ {
+		err := attempt(ctx, reply)
+		if err == nil || n >= maxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		s, _ := status.FromError(err)
+		if !retryable[s.Code()] {
+			return err
+		}
+
+		backoff := time.Duration(math.Min(float64(initialBackoff)*math.Pow(multiplier, float64(n-1)), float64(maxBackoff)))
+		backoff = time.Duration(float64(backoff) * (1 + jitter*(rand.Float64()*2-1)))
+
+		for _, detail := range s.Details() {
+			if retryInfo, ok := detail.(*errdetails.RetryInfo); ok && retryInfo.GetRetryDelay().AsDuration() > backoff {
+				backoff = retryInfo.GetRetryDelay().AsDuration()
+				if backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+			}
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+
+		case <-timer.C:
+		}
+
+		metrics.observeRetry(fullMethod, "retry")
+	}
+}
+
+func hedge(ctx context.Context, metrics *Metrics, fullMethod string, policy *proposal.HedgingPolicy, reply proto.Message, attempt func(context.Context, interface{}) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 2
+	}
+
+	delay := policy.Delay
+	if delay <= 0 {
+		delay = time.Millisecond * 100
+	}
+
+	nonFatal := codeSet(policy.NonFatalCodes, codes.Unavailable)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // the others canceled once one returned
+
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	results := make(chan *result, maxAttempts)
+
+	sent, pending := 0, 0
+	send := func() {
+		if sent > 0 {
+			metrics.observeRetry(fullMethod, "hedging")
+		}
+		sent++
+		pending++
+
+		message := reply.ProtoReflect().New().Interface()
+		go func() {
+			results <- &result{reply: message, err: attempt(ctx, message)}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	resetTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(delay)
+	}
+
+	send()
+	for {
+		select {
+		case <-timer.C:
+			if sent < maxAttempts {
+				send()
+				timer.Reset(delay)
+			}
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				proto.Reset(reply)
+				proto.Merge(reply, result.reply)
+				return nil
+			}
+
+			if !nonFatal[status.Code(result.err)] || ctx.Err() != nil {
+				return result.err
+			}
+
+			if sent < maxAttempts {
+				send()
+				resetTimer()
+
+			} else if pending == 0 {
+				return result.err
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const echoMethod = "/dummy.DummyService/Echo"
+
+type clock struct {
+	sync.Mutex
+	ts time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.ts
+}
+
+func (c *clock) add(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.ts = c.ts.Add(d)
+}
+
+func TestBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(Client, "", &proposal.MetricsConfig{Registry: registry})
+	gauge := metrics.breakerStateGauge.WithLabelValues("dummy.DummyService")
+
+	clock := &clock{ts: time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)}
+	b := newBreaker("dummy.DummyService", &proposal.BreakerConfig{MinRequests: 4, OpenTimeout: time.Second, HalfOpenRequests: 2}, metrics, clock.now)
+
+	call := func(code codes.Code) bool {
+		generation, ok := b.allow()
+		if ok {
+			b.done(generation, code)
+		}
+		return ok
+	}
+
+	assert.True(call(codes.OK))
+	assert.True(call(codes.NotFound)) // not counted as failure
+	assert.True(call(codes.Unavailable))
+	assert.Equal(breakerClosed, b.currentState())
+	assert.True(call(codes.Unavailable))
+	assert.Equal(breakerOpen, b.currentState())
+	assert.Equal(float64(breakerOpen), testutil.ToFloat64(gauge))
+
+	assert.False(call(codes.OK))
+	assert.Equal(float64(1), testutil.ToFloat64(metrics.breakerRejectedCounter.WithLabelValues("dummy.DummyService")))
+
+	clock.add(time.Second)
+	generation, ok := b.allow() // probe
+	assert.True(ok)
+	assert.Equal(breakerHalfOpen, b.currentState())
+	assert.True(call(codes.Unavailable)) // another probe failed
+	assert.Equal(breakerOpen, b.currentState())
+	b.done(generation, codes.OK) // ignored, the generation changed
+	assert.Equal(breakerOpen, b.currentState())
+
+	clock.add(time.Second)
+	assert.True(call(codes.OK))
+	assert.Equal(breakerHalfOpen, b.currentState())
+	assert.True(call(codes.OK))
+	assert.Equal(breakerClosed, b.currentState())
+	assert.Equal(float64(breakerClosed), testutil.ToFloat64(gauge))
+
+	// canceled neither success nor failure
+	for i := 0; i < 4; i++ {
+		assert.True(call(codes.Canceled))
+	}
+	assert.True(call(codes.Unavailable))
+	assert.True(call(codes.Unavailable))
+	assert.True(call(codes.OK))
+	assert.Equal(breakerClosed, b.currentState()) // 3 requests counted only
+	assert.True(call(codes.Unavailable))
+	assert.Equal(breakerOpen, b.currentState())
+
+	clock.add(time.Second)
+	assert.True(call(codes.Canceled)) // probe slot released
+	assert.True(call(codes.OK))
+	assert.True(call(codes.Canceled))
+	assert.Equal(breakerHalfOpen, b.currentState())
+	assert.True(call(codes.OK))
+	assert.Equal(breakerClosed, b.currentState())
+}
+
+func TestRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int32
+	invoker := func(failures int32, code codes.Code) grpc.UnaryInvoker {
+		atomic.StoreInt32(&attempts, 0)
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			if atomic.AddInt32(&attempts, 1) <= failures {
+				return status.Error(code, "backend down")
+			}
+			return nil
+		}
+	}
+
+	unary := UnaryResilienceInterceptor(nil, &Resilience{
+		Retries: map[string]*proposal.RetryPolicy{
+			"dummy.DummyService": {MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		},
+	})
+
+	assert.NoError(unary(context.Background(), echoMethod, nil, nil, nil, invoker(2, codes.Unavailable)))
+	assert.Equal(int32(3), atomic.LoadInt32(&attempts))
+
+	assert.Equal(codes.Unavailable, status.Code(unary(context.Background(), echoMethod, nil, nil, nil, invoker(3, codes.Unavailable))))
+	assert.Equal(int32(3), atomic.LoadInt32(&attempts))
+
+	assert.Equal(codes.NotFound, status.Code(unary(context.Background(), echoMethod, nil, nil, nil, invoker(3, codes.NotFound))))
+	assert.Equal(int32(1), atomic.LoadInt32(&attempts))
+
+	assert.Error(unary(context.Background(), "/dummy.OtherService/Echo", nil, nil, nil, invoker(1, codes.Unavailable)))
+	assert.Equal(int32(1), atomic.LoadInt32(&attempts)) // no policy
+
+	assert.Panics(func() {
+		UnaryResilienceInterceptor(nil, &Resilience{
+			Retries:  map[string]*proposal.RetryPolicy{"": {}},
+			Hedgings: map[string]*proposal.HedgingPolicy{"": {}},
+		})
+	})
+}
+
+func TestHedging(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int32
+	unary := UnaryResilienceInterceptor(nil, &Resilience{
+		Hedgings: map[string]*proposal.HedgingPolicy{
+			echoMethod: {MaxAttempts: 3, Delay: time.Millisecond * 20},
+		},
+	})
+
+	reply := new(wrapperspb.StringValue)
+	err := unary(context.Background(), echoMethod, nil, reply, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&attempts, 1) == 1 { // the first one stuck until canceled
+			<-ctx.Done()
+			return status.Error(codes.Canceled, ctx.Err().Error())
+		}
+
+		reply.(*wrapperspb.StringValue).Value = "pong"
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal("pong", reply.Value)
+	assert.Equal(int32(2), atomic.LoadInt32(&attempts))
+
+	atomic.StoreInt32(&attempts, 0)
+	ts := time.Now()
+	err = unary(context.Background(), echoMethod, nil, reply, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&attempts, 1)
+		return status.Error(codes.Unavailable, "backend down")
+	})
+	assert.Equal(codes.Unavailable, status.Code(err))
+	assert.Equal(int32(3), atomic.LoadInt32(&attempts))
+	assert.Less(time.Since(ts), time.Millisecond*20) // non-fatal ones sent immediately
+}
+
+func TestCircuitOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	unary := UnaryResilienceInterceptor(nil, &Resilience{
+		Retries:  map[string]*proposal.RetryPolicy{"": {InitialBackoff: time.Millisecond}},
+		Breakers: map[string]*proposal.BreakerConfig{"": {MinRequests: 1}},
+	})
+
+	var attempts int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&attempts, 1)
+		return status.Error(codes.Unavailable, "backend down")
+	}
+
+	err := unary(context.Background(), echoMethod, nil, nil, nil, invoker)
+	assert.Equal(int32(1), atomic.LoadInt32(&attempts)) // opened by the first failure, retries rejected
+
+	s, _ := status.FromError(err)
+	bzErr, ok := remoteError(s).(cuzerr.BzError)
+	if assert.True(ok) {
+		assert.Equal(cuzerr.CircuitOpen.BzCode(), bzErr.BzCode())
+		assert.Equal(http.StatusServiceUnavailable, bzErr.HTTPCode())
+	}
+}
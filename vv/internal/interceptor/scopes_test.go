@@ -0,0 +1,60 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type scopedUserinfo []string
+
+func (s scopedUserinfo) HasScope(scope string) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScopes(t *testing.T) {
+	assert := assert.New(t)
+
+	registerOnce("dummy_scoped", func() {
+		RegisteAuthorizationValidator("dummy_scoped", func(authorization string, payload proposal.Payload) (interface{}, error) {
+			if authorization == "plain" {
+				return "alice", nil
+			}
+			return scopedUserinfo{"order:read"}, nil
+		})
+	})
+
+	setMethodHandler(t, "/dummy.OrderService/Get", &options.MethodHandler{Authorization: proto.String("dummy_scoped"), Scopes: []string{"order:read"}}, nil)
+	setMethodHandler(t, "/dummy.OrderService/Delete", &options.MethodHandler{Authorization: proto.String("dummy_scoped"), Scopes: []string{"order:read", "order:delete"}}, nil)
+
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{})
+	call := func(fullMethod, authorization string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Authorization, authorization))
+		_, err := unary(ctx, wrapperspb.String("order"), &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+		return err
+	}
+
+	denied := codes.Code(cuzerr.PermissionDenied.BzCode())
+	assert.NoError(call("/dummy.OrderService/Get", "Bearer alice"))
+	assert.Equal(denied, status.Code(call("/dummy.OrderService/Delete", "Bearer alice")))
+	assert.Equal(denied, status.Code(call("/dummy.OrderService/Get", "plain"))) // scopes unknown
+}
@@ -6,21 +6,20 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/bluekaki/pkg/errors"
 	"github.com/bluekaki/pkg/id"
 	"github.com/bluekaki/pkg/pbutil"
+	"github.com/bluekaki/pkg/trace"
 	"github.com/bluekaki/pkg/vv/internal/pb"
 	"github.com/bluekaki/pkg/vv/internal/pkg/multipart"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
 	"github.com/bluekaki/pkg/vv/proposal"
 
 	protoV1 "github.com/golang/protobuf/proto"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -113,17 +112,12 @@ func (g *grpcPayload) Body() []byte {
 	return g.body
 }
 
-var serverInitMetricsOnce sync.Once
-
 // UnaryServerInterceptor unary interceptor for server
-func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics func(http.Handler), projectName string, disableMessageValitator bool) grpc.UnaryServerInterceptor {
-	if metrics != nil {
-		serverInitMetricsOnce.Do(func() {
-			metrics(promhttp.Handler())
-		})
-	}
+func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, projectName string, disableMessageValitator bool, opt ServerOptions) grpc.UnaryServerInterceptor {
+	links := filterServerLinks(opt.Links, true)
+	outer, inner := splitChain(resolveChain(Server, serverPlacements(links)))
 
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	journal := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		ts := time.Now()
 
 		fullMethod := strings.Split(info.FullMethod, "/")
@@ -157,15 +151,23 @@ func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, m
 			journalID = values[0]
 		}
 
+		var signatureIdentifier string // of options.authorization_proxy, used by metrics
+		defer metrics.start(method)()
+
+		span := startSpan(opt.Tracer, info.FullMethod, trace.KindServer, spanContextFromMeta(meta), journalID)
 		defer func() {
 			grpc.SetHeader(ctx, metadata.Pairs(runtime.MetadataHeaderPrefix+JournalID, journalID))
 			grpc.SetHeader(ctx, metadata.Pairs(JournalID, journalID))
 
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       info.FullMethod,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -180,21 +182,26 @@ func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, m
 				case proposal.BzError:
 					bzErr := err.(proposal.BzError)
 					statusCode = &pb.Code{HttpStatus: uint32(bzErr.HTTPCode())}
-					s, _ := status.New(codes.Code(bzErr.BzCode()), bzErr.Desc()).WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", bzErr.StackErr())})
-					err = s.Err()
+					err = bzStatus(bzErr).Err()
 
 				case proposal.AlertError:
 					alertErr := err.(proposal.AlertError)
 
+					bzErr := alertErr.BzError()
+
 					alert := alertErr.AlertMessage()
 					alert.ProjectName = projectName
 					alert.JournalID = journalID
+					alert.Method = info.FullMethod
+					alert.BzCode = bzErr.BzCode()
+					if alert.Origin == "" {
+						alert.Origin = errors.Origin(bzErr.StackErr())
+					}
+					redactAlert(alert, req, meta)
 					notify(alert)
 
-					bzErr := alertErr.BzError()
 					statusCode = &pb.Code{HttpStatus: uint32(bzErr.HTTPCode())}
-					s, _ := status.New(codes.Code(bzErr.BzCode()), bzErr.Desc()).WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", bzErr.StackErr())})
-					err = s.Err()
+					err = bzStatus(bzErr).Err()
 				}
 			}
 
@@ -256,7 +263,7 @@ func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, m
 						}
 					}
 
-					s = status.New(s.Code(), s.Message()) // reset detail
+					s = publicStatus(s) // reset detail, the google.rpc details kept
 					if statusCode != nil {
 						s, _ = s.WithDetails(statusCode)
 					}
@@ -264,154 +271,151 @@ func UnaryServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, m
 					err = s.Err()
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
+				raw := marshalJournal(journal)
 				if err == nil {
-					logger.Info("server unary interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Info("server unary interceptor", zap.Any("journal", raw))
 
 				} else {
-					logger.Error("server unary interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Error("server unary interceptor", zap.Any("journal", raw))
 				}
+				opt.Journals.Write("server unary interceptor", journal, raw)
 			}
 
-			if metrics != nil {
-				if err == nil {
-					grpcRequestSuccessCounter.WithLabelValues(method).Inc()
-					grpcRequestSuccessDurationHistogram.WithLabelValues(method).Observe(time.Since(ts).Seconds())
-
-				} else {
-					s, _ := status.FromError(err)
-					code := s.Code().String()
-
-					grpcRequestErrorCounter.WithLabelValues(method, code).Inc()
-					grpcRequestErrorDurationHistogram.WithLabelValues(method, code).Observe(time.Since(ts).Seconds())
-				}
+			metrics.observeSize(method, signatureIdentifier, true, req)
+			if err == nil {
+				metrics.observeSize(method, signatureIdentifier, false, resp)
 			}
+			metrics.observe(method, signatureIdentifier, ts, err)
+
+			finishSpan(span, err)
 		}()
 
-		if req != nil {
-			if validator, ok := req.(proposal.Validator); ok && !disableMessageValitator {
-				if err := validator.Validate(); err != nil {
-					return nil, status.Error(codes.InvalidArgument, err.Error())
+		payload := func() proposal.Payload {
+			return newPayload(meta, journalID, serviceName, info.FullMethod, func() []byte {
+				if req == nil {
+					return nil
 				}
-			}
-		}
 
-		var (
-			authorizationValidator      proposal.UserinfoHandler
-			authorizationProxyValidator proposal.SignatureHandler
-		)
+				raw, _ := pbutil.ProtoMessage2JSON(req.(protoV1.Message))
+				return []byte(raw)
+			})
+		}
 
-		if serviceHandler, ok := getServiceHandler(serviceName); ok {
-			if serviceHandler.Authorization != nil && *serviceHandler.Authorization != "" {
-				authorizationValidator, _ = getAuthorizationHandler(*serviceHandler.Authorization)
+		interceptors := make([]grpc.UnaryServerInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Unary
+				continue
 			}
 
-			if serviceHandler.AuthorizationProxy != nil && *serviceHandler.AuthorizationProxy != "" {
-				authorizationProxyValidator, _ = getAuthorizationProxyHandler(*serviceHandler.AuthorizationProxy)
-			}
-		}
+			switch node.stage {
+			case proposal.StageValidation:
+				interceptors[i] = func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					if req != nil {
+						if !disableMessageValitator {
+							if err := validateMessage(req, opt.CollectAllViolations); err != nil {
+								return nil, err
+							}
+						}
+					}
 
-		if methodHandler, ok := getMethodHandler(info.FullMethod); ok {
-			if methodHandler.Authorization != nil && *methodHandler.Authorization != "" {
-				authorizationValidator, _ = getAuthorizationHandler(*methodHandler.Authorization)
-			}
+					return handler(ctx, req)
+				}
 
-			if methodHandler.AuthorizationProxy != nil && *methodHandler.AuthorizationProxy != "" {
-				authorizationProxyValidator, _ = getAuthorizationProxyHandler(*methodHandler.AuthorizationProxy)
-			}
-		}
+			case proposal.StagePeerCertificate:
+				interceptors[i] = func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					if err := checkPeerCertificate(ctx, serviceName, info.FullMethod); err != nil {
+						return nil, err
+					}
 
-		if authorizationValidator == nil && authorizationProxyValidator == nil {
-			return handler(ctx, req)
-		}
+					return handler(ctx, req)
+				}
 
-		var auth, authProxy string
-		if authHeader := meta.Get(Authorization); len(authHeader) != 0 {
-			auth = authHeader[0]
-		}
-		if authProxyHeader := meta.Get(AuthorizationProxy); len(authProxyHeader) != 0 {
-			authProxy = authProxyHeader[0]
-		}
+			case proposal.StageAuthorization:
+				interceptors[i] = func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					authorizationValidator := getAuthorizationValidator(serviceName, info.FullMethod)
+					if authorizationValidator == nil {
+						return handler(ctx, req)
+					}
 
-		var payload proposal.Payload
-		if forwardedByGrpcGateway(meta) {
-			payload = &restPayload{
-				journalID: journalID,
-				service:   serviceName,
-				date:      meta.Get(Date)[0],
-				method:    meta.Get(Method)[0],
-				uri:       meta.Get(URI)[0],
-				body: func() []byte {
-					if meta.Get(OctetStream)[0] != "" {
-						raw, _ := base64.StdEncoding.DecodeString(meta.Get(Body)[0])
-						return bytes.Join(multipart.ParseFormData(raw), nil)
-
-					} else {
-						return []byte(meta.Get(Body)[0])
+					userinfo, err := authorizationValidator(firstValue(meta, Authorization), payload())
+					if err != nil {
+						s := status.New(codes.Unauthenticated, codes.Unauthenticated.String())
+						s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
+						return nil, s.Err()
+					}
+					if err := checkScopes(info.FullMethod, userinfo); err != nil {
+						return nil, err
+					}
+					if err := authorize(serviceName, info.FullMethod, userinfo); err != nil {
+						return nil, err
 					}
-				}(),
-			}
 
-		} else {
-			payload = &grpcPayload{
-				journalID: journalID,
-				service:   serviceName,
-				date: func() string {
-					if date := meta.Get(Date); len(date) > 0 {
-						return date[0]
+					return handler(context.WithValue(ctx, SessionUserinfo{}, userinfo), req)
+				}
+
+			case proposal.StageAuthorizationProxy:
+				interceptors[i] = func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					authorizationProxyValidator := getAuthorizationProxyValidator(serviceName, info.FullMethod)
+					if authorizationProxyValidator == nil {
+						return handler(ctx, req)
+					}
+
+					identifier, ok, err := authorizationProxyValidator(firstValue(meta, AuthorizationProxy), payload())
+					if err != nil {
+						s := status.New(codes.PermissionDenied, codes.PermissionDenied.String())
+						s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
+						return nil, s.Err()
 					}
-					return ""
-				}(),
-				method: "GRPC",
-				uri:    info.FullMethod,
-				body: func() []byte {
-					if req == nil {
-						return nil
+					if !ok {
+						return nil, status.Error(codes.PermissionDenied, "signature does not match")
 					}
 
-					raw, _ := pbutil.ProtoMessage2JSON(req.(protoV1.Message))
-					return []byte(raw)
-				}(),
-			}
-		}
+					signatureIdentifier = identifier
+					return handler(context.WithValue(ctx, SignatureIdentifier{}, identifier), req)
+				}
 
-		if authorizationValidator != nil {
-			userinfo, err := authorizationValidator(auth, payload)
-			if err != nil {
-				s := status.New(codes.Unauthenticated, codes.Unauthenticated.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
-				return nil, s.Err()
+			case proposal.StageRateLimit:
+				interceptors[i] = func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					if err := checkRateLimit(ctx, opt.Limiter, meta, serviceName, info.FullMethod); err != nil {
+						return nil, err
+					}
+
+					return handler(ctx, req)
+				}
 			}
-			ctx = context.WithValue(ctx, SessionUserinfo{}, userinfo)
 		}
 
-		if authorizationProxyValidator != nil {
-			identifier, ok, err := authorizationProxyValidator(authProxy, payload)
-			if err != nil {
-				s := status.New(codes.PermissionDenied, codes.PermissionDenied.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
-				return nil, s.Err()
-			}
-			if !ok {
-				return nil, status.Error(codes.PermissionDenied, "signature does not match")
-			}
-			ctx = context.WithValue(ctx, SignatureIdentifier{}, identifier)
+		if span != nil {
+			ctx = trace.ContextWithSpanContext(ctx, span.SpanContext)
 		}
 
-		return handler(ctx, req)
+		ctx, cancel := withTimeout(ctx, getTimeout(serviceName, info.FullMethod))
+		defer cancel()
+
+		return enforceDeadline(ctx, req, info.FullMethod, chainUnaryServer(interceptors, info, opt.Idempotency.serverHandler(info.FullMethod, meta, guardDeadline(info.FullMethod, handler))))
+	}
+
+	interceptors := make([]grpc.UnaryServerInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Unary)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return chainUnaryServer(interceptors, info, handler)(ctx, req)
 	}
 }
 
 // StreamServerInterceptor stream interceptor for server
-func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics func(http.Handler), projectName string, disableMessageValitator bool) grpc.StreamServerInterceptor {
-	if metrics != nil {
-		serverInitMetricsOnce.Do(func() {
-			metrics(promhttp.Handler())
-		})
-	}
+func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler, metrics *Metrics, projectName string, disableMessageValitator bool, opt ServerOptions) grpc.StreamServerInterceptor {
+	links := filterServerLinks(opt.Links, false)
+	outer, inner := splitChain(resolveChain(Server, serverPlacements(links)))
 
-	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	journal := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
 		ts := time.Now()
 
 		fullMethod := strings.Split(info.FullMethod, "/")
@@ -444,12 +448,21 @@ func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 		}
 
 		stream.SendHeader(metadata.Pairs(JournalID, journalID))
+
+		var signatureIdentifier string // of options.authorization_proxy, used by metrics
+		defer metrics.start(method)()
+
+		span := startSpan(opt.Tracer, info.FullMethod, trace.KindServer, spanContextFromMeta(meta), journalID)
 		defer func() {
 			if p := recover(); p != nil {
-				errVerbose := fmt.Sprintf("got panic => error: %+v", errors.Panic(p))
+				panicErr := errors.Panic(p)
+				errVerbose := fmt.Sprintf("got panic => error: %+v", panicErr)
 				notify(&proposal.AlertMessage{
 					ProjectName:  projectName,
 					JournalID:    journalID,
+					Method:       info.FullMethod,
+					Origin:       errors.Origin(panicErr),
+					Severity:     proposal.SeverityCritical,
 					ErrorVerbose: errVerbose,
 					Timestamp:    time.Now(),
 				})
@@ -464,21 +477,26 @@ func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 				case proposal.BzError:
 					bzErr := err.(proposal.BzError)
 					statusCode = &pb.Code{HttpStatus: uint32(bzErr.HTTPCode())}
-					s, _ := status.New(codes.Code(bzErr.BzCode()), bzErr.Desc()).WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", bzErr.StackErr())})
-					err = s.Err()
+					err = bzStatus(bzErr).Err()
 
 				case proposal.AlertError:
 					alertErr := err.(proposal.AlertError)
 
+					bzErr := alertErr.BzError()
+
 					alert := alertErr.AlertMessage()
 					alert.ProjectName = projectName
 					alert.JournalID = journalID
+					alert.Method = info.FullMethod
+					alert.BzCode = bzErr.BzCode()
+					if alert.Origin == "" {
+						alert.Origin = errors.Origin(bzErr.StackErr())
+					}
+					redactAlert(alert, nil, meta)
 					notify(alert)
 
-					bzErr := alertErr.BzError()
 					statusCode = &pb.Code{HttpStatus: uint32(bzErr.HTTPCode())}
-					s, _ := status.New(codes.Code(bzErr.BzCode()), bzErr.Desc()).WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", bzErr.StackErr())})
-					err = s.Err()
+					err = bzStatus(bzErr).Err()
 				}
 			}
 
@@ -527,7 +545,7 @@ func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 						}
 					}
 
-					s = status.New(s.Code(), s.Message()) // reset detail
+					s = publicStatus(s) // reset detail, the google.rpc details kept
 					if statusCode != nil {
 						s, _ = s.WithDetails(statusCode)
 					}
@@ -535,166 +553,159 @@ func StreamServerInterceptor(logger *zap.Logger, notify proposal.NotifyHandler,
 					err = s.Err()
 				}
 
+				journal.DeadlineExceeded = deadlineExceeded(err)
 				journal.CostSeconds = time.Since(ts).Seconds()
 
+				raw := marshalJournal(journal)
 				if err == nil {
-					logger.Info("server stream interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Info("server stream interceptor", zap.Any("journal", raw))
 
 				} else {
-					logger.Error("server stream interceptor", zap.Any("journal", marshalJournal(journal)))
+					logger.Error("server stream interceptor", zap.Any("journal", raw))
 				}
+				opt.Journals.Write("server stream interceptor", journal, raw)
 			}
 
-			if metrics != nil {
-				if err == nil {
-					grpcRequestSuccessCounter.WithLabelValues(method).Inc()
-					grpcRequestSuccessDurationHistogram.WithLabelValues(method).Observe(time.Since(ts).Seconds())
+			metrics.observe(method, signatureIdentifier, ts, err)
 
-				} else {
-					s, _ := status.FromError(err)
-					code := s.Code().String()
-
-					grpcRequestErrorCounter.WithLabelValues(method, code).Inc()
-					grpcRequestErrorDurationHistogram.WithLabelValues(method, code).Observe(time.Since(ts).Seconds())
-				}
-			}
+			finishSpan(span, err)
 		}()
 
-		var (
-			authorizationValidator      proposal.UserinfoHandler
-			authorizationProxyValidator proposal.SignatureHandler
-		)
+		payload := func() proposal.Payload {
+			return newPayload(meta, journalID, serviceName, info.FullMethod, func() []byte {
+				return []byte(journalID)
+			})
+		}
 
-		if serviceHandler, ok := getServiceHandler(serviceName); ok {
-			if serviceHandler.Authorization != nil && *serviceHandler.Authorization != "" {
-				authorizationValidator, _ = getAuthorizationHandler(*serviceHandler.Authorization)
+		interceptors := make([]grpc.StreamServerInterceptor, len(inner))
+		for i, node := range inner {
+			if node.link != -1 {
+				interceptors[i] = links[node.link].Stream
+				continue
 			}
 
-			if serviceHandler.AuthorizationProxy != nil && *serviceHandler.AuthorizationProxy != "" {
-				authorizationProxyValidator, _ = getAuthorizationProxyHandler(*serviceHandler.AuthorizationProxy)
-			}
-		}
+			switch node.stage {
+			case proposal.StageValidation:
+				interceptors[i] = func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+					if disableMessageValitator {
+						return handler(srv, stream)
+					}
 
-		if methodHandler, ok := getMethodHandler(info.FullMethod); ok {
-			if methodHandler.Authorization != nil && *methodHandler.Authorization != "" {
-				authorizationValidator, _ = getAuthorizationHandler(*methodHandler.Authorization)
-			}
+					return handler(srv, &validatorServerStream{ServerStream: stream, collectAll: opt.CollectAllViolations})
+				}
 
-			if methodHandler.AuthorizationProxy != nil && *methodHandler.AuthorizationProxy != "" {
-				authorizationProxyValidator, _ = getAuthorizationProxyHandler(*methodHandler.AuthorizationProxy)
-			}
-		}
+			case proposal.StagePeerCertificate:
+				interceptors[i] = func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+					if err := checkPeerCertificate(stream.Context(), serviceName, info.FullMethod); err != nil {
+						return err
+					}
 
-		if authorizationValidator == nil && authorizationProxyValidator == nil {
-			return handler(srv, &streamServerInterceptor{
-				ServerStream: stream,
-				logger:       logger,
+					return handler(srv, stream)
+				}
 
-				journalID: journalID,
+			case proposal.StageAuthorization:
+				interceptors[i] = func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+					authorizationValidator := getAuthorizationValidator(serviceName, info.FullMethod)
+					if authorizationValidator == nil {
+						return handler(srv, stream)
+					}
 
-				ignore:    ignore,
-				doJournal: doJournal,
-				restapi:   forwardedByGrpcGateway(meta),
-				method:    info.FullMethod,
+					userinfo, err := authorizationValidator(firstValue(meta, Authorization), payload())
+					if err != nil {
+						s := status.New(codes.Unauthenticated, codes.Unauthenticated.String())
+						s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
+						return s.Err()
+					}
+					if err := checkScopes(info.FullMethod, userinfo); err != nil {
+						return err
+					}
+					if err := authorize(serviceName, info.FullMethod, userinfo); err != nil {
+						return err
+					}
 
-				disableMessageValitator: disableMessageValitator,
-			})
-		}
+					return handler(srv, &wrappedServerStream{
+						ServerStream: stream,
+						ctx:          context.WithValue(stream.Context(), SessionUserinfo{}, userinfo),
+					})
+				}
 
-		var auth, authProxy string
-		if authHeader := meta.Get(Authorization); len(authHeader) != 0 {
-			auth = authHeader[0]
-		}
-		if authProxyHeader := meta.Get(AuthorizationProxy); len(authProxyHeader) != 0 {
-			authProxy = authProxyHeader[0]
-		}
+			case proposal.StageAuthorizationProxy:
+				interceptors[i] = func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+					authorizationProxyValidator := getAuthorizationProxyValidator(serviceName, info.FullMethod)
+					if authorizationProxyValidator == nil {
+						return handler(srv, stream)
+					}
 
-		var payload proposal.Payload
-		if forwardedByGrpcGateway(meta) {
-			payload = &restPayload{
-				journalID: journalID,
-				service:   serviceName,
-				date:      meta.Get(Date)[0],
-				method:    meta.Get(Method)[0],
-				uri:       meta.Get(URI)[0],
-				body: func() []byte {
-					if meta.Get(OctetStream)[0] != "" {
-						raw, _ := base64.StdEncoding.DecodeString(meta.Get(Body)[0])
-						return bytes.Join(multipart.ParseFormData(raw), nil)
-
-					} else {
-						return []byte(meta.Get(Body)[0])
+					identifier, ok, err := authorizationProxyValidator(firstValue(meta, AuthorizationProxy), payload())
+					if err != nil {
+						s := status.New(codes.PermissionDenied, codes.PermissionDenied.String())
+						s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
+						return s.Err()
+					}
+					if !ok {
+						return status.Error(codes.PermissionDenied, "signature does not match")
 					}
-				}(),
-			}
 
-		} else {
-			payload = &grpcPayload{
-				journalID: journalID,
-				service:   serviceName,
-				date: func() string {
-					if date := meta.Get(Date); len(date) > 0 {
-						return date[0]
+					signatureIdentifier = identifier
+					return handler(srv, &wrappedServerStream{
+						ServerStream: stream,
+						ctx:          context.WithValue(stream.Context(), SignatureIdentifier{}, identifier),
+					})
+				}
+
+			case proposal.StageRateLimit:
+				interceptors[i] = func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+					if err := checkRateLimit(stream.Context(), opt.Limiter, meta, serviceName, info.FullMethod); err != nil {
+						return err
 					}
-					return ""
-				}(),
-				method: "GRPC",
-				uri:    info.FullMethod,
-				body: func() []byte {
-					return []byte(journalID)
-				}(),
-			}
-		}
 
-		var userinfo interface{}
-		if authorizationValidator != nil {
-			userinfo, err = authorizationValidator(auth, payload)
-			if err != nil {
-				s := status.New(codes.Unauthenticated, codes.Unauthenticated.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
-				return s.Err()
+					return handler(srv, stream)
+				}
 			}
 		}
 
-		var identifier string
-		if authorizationProxyValidator != nil {
-			var ok bool
-			identifier, ok, err = authorizationProxyValidator(authProxy, payload)
-			if err != nil {
-				s := status.New(codes.PermissionDenied, codes.PermissionDenied.String())
-				s, _ = s.WithDetails(&pb.Stack{Verbose: fmt.Sprintf("%+v", err)})
-				return s.Err()
-			}
-			if !ok {
-				return status.Error(codes.PermissionDenied, "signature does not match")
-			}
+		ctx, cancel := withTimeout(stream.Context(), getTimeout(serviceName, info.FullMethod))
+		defer cancel()
+
+		if span != nil {
+			ctx = trace.ContextWithSpanContext(ctx, span.SpanContext)
 		}
 
-		return handler(srv, &streamServerInterceptor{
-			ServerStream: stream,
+		return chainStreamServer(interceptors, info, handler)(srv, &streamServerInterceptor{
+			ServerStream: &deadlineServerStream{ServerStream: stream, ctx: ctx},
 			logger:       logger,
+			journals:     opt.Journals,
 
-			journalID:  journalID,
-			userinfo:   userinfo,
-			identifier: identifier,
+			journalID: journalID,
 
 			ignore:    ignore,
 			doJournal: doJournal,
 			restapi:   forwardedByGrpcGateway(meta),
 			method:    info.FullMethod,
 
-			disableMessageValitator: disableMessageValitator,
+			metrics:       metrics,
+			metricsMethod: method,
+			identifier:    &signatureIdentifier,
 		})
 	}
+
+	interceptors := make([]grpc.StreamServerInterceptor, 0, len(outer)+1)
+	for _, node := range outer {
+		interceptors = append(interceptors, links[node.link].Stream)
+	}
+	interceptors = append(interceptors, journal)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return chainStreamServer(interceptors, info, handler)(srv, stream)
+	}
 }
 
 type streamServerInterceptor struct {
 	grpc.ServerStream
-	logger *zap.Logger
+	logger   *zap.Logger
+	journals *JournalWriter
 
-	journalID  string
-	userinfo   interface{}
-	identifier string
+	journalID string
 
 	counter struct {
 		send uint32
@@ -706,22 +717,21 @@ type streamServerInterceptor struct {
 	restapi   bool
 	method    string
 
-	disableMessageValitator bool
+	metrics       *Metrics
+	metricsMethod string
+	identifier    *string
 }
 
 func (s *streamServerInterceptor) Context() context.Context {
 	meta, _ := metadata.FromIncomingContext(s.ServerStream.Context())
 	meta.Set(JournalID, s.journalID)
 
-	ctx := metadata.NewIncomingContext(s.ServerStream.Context(), meta)
-	ctx = context.WithValue(ctx, SessionUserinfo{}, s.userinfo)
-	ctx = context.WithValue(ctx, SignatureIdentifier{}, s.identifier)
-
-	return ctx
+	return metadata.NewIncomingContext(s.ServerStream.Context(), meta)
 }
 
 func (s *streamServerInterceptor) SendMsg(m interface{}) (err error) {
 	s.counter.send++
+	s.metrics.observeStreamMsg(s.metricsMethod, *s.identifier, "send", false, m)
 
 	ts := time.Now()
 	defer func() {
@@ -746,7 +756,9 @@ func (s *streamServerInterceptor) SendMsg(m interface{}) (err error) {
 				CostSeconds: time.Since(ts).Seconds(),
 			}
 
-			s.logger.Info("server stream/send interceptor", zap.Any("journal", marshalJournal(journal)))
+			raw := marshalJournal(journal)
+			s.logger.Info("server stream/send interceptor", zap.Any("journal", raw))
+			s.journals.Write("server stream/send interceptor", journal, raw)
 		}
 	}()
 
@@ -760,15 +772,10 @@ func (s *streamServerInterceptor) RecvMsg(m interface{}) (err error) {
 			return
 		}
 
-		if err == nil && m != nil {
-			if validator, ok := m.(proposal.Validator); ok && !s.disableMessageValitator {
-				if err = validator.Validate(); err != nil {
-					err = status.Error(codes.InvalidArgument, err.Error())
-				}
-			}
-		}
-
 		s.counter.recv++
+		if err == nil {
+			s.metrics.observeStreamMsg(s.metricsMethod, *s.identifier, "recv", true, m)
+		}
 
 		if !s.ignore {
 			journal := &pb.Journal{
@@ -793,9 +800,95 @@ func (s *streamServerInterceptor) RecvMsg(m interface{}) (err error) {
 				CostSeconds: time.Since(ts).Seconds(),
 			}
 
-			s.logger.Info("server stream/recv interceptor", zap.Any("journal", marshalJournal(journal)))
+			raw := marshalJournal(journal)
+			s.logger.Info("server stream/recv interceptor", zap.Any("journal", raw))
+			s.journals.Write("server stream/recv interceptor", journal, raw)
 		}
 	}()
 
 	return s.ServerStream.RecvMsg(m)
 }
+
+type validatorServerStream struct {
+	grpc.ServerStream
+	collectAll bool
+}
+
+func (v *validatorServerStream) RecvMsg(m interface{}) error {
+	if err := v.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validateMessage(m, v.collectAll)
+}
+
+func newPayload(meta metadata.MD, journalID, serviceName, fullMethod string, grpcBody func() []byte) proposal.Payload {
+	if forwardedByGrpcGateway(meta) {
+		return &restPayload{
+			journalID: journalID,
+			service:   serviceName,
+			date:      meta.Get(Date)[0],
+			method:    meta.Get(Method)[0],
+			uri:       meta.Get(URI)[0],
+			body: func() []byte {
+				if meta.Get(OctetStream)[0] != "" {
+					raw, _ := base64.StdEncoding.DecodeString(meta.Get(Body)[0])
+					return bytes.Join(multipart.ParseFormData(raw), nil)
+
+				} else {
+					return []byte(meta.Get(Body)[0])
+				}
+			}(),
+		}
+	}
+
+	return &grpcPayload{
+		journalID: journalID,
+		service:   serviceName,
+		date:      firstValue(meta, Date),
+		method:    "GRPC",
+		uri:       fullMethod,
+		body:      grpcBody(),
+	}
+}
+
+func getAuthorizationValidator(serviceName, fullMethod string) (authorizationValidator proposal.UserinfoHandler) {
+	if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.Authorization != nil && *serviceHandler.Authorization != "" {
+		authorizationValidator, _ = getAuthorizationHandler(*serviceHandler.Authorization)
+	}
+
+	if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.Authorization != nil && *methodHandler.Authorization != "" {
+		authorizationValidator, _ = getAuthorizationHandler(*methodHandler.Authorization)
+	}
+
+	return
+}
+
+// checkScopes the userinfo should be granted all the options.scopes of method, denied with cuzerr.PermissionDenied as authorize
+func checkScopes(fullMethod string, userinfo interface{}) error {
+	methodHandler, _ := getMethodHandler(fullMethod)
+	if len(methodHandler.GetScopes()) == 0 {
+		return nil
+	}
+
+	scoped, ok := userinfo.(proposal.ScopedUserinfo)
+	for _, scope := range methodHandler.GetScopes() {
+		if !ok || !scoped.HasScope(scope) {
+			return cuzerr.NewBzError(cuzerr.PermissionDenied, errors.Errorf("scope %s required by %s", scope, fullMethod))
+		}
+	}
+
+	return nil
+}
+
+func getAuthorizationProxyValidator(serviceName, fullMethod string) (authorizationProxyValidator proposal.SignatureHandler) {
+	if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.AuthorizationProxy != nil && *serviceHandler.AuthorizationProxy != "" {
+		authorizationProxyValidator, _ = getAuthorizationProxyHandler(*serviceHandler.AuthorizationProxy)
+	}
+
+	if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.AuthorizationProxy != nil && *methodHandler.AuthorizationProxy != "" {
+		authorizationProxyValidator, _ = getAuthorizationProxyHandler(*methodHandler.AuthorizationProxy)
+	}
+
+	return
+}
@@ -0,0 +1,118 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// parseTimeout parse options.timeout, panic if illegal
+func parseTimeout(name, timeout string) time.Duration {
+	duration, err := time.ParseDuration(timeout)
+	if err != nil || duration <= 0 {
+		panic(fmt.Sprintf("%s timeout: %s illegal", name, timeout))
+	}
+
+	return duration
+}
+
+// getTimeout the method's timeout takes precedence over the service's one
+func getTimeout(serviceName, fullMethod string) (timeout time.Duration) {
+	if serviceHandler, ok := getServiceHandler(serviceName); ok && serviceHandler.GetTimeout() != "" {
+		timeout, _ = time.ParseDuration(serviceHandler.GetTimeout())
+	}
+
+	if methodHandler, ok := getMethodHandler(fullMethod); ok && methodHandler.GetTimeout() != "" {
+		timeout, _ = time.ParseDuration(methodHandler.GetTimeout())
+	}
+
+	return
+}
+
+// withTimeout derive a context with the timeout, a shorter deadline (the remaining budget of upstream) is kept
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// checkDeadline fail fast if the budget has been used up
+func checkDeadline(ctx context.Context, fullMethod string) error {
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return status.Errorf(codes.DeadlineExceeded, "%s deadline exceeded before call", fullMethod)
+	}
+
+	return nil
+}
+
+// deadlineExceeded whether the err caused by deadline
+func deadlineExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if err == context.DeadlineExceeded {
+		return true
+	}
+
+	s, _ := status.FromError(err)
+	return s.Code() == codes.DeadlineExceeded
+}
+
+// enforceDeadline run the handler in place with the deadline carried by ctx, so it's never detached from the chain;
+// the result is replaced by DeadlineExceeded if the deadline reached before the handler returned
+func enforceDeadline(ctx context.Context, req interface{}, fullMethod string, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, status.Errorf(codes.DeadlineExceeded, "%s cut off by deadline", fullMethod)
+	}
+
+	return resp, err
+}
+
+// guardDeadline the handler never started once the deadline reached, e.g. used up by the authorization and rate limit,
+// so that a call told DeadlineExceeded has no side effects
+func guardDeadline(fullMethod string, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := ctx.Err(); err != nil {
+			if err == context.DeadlineExceeded {
+				return nil, status.Errorf(codes.DeadlineExceeded, "%s cut off by deadline", fullMethod)
+			}
+			return nil, status.FromContextError(err).Err()
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// deadlineServerStream carry the deadline into the handler, and refuse to send or recv once reached
+type deadlineServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (d *deadlineServerStream) Context() context.Context {
+	return d.ctx
+}
+
+func (d *deadlineServerStream) SendMsg(m interface{}) error {
+	if err := d.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	return d.ServerStream.SendMsg(m)
+}
+
+func (d *deadlineServerStream) RecvMsg(m interface{}) error {
+	if err := d.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	return d.ServerStream.RecvMsg(m)
+}
@@ -0,0 +1,92 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestEnforceDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	slow := func(ctx context.Context, req interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return "ok", nil
+		}
+	}
+
+	ctx, cancel := withTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	ts := time.Now()
+	resp, err := enforceDeadline(ctx, nil, "/dummy.DummyService/Echo", slow)
+	assert.Nil(resp)
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+	assert.True(deadlineExceeded(err))
+	assert.Less(time.Since(ts), time.Millisecond*500)
+
+	// a shorter deadline of upstream is kept
+	upstream, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	ctx, cancel = withTimeout(upstream, time.Minute)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	assert.Less(time.Until(deadline), time.Second)
+
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(codes.DeadlineExceeded, status.Code(checkDeadline(ctx, "/dummy.DummyService/Echo")))
+
+	resp, err = enforceDeadline(context.Background(), nil, "/dummy.DummyService/Echo", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.Nil(err)
+	assert.Equal("ok", resp)
+
+	assert.Panics(func() {
+		parseTimeout("/dummy.DummyService/Echo", "3 seconds")
+	})
+}
+
+func TestDeadlineNotDetached(t *testing.T) {
+	assert := assert.New(t)
+
+	// outlives the deadline, and the identifier is set after it reached
+	registerOnce("dummy_slow_signature", func() {
+		RegisteAuthorizationProxyValidator("dummy_slow_signature", func(signature string, payload proposal.Payload) (string, bool, error) {
+			time.Sleep(time.Millisecond * 100)
+			return "slow", true, nil
+		})
+	})
+	setMethodHandler(t, "/dummy.DummyService/Slow", &options.MethodHandler{AuthorizationProxy: proto.String("dummy_slow_signature"), Timeout: proto.String("20ms")}, nil)
+
+	metrics := NewMetrics(Server, "demo", &proposal.MetricsConfig{Registry: prometheus.NewRegistry(), Namespace: "dummy_deadline"})
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, metrics, "demo", false, ServerOptions{})
+
+	finished := false
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationProxy, "signature"))
+	_, err := unary(ctx, wrapperspb.String("ping"), &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Slow"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		finished = true
+		return req, nil
+	})
+
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+	assert.False(finished) // never started once the deadline used up by authorization, race detected by -race if detached
+}
@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"github.com/bluekaki/pkg/trace"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startSpan begin a span if tracing enabled, nil returned if disabled
+func startSpan(tracer *trace.Tracer, fullMethod string, kind trace.SpanKind, parent trace.SpanContext, journalID string) *trace.Span {
+	if tracer == nil {
+		return nil
+	}
+
+	span := tracer.Start(fullMethod, kind, parent, journalID)
+	span.SetAttribute("journal_id", journalID)
+	span.SetAttribute("rpc.method", fullMethod)
+	return span
+}
+
+// finishSpan end the span with the grpc code of err
+func finishSpan(span *trace.Span, err error) {
+	if httpErr, ok := err.(*runtime.HTTPStatusError); ok {
+		err = httpErr.Err
+	}
+
+	span.Finish(status.Code(err).String(), err)
+}
+
+// spanContextFromMeta the parent propagated by traceparent and tracestate, invalid if not found or illegal
+func spanContextFromMeta(meta metadata.MD) trace.SpanContext {
+	traceparent := firstValue(meta, Traceparent)
+	if traceparent == "" {
+		return trace.SpanContext{}
+	}
+
+	sc, err := trace.ParseTraceparent(traceparent)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+
+	sc.TraceState = firstValue(meta, Tracestate)
+	return sc
+}
+
+// injectSpanContext propagate the span by traceparent and tracestate
+func injectSpanContext(meta metadata.MD, span *trace.Span) {
+	if span == nil {
+		return
+	}
+
+	meta.Set(Traceparent, span.SpanContext.Traceparent())
+	if span.SpanContext.TraceState != "" {
+		meta.Set(Tracestate, span.SpanContext.TraceState)
+	}
+}
@@ -0,0 +1,51 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluekaki/pkg/trace"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := trace.NewInMemoryExporter()
+	unary := UnaryServerInterceptor(zap.NewNop(), func(*proposal.AlertMessage) {}, nil, "", false, ServerOptions{Tracer: trace.NewTracer(exporter)})
+
+	parent, _ := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	meta := metadata.Pairs(JournalID, "4bf92f3577b34da6a3ce929d0e0e4736", Traceparent, parent.Traceparent())
+
+	var current trace.SpanContext
+	_, err := unary(metadata.NewIncomingContext(context.Background(), meta), nil, &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		current = trace.SpanContextFromContext(ctx)
+		return "ok", nil
+	})
+	assert.Nil(err)
+
+	spans := exporter.Spans()
+	assert.Len(spans, 1)
+	assert.Equal(current, spans[0].SpanContext)
+	assert.Equal(parent.TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(parent.SpanID, spans[0].Parent)
+	assert.Equal(trace.KindServer, spans[0].Kind)
+	assert.Equal("OK", spans[0].Code)
+
+	// without traceparent the journal id is reused as trace id
+	exporter.Reset()
+	_, err = unary(metadata.NewIncomingContext(context.Background(), metadata.Pairs(JournalID, "4bf92f3577b34da6a3ce929d0e0e4736")), nil, &grpc.UnaryServerInfo{FullMethod: "/dummy.DummyService/Echo"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.Nil(err)
+
+	spans = exporter.Spans()
+	assert.Len(spans, 1)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID.String())
+	assert.False(spans[0].Parent.IsValid())
+}
@@ -0,0 +1,558 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	stderr "errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/pkg/marshaler"
+	"github.com/bluekaki/pkg/vv/internal/pkg/multipart"
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	defaultChunkBytes = 64 << 10
+
+	// the fields of the chunk messages, by name
+	rawField         = "raw"
+	fileNameField    = "file_name"
+	contentTypeField = "content_type"
+	sizeField        = "size"
+	offsetField      = "offset"
+	lengthField      = "length"
+)
+
+// errRangeSent the requested range sent, the rest of stream discarded
+var errRangeSent = stderr.New("range sent")
+
+type transferRoute struct {
+	fullMethod string
+	method     string
+	path       *regexp.Regexp
+
+	// upload
+	chunkBytes int
+	maxBytes   int64
+	accept     []string
+
+	// download
+	output      protoreflect.MessageDescriptor
+	contentType string // of validator.media
+	seekable    bool   // the request declared offset and length, the server seeks by them
+}
+
+// Transfer wrap the gateway mux, streams the files of methods declared options.upload and options.download without buffering them.
+//
+// Upload: the files of multipart/form-data sent to the client streaming method by chunks, each a request message of
+// bytes raw, with file_name and content_type if declared; the content types accepted by validator.media of the request message.
+//
+// Download: the chunks of the server streaming method (GET only) written as the file body, each a response message of
+// bytes raw, and the first one carries size, content_type and file_name if declared; a single Range responded with 206,
+// the bytes out of it skipped by gateway, or sought by the server if the request message declared offset and length
+// (set by a bounded range); the position of each chunk taken by its offset if the response message declared it, so it must be set.
+func Transfer(logger *zap.Logger) func(http.Handler) http.Handler {
+	var uploads, downloads []*transferRoute
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		serivces := fd.Services()
+		for i := 0; i < serivces.Len(); i++ {
+			serivce := serivces.Get(i)
+
+			methods := serivce.Methods()
+			for k := 0; k < methods.Len(); k++ {
+				method := methods.Get(k)
+				fullMethod := fmt.Sprintf("/%s/%s", serivce.FullName(), method.Name())
+
+				methodHandler, _ := getMethodHandler(fullMethod)
+				httpRule, _ := getHTTPRule(fullMethod)
+
+				if upload := methodHandler.GetUpload(); upload != nil {
+					if !method.IsStreamingClient() || method.IsStreamingServer() {
+						panic(fmt.Sprintf("options.upload of %s requires client streaming", fullMethod))
+					}
+					if upload.GetMaxBytes() == 0 {
+						panic(fmt.Sprintf("options.upload max_bytes of %s required", fullMethod))
+					}
+					checkRawField(fullMethod, method.Input())
+
+					chunkBytes := int(upload.GetChunkBytes())
+					if chunkBytes == 0 {
+						chunkBytes = defaultChunkBytes
+					}
+
+					var accept []string
+					if media, _ := proto.GetExtension(method.Input().Options(), validator.E_Media).(*validator.MediaValidator); media != nil {
+						if media.GetContentType() != "" {
+							accept = append(accept, media.GetContentType())
+						}
+						accept = append(accept, media.GetAccept()...)
+					}
+
+					for _, rule := range httpRules(httpRule) {
+						if rule.Method == http.MethodGet {
+							continue
+						}
+
+						uploads = append(uploads, &transferRoute{
+							fullMethod: fullMethod,
+							method:     rule.Method,
+							path:       templateRegexp(rule.Path),
+							chunkBytes: chunkBytes,
+							maxBytes:   int64(upload.GetMaxBytes()),
+							accept:     accept,
+						})
+					}
+				}
+
+				if methodHandler.GetDownload() {
+					if method.IsStreamingClient() || !method.IsStreamingServer() {
+						panic(fmt.Sprintf("options.download of %s requires server streaming", fullMethod))
+					}
+					checkRawField(fullMethod, method.Output())
+
+					var contentType string
+					if media, _ := proto.GetExtension(method.Output().Options(), validator.E_Media).(*validator.MediaValidator); media != nil {
+						contentType = media.GetContentType()
+					}
+
+					for _, rule := range httpRules(httpRule) {
+						if rule.Method != http.MethodGet {
+							continue
+						}
+
+						downloads = append(downloads, &transferRoute{
+							fullMethod:  fullMethod,
+							method:      rule.Method,
+							path:        templateRegexp(rule.Path),
+							output:      method.Output(),
+							contentType: contentType,
+							seekable:    method.Input().Fields().ByName(offsetField) != nil && method.Input().Fields().ByName(lengthField) != nil,
+						})
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+				for _, route := range uploads {
+					if route.method == r.Method && route.path.MatchString(r.URL.Path) {
+						upload(w, r, route, next)
+						return
+					}
+				}
+			}
+
+			if r.Method == http.MethodGet {
+				for _, route := range downloads {
+					if route.path.MatchString(r.URL.Path) {
+						download(logger, w, r, route, next)
+						return
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkRawField(fullMethod string, message protoreflect.MessageDescriptor) {
+	if field := message.Fields().ByName(rawField); field == nil || field.Kind() != protoreflect.BytesKind || field.IsList() {
+		panic(fmt.Sprintf("options.upload/download of %s requires bytes %s of %s", fullMethod, rawField, message.FullName()))
+	}
+}
+
+func upload(w http.ResponseWriter, r *http.Request, route *transferRoute, next http.Handler) {
+	chunker, err := multipart.NewChunker(r, route.chunkBytes, route.maxBytes, route.accept)
+	if err != nil {
+		httpStatus := http.StatusBadRequest
+		if stderr.Is(err, multipart.ErrContentType) {
+			httpStatus = http.StatusUnsupportedMediaType
+		}
+
+		writeErrorEnvelope(r.Context(), w, httpStatus, &ErrorEnvelope{Code: int32(codes.InvalidArgument), Message: err.Error()}, "")
+		return
+	}
+
+	req := r.Clone(context.WithValue(r.Context(), streamingBodyKey{}, true))
+	req.Body = &uploadBody{chunker: chunker}
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", marshaler.ChunkMIME)
+
+	next.ServeHTTP(&uploadWriter{ResponseWriter: w, chunker: chunker}, req)
+}
+
+// uploadBody the files read by chunks
+type uploadBody struct {
+	chunker *multipart.Chunker
+}
+
+func (u *uploadBody) Read([]byte) (int, error) {
+	return 0, errors.New("upload read by chunks only")
+}
+
+func (u *uploadBody) Close() error {
+	return u.chunker.Close()
+}
+
+func (u *uploadBody) NextChunk(message proto.Message) error {
+	chunk, err := u.chunker.Next()
+	if err != nil {
+		return err
+	}
+
+	m := message.ProtoReflect()
+	fields := m.Descriptor().Fields()
+
+	m.Set(fields.ByName(rawField), protoreflect.ValueOfBytes(chunk.Data))
+	if field := fields.ByName(fileNameField); field != nil && field.Kind() == protoreflect.StringKind {
+		m.Set(field, protoreflect.ValueOfString(chunk.FileName))
+	}
+	if field := fields.ByName(contentTypeField); field != nil && field.Kind() == protoreflect.StringKind {
+		m.Set(field, protoreflect.ValueOfString(chunk.ContentType))
+	}
+
+	return nil
+}
+
+// uploadWriter the error of decoding responded as 400 by gateway, corrected by the chunker
+type uploadWriter struct {
+	http.ResponseWriter
+	chunker *multipart.Chunker
+}
+
+func (u *uploadWriter) WriteHeader(status int) {
+	if status == http.StatusBadRequest {
+		switch err := u.chunker.Err(); {
+		case stderr.Is(err, multipart.ErrTooLarge):
+			status = http.StatusRequestEntityTooLarge
+		case stderr.Is(err, multipart.ErrContentType):
+			status = http.StatusUnsupportedMediaType
+		}
+	}
+
+	u.ResponseWriter.WriteHeader(status)
+}
+
+// byteRange a single range of bytes, suffix if start < 0, open ended if end < 0
+type byteRange struct {
+	start, end int64
+	suffix     int64
+}
+
+// parseRange a single range of bytes unit, nil if absent, illegal or multiple ranges, which are ignored
+func parseRange(header string) *byteRange {
+	spec := strings.TrimSpace(header)
+	if !strings.HasPrefix(spec, "bytes=") || strings.Contains(spec, ",") {
+		return nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec[len("bytes="):]), "-")
+	if !ok {
+		return nil
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return nil
+		}
+		return &byteRange{start: -1, end: -1, suffix: suffix}
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil
+	}
+
+	end := int64(-1)
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return nil
+		}
+	}
+
+	return &byteRange{start: start, end: end}
+}
+
+func download(logger *zap.Logger, w http.ResponseWriter, r *http.Request, route *transferRoute, next http.Handler) {
+	req := r.Clone(r.Context())
+	req.Header.Set("Accept", marshaler.ChunkMIME)
+
+	writer := &downloadWriter{w: w, route: route, rng: parseRange(r.Header.Get("Range")), end: -1}
+	if rng := writer.rng; rng != nil && rng.start >= 0 && rng.end >= 0 && route.seekable {
+		query := req.URL.Query()
+		query.Set(offsetField, strconv.FormatInt(rng.start, 10))
+		query.Set(lengthField, strconv.FormatInt(rng.end-rng.start+1, 10))
+		req.URL.RawQuery = query.Encode()
+
+		writer.position = writer.rng.start
+	}
+
+	next.ServeHTTP(writer, req)
+
+	if err := writer.finish(); err != nil {
+		logger.Error("gateway download aborted",
+			zap.String("journal_id", w.Header().Get(runtime.MetadataHeaderPrefix+JournalID)),
+			zap.String("method", route.fullMethod),
+			zap.Error(err),
+		)
+		panic(http.ErrAbortHandler) // the client sees the body truncated
+	}
+}
+
+// downloadWriter write the raw of chunks as the body, the response of non 200 passed through
+type downloadWriter struct {
+	w     http.ResponseWriter
+	route *transferRoute
+	rng   *byteRange
+
+	status   int
+	started  bool
+	done     bool  // the range sent, or unsatisfiable
+	position int64 // the offset of the next chunk
+	start    int64 // the first byte to send
+	end      int64 // the last byte to send, -1 if unknown
+	length   int64 // Content-Length, -1 if unknown
+	sent     int64
+	buf      bytes.Buffer
+}
+
+func (d *downloadWriter) Header() http.Header {
+	return d.w.Header()
+}
+
+// WriteHeader 200 deferred until the first chunk, as it may be 206 or 416
+func (d *downloadWriter) WriteHeader(status int) {
+	if d.status != 0 {
+		return
+	}
+
+	d.status = status
+	if status != http.StatusOK {
+		d.w.WriteHeader(status)
+	}
+}
+
+// Flush flushed on each chunk
+func (d *downloadWriter) Flush() {}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if d.status == 0 {
+		d.status = http.StatusOK
+	}
+	if d.status != http.StatusOK {
+		return d.w.Write(p)
+	}
+	if d.done {
+		return 0, errRangeSent
+	}
+
+	d.buf.Write(p)
+	for {
+		raw, n, ok := marshaler.SplitChunkFrame(d.buf.Bytes())
+		if !ok {
+			break // not completed, or the error left in buf
+		}
+
+		if err := d.chunk(raw); err != nil {
+			return 0, err
+		}
+		d.buf.Next(n)
+	}
+
+	return len(p), nil
+}
+
+func (d *downloadWriter) chunk(raw []byte) error {
+	var message proto.Message
+	if messageType, err := protoregistry.GlobalTypes.FindMessageByName(d.route.output.FullName()); err == nil {
+		message = messageType.New().Interface()
+	} else {
+		message = dynamicpb.NewMessage(d.route.output)
+	}
+	if err := proto.Unmarshal(raw, message); err != nil {
+		return errors.Wrap(err, "unmarshal chunk err")
+	}
+
+	m := message.ProtoReflect()
+	fields := m.Descriptor().Fields()
+
+	if !d.started {
+		d.begin(intField(m, sizeField), stringField(m, contentTypeField), stringField(m, fileNameField))
+		if d.done {
+			return errRangeSent
+		}
+	}
+
+	if fields.ByName(offsetField) != nil {
+		d.position = intField(m, offsetField)
+	}
+
+	data := m.Get(fields.ByName(rawField)).Bytes()
+	from, to := d.position, d.position+int64(len(data)) // [from, to)
+	d.position = to
+
+	if from < d.start {
+		if to <= d.start {
+			return nil
+		}
+		data, from = data[d.start-from:], d.start
+	}
+	if d.end >= 0 && to > d.end+1 {
+		data = data[:d.end+1-from]
+	}
+
+	if len(data) > 0 {
+		if _, err := d.w.Write(data); err != nil {
+			return err
+		}
+		d.sent += int64(len(data))
+
+		if flusher, ok := d.w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	if d.end >= 0 && d.position > d.end {
+		d.done = true
+		return errRangeSent
+	}
+	return nil
+}
+
+// begin write the header by the first chunk, the range resolved by size (0 means unknown)
+func (d *downloadWriter) begin(size int64, contentType, fileName string) {
+	d.started = true
+
+	header := d.w.Header()
+	header.Del("Transfer-Encoding")
+	header.Set("Accept-Ranges", "bytes")
+
+	switch {
+	case contentType != "":
+	case d.route.contentType != "":
+		contentType = d.route.contentType
+	default:
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	if fileName != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+
+	d.length = -1
+	if size > 0 {
+		d.length = size
+	}
+
+	rng := d.rng
+	if rng != nil && size <= 0 && (rng.start < 0 || rng.end < 0) {
+		rng = nil // unable to resolve without size, the whole responded
+	}
+
+	if rng == nil {
+		d.start, d.end = 0, -1
+		if size > 0 {
+			d.end = size - 1
+			header.Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+		d.w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	d.start, d.end = rng.start, rng.end
+	if rng.start < 0 {
+		d.start, d.end = size-rng.suffix, size-1
+		if d.start < 0 {
+			d.start = 0
+		}
+	}
+
+	total := "*"
+	if size > 0 {
+		total = strconv.FormatInt(size, 10)
+		if d.start >= size {
+			header.Del("Content-Type")
+			header.Del("Content-Disposition")
+			header.Set("Content-Range", "bytes */"+total)
+			d.w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			d.done = true
+			return
+		}
+		if d.end < 0 || d.end >= size {
+			d.end = size - 1
+		}
+	}
+
+	d.length = d.end - d.start + 1
+	header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%s", d.start, d.end, total))
+	header.Set("Content-Length", strconv.FormatInt(d.length, 10))
+	d.w.WriteHeader(http.StatusPartialContent)
+}
+
+// finish an empty body if no chunk, the error returned if the body not completed
+func (d *downloadWriter) finish() error {
+	if d.status != http.StatusOK || d.done {
+		return nil
+	}
+	if !d.started {
+		if d.buf.Len() > 0 { // failed before any chunk but responded 200, impossible by gateway
+			return errors.Errorf("download failed: %s", d.buf.String())
+		}
+
+		d.begin(0, "", "")
+		return nil
+	}
+
+	if d.buf.Len() > 0 {
+		return errors.Errorf("download failed after %d bytes sent: %s", d.sent, d.buf.String())
+	}
+	if d.length >= 0 && d.sent != d.length {
+		return errors.Errorf("download ended after %d of %d bytes sent", d.sent, d.length)
+	}
+	return nil
+}
+
+func intField(m protoreflect.Message, name protoreflect.Name) int64 {
+	field := m.Descriptor().Fields().ByName(name)
+	if field == nil {
+		return 0
+	}
+
+	switch field.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return m.Get(field).Int()
+
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(m.Get(field).Uint())
+	}
+	return 0
+}
+
+func stringField(m protoreflect.Message, name protoreflect.Name) string {
+	if field := m.Descriptor().Fields().ByName(name); field != nil && field.Kind() == protoreflect.StringKind {
+		return m.Get(field).String()
+	}
+	return ""
+}
@@ -0,0 +1,396 @@
+package interceptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/internal/pkg/marshaler"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestParseRange(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(&byteRange{start: 0, end: 99}, parseRange("bytes=0-99"))
+	assert.Equal(&byteRange{start: 100, end: -1}, parseRange("bytes=100-"))
+	assert.Equal(&byteRange{start: -1, end: -1, suffix: 10}, parseRange("bytes=-10"))
+	assert.Nil(parseRange(""))
+	assert.Nil(parseRange("bytes=0-1,5-6"))
+	assert.Nil(parseRange("bytes=9-1"))
+	assert.Nil(parseRange("items=0-1"))
+	assert.Nil(parseRange("bytes=-0"))
+}
+
+func TestTransfer(t *testing.T) {
+	assert := assert.New(t)
+
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     kind.Enum(),
+		}
+	}
+
+	chunkOptions := new(descriptorpb.MessageOptions)
+	proto.SetExtension(chunkOptions, validator.E_Media, &validator.MediaValidator{Accept: []string{"image/*", "text/plain", "text/csv", "application/json"}})
+
+	file := registerFile(t, &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("dummy/transfer.proto"),
+		Package: proto.String("dummy"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("FileChunk"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("file_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("content_type", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("raw", 3, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+					field("size", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					field("offset", 5, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				},
+				Options: chunkOptions,
+			},
+			{
+				Name:  proto.String("UploadResp"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("digest", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+			},
+			{
+				Name: proto.String("DownloadReq"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("offset", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					field("length", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				},
+			},
+			{
+				Name:  proto.String("ExportReq"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("FileService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:            proto.String("Upload"),
+						InputType:       proto.String(".dummy.FileChunk"),
+						OutputType:      proto.String(".dummy.UploadResp"),
+						ClientStreaming: proto.Bool(true),
+					},
+					{
+						Name:            proto.String("Download"),
+						InputType:       proto.String(".dummy.DownloadReq"),
+						OutputType:      proto.String(".dummy.FileChunk"),
+						ServerStreaming: proto.Bool(true),
+					},
+					{
+						Name:            proto.String("Export"),
+						InputType:       proto.String(".dummy.ExportReq"),
+						OutputType:      proto.String(".dummy.FileChunk"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	})
+	setMethodHandler(t, "/dummy.FileService/Upload", &options.MethodHandler{Upload: &options.Upload{MaxBytes: proto.Uint64(1000), ChunkBytes: proto.Uint32(16)}},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/files"}, Body: "*"})
+	setMethodHandler(t, "/dummy.FileService/Download", &options.MethodHandler{Download: proto.Bool(true)},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/files/{name}"}})
+	setMethodHandler(t, "/dummy.FileService/Export", &options.MethodHandler{Download: proto.Bool(true)},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/exports/{name}"}})
+
+	chunkDesc := file.Messages().ByName("FileChunk")
+	content := []byte(strings.Repeat("0123456789", 10))
+
+	// the generated handlers of grpc-gateway, by the runtime
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(marshaler.ChunkMIME, marshaler.NewChunkMarshaler()),
+		runtime.WithMarshalerOption("application/json", marshaler.NewJSONPbMarshaler()),
+	)
+
+	var uploaded []*dynamicpb.Message
+	mux.HandlePath(http.MethodPost, "/v1/files", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		inbound, outbound := runtime.MarshalerForRequest(mux, r)
+		assert.True(StreamingBody(r.Context()))
+
+		uploaded = nil
+		digest := sha256.New()
+		decoder := inbound.NewDecoder(r.Body)
+		for {
+			chunk := dynamicpb.NewMessage(chunkDesc)
+			err := decoder.Decode(chunk)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outbound, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+				return
+			}
+
+			uploaded = append(uploaded, chunk)
+			digest.Write(chunk.Get(chunkDesc.Fields().ByName("raw")).Bytes())
+		}
+
+		resp := dynamicpb.NewMessage(file.Messages().ByName("UploadResp"))
+		resp.Set(resp.Descriptor().Fields().ByName("digest"), protoreflect.ValueOfString(hex.EncodeToString(digest.Sum(nil))))
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, resp)
+	})
+
+	var sought string
+	stream := func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+
+		name := params["name"]
+		if name == "missing" {
+			runtime.HTTPError(ctx, mux, outbound, w, r, status.Error(codes.NotFound, "file not found"))
+			return
+		}
+
+		from, to := 0, len(content)
+		if offset := r.URL.Query().Get("offset"); offset != "" {
+			sought = offset + "+" + r.URL.Query().Get("length")
+			from, _ = strconv.Atoi(offset)
+			length, _ := strconv.Atoi(r.URL.Query().Get("length"))
+			to = from + length
+		}
+
+		i := from
+		runtime.ForwardResponseStream(ctx, mux, outbound, w, r, func() (proto.Message, error) {
+			if i >= to {
+				return nil, io.EOF
+			}
+			if name == "broken" && i >= 32 {
+				return nil, status.Error(codes.Unavailable, "storage unavailable")
+			}
+
+			end := i + 16
+			if end > to {
+				end = to
+			}
+
+			chunk := dynamicpb.NewMessage(chunkDesc)
+			chunk.Set(chunkDesc.Fields().ByName("raw"), protoreflect.ValueOfBytes(content[i:end]))
+			if params["name"] != "unsized" && i == from {
+				chunk.Set(chunkDesc.Fields().ByName("size"), protoreflect.ValueOfInt64(int64(len(content))))
+				chunk.Set(chunkDesc.Fields().ByName("content_type"), protoreflect.ValueOfString("text/plain"))
+				chunk.Set(chunkDesc.Fields().ByName("file_name"), protoreflect.ValueOfString(name+".txt"))
+			}
+			chunk.Set(chunkDesc.Fields().ByName("offset"), protoreflect.ValueOfInt64(int64(i)))
+
+			i = end
+			return chunk, nil
+		})
+	}
+	mux.HandlePath(http.MethodGet, "/v1/files/{name}", stream)
+	mux.HandlePath(http.MethodGet, "/v1/exports/{name}", stream)
+
+	server := httptest.NewServer(Transfer(zap.NewNop())(mux))
+	defer server.Close()
+
+	t.Run("upload", func(t *testing.T) {
+		type part struct {
+			field, fileName, contentType string
+			raw                          []byte
+		}
+
+		post := func(parts ...part) (*http.Response, string) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			for _, p := range parts {
+				header := make(textproto.MIMEHeader)
+				disposition := fmt.Sprintf(`form-data; name="%s"`, p.field)
+				if p.fileName != "" {
+					disposition += fmt.Sprintf(`; filename="%s"`, p.fileName)
+				}
+				header.Set("Content-Disposition", disposition)
+				if p.contentType != "" {
+					header.Set("Content-Type", p.contentType)
+				}
+
+				w, _ := writer.CreatePart(header)
+				w.Write(p.raw)
+			}
+			writer.Close()
+
+			resp, err := http.Post(server.URL+"/v1/files", writer.FormDataContentType(), &body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			raw, _ := io.ReadAll(resp.Body)
+			return resp, string(raw)
+		}
+
+		png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 24)...)
+		resp, body := post(
+			part{field: "note", raw: []byte("skipped")},
+			part{field: "file", fileName: "a.txt", contentType: "text/plain", raw: content},
+			part{field: "file", fileName: "b.png", raw: png}, // sniffed
+		)
+		assert.Equal(http.StatusOK, resp.StatusCode)
+
+		digest := sha256.Sum256(append(append([]byte{}, content...), png...))
+		assert.Contains(body, hex.EncodeToString(digest[:]))
+
+		if assert.Len(uploaded, 7+2) { // 100 bytes and 32 bytes by 16
+			assert.Equal("a.txt", uploaded[0].Get(chunkDesc.Fields().ByName("file_name")).String())
+			assert.Equal("text/plain; charset=utf-8", uploaded[6].Get(chunkDesc.Fields().ByName("content_type")).String())
+			assert.Len(uploaded[6].Get(chunkDesc.Fields().ByName("raw")).Bytes(), 4)
+			assert.Equal("b.png", uploaded[7].Get(chunkDesc.Fields().ByName("file_name")).String())
+			assert.Equal("image/png", uploaded[8].Get(chunkDesc.Fields().ByName("content_type")).String())
+		}
+
+		zip := append([]byte("PK\x03\x04"), content...)
+		uploaded = nil
+		resp, _ = post(part{field: "file", fileName: "a.zip", contentType: "application/zip", raw: zip})
+		assert.Equal(http.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Nil(uploaded) // rejected before streaming
+
+		// the declared taken if the sniffed generic
+		uploaded = nil
+		resp, _ = post(
+			part{field: "file", fileName: "a.csv", contentType: "text/csv", raw: []byte("id,name\n1,alice\n")},
+			part{field: "file", fileName: "a.json", contentType: "application/json", raw: []byte(`{"id":1}`)},
+		)
+		assert.Equal(http.StatusOK, resp.StatusCode)
+		if assert.Len(uploaded, 2) {
+			assert.Equal("text/csv", uploaded[0].Get(chunkDesc.Fields().ByName("content_type")).String())
+			assert.Equal("application/json", uploaded[1].Get(chunkDesc.Fields().ByName("content_type")).String())
+		}
+
+		uploaded = nil
+		resp, _ = post(part{field: "file", fileName: "a.csv", contentType: "text/csv", raw: zip}) // not compatible
+		assert.Equal(http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		resp, _ = post(part{field: "file", fileName: "a.html", raw: []byte("<html><body>hi</body></html>")})
+		assert.Equal(http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		resp, _ = post(part{field: "file", fileName: "a.png", contentType: "image/png", raw: []byte("<html><body>hi</body></html>")}) // declared falsely
+		assert.Equal(http.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Nil(uploaded)
+
+		resp, _ = post(part{field: "note", raw: []byte("no file")})
+		assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+		// the following file not accepted, found during streaming
+		resp, _ = post(
+			part{field: "file", fileName: "a.txt", contentType: "text/plain", raw: content},
+			part{field: "file", fileName: "a.zip", contentType: "application/zip", raw: zip},
+		)
+		assert.Equal(http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		resp, body = post(part{field: "file", fileName: "large.txt", contentType: "text/plain", raw: bytes.Repeat(content, 11)})
+		assert.Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Contains(body, "exceeded the max bytes")
+	})
+
+	get := func(path, rangeHeader string) (*http.Response, []byte, error) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer resp.Body.Close()
+
+		raw, err := io.ReadAll(resp.Body)
+		return resp, raw, err
+	}
+
+	t.Run("download", func(t *testing.T) {
+		resp, raw, err := get("/v1/exports/report", "")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusOK, resp.StatusCode)
+			assert.Equal(content, raw)
+			assert.Equal("100", resp.Header.Get("Content-Length"))
+			assert.Equal("text/plain", resp.Header.Get("Content-Type"))
+			assert.Equal("bytes", resp.Header.Get("Accept-Ranges"))
+			assert.Equal(`attachment; filename=report.txt`, resp.Header.Get("Content-Disposition"))
+		}
+
+		resp, raw, err = get("/v1/exports/report", "bytes=20-39")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusPartialContent, resp.StatusCode)
+			assert.Equal(content[20:40], raw)
+			assert.Equal("bytes 20-39/100", resp.Header.Get("Content-Range"))
+		}
+
+		resp, raw, err = get("/v1/exports/report", "bytes=-10")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusPartialContent, resp.StatusCode)
+			assert.Equal(content[90:], raw)
+			assert.Equal("bytes 90-99/100", resp.Header.Get("Content-Range"))
+		}
+
+		resp, _, err = get("/v1/exports/report", "bytes=200-")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+			assert.Equal("bytes */100", resp.Header.Get("Content-Range"))
+		}
+
+		// the size unknown
+		resp, raw, err = get("/v1/exports/unsized", "bytes=10-19")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusPartialContent, resp.StatusCode)
+			assert.Equal(content[10:20], raw)
+			assert.Equal("bytes 10-19/*", resp.Header.Get("Content-Range"))
+		}
+
+		resp, raw, err = get("/v1/exports/unsized", "bytes=10-")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusOK, resp.StatusCode)
+			assert.Equal(content, raw)
+			assert.Equal("application/octet-stream", resp.Header.Get("Content-Type"))
+		}
+
+		// sought by the server
+		resp, raw, err = get("/v1/files/report", "bytes=50-59")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusPartialContent, resp.StatusCode)
+			assert.Equal(content[50:60], raw)
+			assert.Equal("50+10", sought)
+		}
+
+		resp, raw, err = get("/v1/exports/missing", "")
+		if assert.NoError(err) {
+			assert.Equal(http.StatusNotFound, resp.StatusCode)
+			assert.Contains(string(raw), "file not found")
+		}
+
+		// truncated if failed after started
+		_, _, err = get("/v1/exports/broken", "")
+		assert.Error(err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/bluekaki/pkg/pbutil"
+	"github.com/bluekaki/pkg/vv/internal/pb"
 
 	"github.com/golang/protobuf/proto"
 )
@@ -19,6 +20,10 @@ func queryUnescape(uri string) string {
 }
 
 func marshalJournal(journal proto.Message) interface{} {
+	if journal, ok := journal.(*pb.Journal); ok {
+		redactJournal(journal)
+	}
+
 	raw, _ := pbutil.ProtoMessage2JSON(journal)
 
 	if os.Getenv("MarshalJournal") == "true" {
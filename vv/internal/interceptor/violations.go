@@ -0,0 +1,132 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bluekaki/pkg/vv/internal/pb"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/validation"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validateMessage the request message, all the violations returned as google.rpc.BadRequest if collectAll
+func validateMessage(req interface{}, collectAll bool) error {
+	if collectAll {
+		if validator, ok := req.(proposal.AllValidator); ok {
+			err := validator.ValidateAll()
+			if err == nil {
+				return nil
+			}
+
+			if violations, ok := err.(validation.Violations); ok {
+				return violationsError(violations)
+			}
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if validator, ok := req.(proposal.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func violationsError(violations validation.Violations) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(violations))}
+	items := &pb.Violations{Items: make([]*pb.Violation, len(violations))}
+
+	for i, violation := range violations {
+		badRequest.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Error(),
+		}
+
+		items.Items[i] = &pb.Violation{
+			Field:       violation.Field,
+			Rule:        violation.Rule,
+			Expected:    violation.Expected,
+			Actual:      violation.Actual,
+			Description: violation.Error(),
+		}
+	}
+
+	s, _ := status.New(codes.InvalidArgument, violations.Error()).WithDetails(badRequest, items)
+	return s.Err()
+}
+
+// ViolationItem the element of the json array responded by gateway
+type ViolationItem struct {
+	Field       string `json:"field"`
+	Rule        string `json:"rule"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+	Description string `json:"description"`
+}
+
+// HTTPErrorHandler render the violations as a json array, the google.rpc details as an ErrorEnvelope,
+// others by runtime.DefaultHTTPErrorHandler; the desc of cuzerr localized by Accept-Language
+func HTTPErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	err = localizeError(err, r.Header.Get(acceptLanguage))
+
+	cause := err
+	httpErr, wrapped := err.(*runtime.HTTPStatusError)
+	if wrapped {
+		cause = httpErr.Err
+	}
+
+	s, ok := status.FromError(cause)
+	if !ok {
+		runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+		return
+	}
+
+	var violations *pb.Violations
+	for _, detail := range s.Details() {
+		if detail, ok := detail.(*pb.Violations); ok {
+			violations = detail
+		}
+	}
+
+	if violations == nil {
+		if envelope, retryAfter := errorEnvelope(s); envelope != nil {
+			httpStatus := runtime.HTTPStatusFromCode(s.Code())
+			if wrapped {
+				httpStatus = httpErr.HTTPStatus
+			}
+
+			writeErrorEnvelope(ctx, w, httpStatus, envelope, retryAfter)
+			return
+		}
+
+		runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+		return
+	}
+
+	items := make([]*ViolationItem, len(violations.Items))
+	for i, violation := range violations.Items {
+		items[i] = &ViolationItem{
+			Field:       violation.Field,
+			Rule:        violation.Rule,
+			Expected:    violation.Expected,
+			Actual:      violation.Actual,
+			Description: violation.Description,
+		}
+	}
+
+	forwardServerMetadata(ctx, w)
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(items)
+}
@@ -0,0 +1,49 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/example"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestViolationsGateway(t *testing.T) {
+	assert := assert.New(t)
+
+	newProfile := func() proto.Message { return new(example.Profile) }
+	update := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("updated"), nil
+	}
+
+	// nickname too short, tags[1] and home.city missing
+	const body = `{"nickname":"a","gender":"male","ratio":0.5,"retries":1,"tags":["ok",""],"home":{},"phone":"13800138000"}`
+	post := func(mux http.Handler) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/profiles", strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := post(newGatewayServer(t, ServerOptions{CollectAllViolations: true}, GatewayOptions{}, "/dummy.ProfileService/Update", "/v1/profiles", newProfile, update))
+	assert.Equal(http.StatusBadRequest, recorder.Code)
+
+	var items []*ViolationItem
+	assert.NoError(json.Unmarshal(recorder.Body.Bytes(), &items), recorder.Body.String())
+	if assert.Len(items, 3) {
+		assert.Equal(&ViolationItem{Field: "nickname", Rule: "min_len", Expected: "2", Actual: "a", Description: "nickname length illegal"}, items[0])
+		assert.Equal(&ViolationItem{Field: "tags[1]", Rule: "require", Description: "tags[1] required"}, items[1])
+		assert.Equal(&ViolationItem{Field: "home.city", Rule: "require", Description: "home.city required"}, items[2])
+	}
+
+	// the first violation only, in the plain error
+	recorder = post(newGatewayServer(t, ServerOptions{}, GatewayOptions{}, "/dummy.ProfileService/Update", "/v1/profiles", newProfile, update))
+	assert.Equal(http.StatusBadRequest, recorder.Code)
+	assert.Contains(recorder.Body.String(), `"message":"nickname length illegal"`)
+}
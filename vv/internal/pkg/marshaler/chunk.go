@@ -0,0 +1,95 @@
+package marshaler
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/bluekaki/pkg/errors"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/proto"
+)
+
+// ChunkMIME the mime of streaming upload and download, set by gateway only
+const ChunkMIME = "application/x-vv-chunk"
+
+const chunkFrameHeader = 5 // a zero tag and 4 bytes length
+
+// ChunkReader the request body read by chunks
+type ChunkReader interface {
+	// NextChunk fill the message with the next chunk, io.EOF at the end
+	NextChunk(message proto.Message) error
+}
+
+// NewChunkMarshaler decode the messages from ChunkReader, and encode the results of stream into frames of proto wire
+// split by SplitChunkFrame; others encoded in json, e.g. the error and the response of client streaming
+func NewChunkMarshaler() runtime.Marshaler {
+	return &chunk{jsonPb: *jsonPbMarshaler}
+}
+
+type chunk struct {
+	jsonPb
+}
+
+func (c *chunk) NewDecoder(reader io.Reader) runtime.Decoder {
+	return runtime.DecoderFunc(func(value interface{}) error {
+		source, ok := reader.(ChunkReader)
+		if !ok {
+			return errors.New("body not read by chunks")
+		}
+
+		message, ok := value.(proto.Message)
+		if !ok {
+			return errors.Errorf("unable to decode chunk into %T", value)
+		}
+
+		return source.NextChunk(message)
+	})
+}
+
+func (c *chunk) Marshal(value interface{}) ([]byte, error) {
+	result, ok := value.(map[string]interface{})
+	if !ok || len(result) != 1 {
+		return c.jsonPb.Marshal(value)
+	}
+
+	message, ok := result["result"].(proto.Message)
+	if !ok {
+		return c.jsonPb.Marshal(value)
+	}
+
+	raw, err := proto.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal chunk err")
+	}
+
+	frame := make([]byte, chunkFrameHeader, chunkFrameHeader+len(raw))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(raw)))
+	return append(frame, raw...), nil
+}
+
+// Delimiter the frames are length prefixed
+func (c *chunk) Delimiter() []byte {
+	return nil
+}
+
+// SplitChunkFrame split the first frame of buf, the wire of message returned with the bytes consumed;
+// ok is false if the frame not completed, or buf not started with a frame (e.g. the error in json) if n is -1
+func SplitChunkFrame(buf []byte) (message []byte, n int, ok bool) {
+	if len(buf) == 0 {
+		return nil, 0, false
+	}
+	if buf[0] != 0 {
+		return nil, -1, false
+	}
+	if len(buf) < chunkFrameHeader {
+		return nil, 0, false
+	}
+
+	size := int(binary.BigEndian.Uint32(buf[1:]))
+	if len(buf) < chunkFrameHeader+size {
+		return nil, 0, false
+	}
+
+	return buf[chunkFrameHeader : chunkFrameHeader+size], chunkFrameHeader + size, true
+}
@@ -0,0 +1,230 @@
+package multipart
+
+import (
+	stderr "errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+)
+
+const sniffLen = 512
+
+var (
+	// ErrNoFile no file part found in form-data
+	ErrNoFile = stderr.New("no file found in form-data")
+	// ErrTooLarge the files exceeded the max bytes
+	ErrTooLarge = stderr.New("files exceeded the max bytes")
+	// ErrContentType the content type of file not accepted
+	ErrContentType = stderr.New("content type of file not accepted")
+)
+
+// Chunk a piece of the file uploaded
+type Chunk struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Chunker read the file parts of multipart/form-data by chunks, without buffering the whole body; the form fields skipped
+type Chunker struct {
+	body      io.Closer
+	reader    *multipart.Reader
+	chunkSize int
+	maxBytes  int64
+	accept    []string
+
+	part        *multipart.Part
+	fileName    string
+	contentType string
+	pending     []byte // sniffed but not returned
+	read        int64
+	err         error
+}
+
+// NewChunker advance to the first file part and check its content type, ErrNoFile or ErrContentType (wrapped) returned if failed;
+// the content type sniffed from the first 512 bytes, the declared one taken if compatible with a generic sniffed one, e.g. text/csv of text/plain;
+// accept of e.g. image/png or image/* means all if empty, maxBytes <= 0 means unlimited
+func NewChunker(req *http.Request, chunkSize int, maxBytes int64, accept []string) (*Chunker, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, errors.Wrap(err, "read form-data err")
+	}
+
+	c := &Chunker{
+		body:      req.Body,
+		reader:    reader,
+		chunkSize: chunkSize,
+		maxBytes:  maxBytes,
+		accept:    accept,
+	}
+
+	if err = c.advance(); err != nil {
+		if err == io.EOF {
+			return nil, ErrNoFile
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// advance to the next file part, io.EOF if no more
+func (c *Chunker) advance() error {
+	c.part = nil
+	for {
+		part, err := c.reader.NextPart()
+		if err == io.EOF {
+			return io.EOF
+		}
+		if err != nil {
+			return errors.Wrap(err, "read form-data part err")
+		}
+
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		// the declared content type trusted only if compatible with the sniffed one
+		sniffed := make([]byte, sniffLen)
+		n, err := io.ReadFull(part, sniffed)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Wrap(err, "read form-data part err")
+		}
+
+		c.fileName, c.pending = part.FileName(), sniffed[:n]
+		c.contentType = http.DetectContentType(c.pending)
+		if declared := part.Header.Get("Content-Type"); compatible(c.contentType, declared) && Accepted(declared, c.accept) {
+			c.contentType = declared
+		}
+
+		if !Accepted(c.contentType, c.accept) {
+			return fmt.Errorf("%w: %s of %s", ErrContentType, c.contentType, c.fileName)
+		}
+
+		c.part = part
+		return nil
+	}
+}
+
+// Next the next chunk of files, io.EOF at the end; ErrTooLarge or ErrContentType (wrapped) returned if failed
+func (c *Chunker) Next() (*Chunk, error) {
+	for c.part != nil {
+		data := make([]byte, c.chunkSize)
+		n := copy(data, c.pending)
+		c.pending = c.pending[n:]
+
+		m, err := io.ReadFull(c.part, data[n:])
+		n += m
+
+		finished := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !finished {
+			return nil, errors.Wrap(err, "read form-data part err")
+		}
+
+		if c.read += int64(n); c.maxBytes > 0 && c.read > c.maxBytes {
+			c.part = nil
+			c.err = fmt.Errorf("%w of %d", ErrTooLarge, c.maxBytes)
+			return nil, c.err
+		}
+
+		chunk := &Chunk{FileName: c.fileName, ContentType: c.contentType, Data: data[:n]}
+		if finished {
+			c.err = c.advance() // returned by the next call, the chunk in hand returned first
+		}
+
+		if n > 0 {
+			return chunk, nil
+		}
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	return nil, io.EOF
+}
+
+// Err the error stopped reading, nil if not stopped or ended normally
+func (c *Chunker) Err() error {
+	if c.err == io.EOF {
+		return nil
+	}
+	return c.err
+}
+
+// Close the request body
+func (c *Chunker) Close() error {
+	return c.body.Close()
+}
+
+// Accepted whether the content type matched one of accept, e.g. image/png or image/*; all accepted if accept empty
+func Accepted(contentType string, accept []string) bool {
+	if len(accept) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range accept {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "*/*" || pattern == mediaType:
+			return true
+
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, pattern[:len(pattern)-1]):
+			return true
+		}
+	}
+	return false
+}
+
+// compatible whether the declared content type refines the generic sniffed one, e.g. application/json of text/plain,
+// docx of application/zip; not if the sniffed specific, e.g. image/png, or the same
+func compatible(sniffed, declared string) bool {
+	sniffedType, _, err := mime.ParseMediaType(sniffed)
+	if err != nil {
+		return false
+	}
+	declaredType, _, err := mime.ParseMediaType(declared)
+	if err != nil || declaredType == sniffedType {
+		return false
+	}
+
+	switch sniffedType {
+	case "text/plain": // html recognized by sniffing if it was
+		return textual(declaredType) && declaredType != "text/html"
+
+	case "application/zip":
+		return strings.HasSuffix(declaredType, "+zip") ||
+			strings.HasPrefix(declaredType, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(declaredType, "application/vnd.oasis.opendocument.") ||
+			declaredType == "application/java-archive"
+
+	case "application/octet-stream": // nothing recognized, but not text either
+		return strings.HasPrefix(declaredType, "application/") && !textual(declaredType)
+	}
+	return false
+}
+
+// textual whether the media type is of text, e.g. text/csv, application/json or application/atom+xml
+func textual(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-ndjson", "application/yaml", "application/x-yaml":
+		return true
+	}
+	return false
+}
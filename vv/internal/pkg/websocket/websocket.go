@@ -0,0 +1,423 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+)
+
+// opcodes of RFC 6455
+const (
+	continuationFrame = 0x0
+	TextMessage       = 0x1
+	BinaryMessage     = 0x2
+	CloseMessage      = 0x8
+	PingMessage       = 0x9
+	PongMessage       = 0xa
+)
+
+// close codes of RFC 6455
+const (
+	CloseNormalClosure   = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	acceptGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlLength = 125
+)
+
+// CloseError the close frame received
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (c *CloseError) Error() string {
+	return "websocket closed: " + c.Reason
+}
+
+// IsUpgrade whether the request asks for upgrading to websocket
+func IsUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket")
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade the request to websocket, with the subprotocol selected and the extra response header
+func Upgrade(w http.ResponseWriter, r *http.Request, subprotocol string, header http.Header) (*Conn, error) {
+	if !IsUpgrade(r) {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("not a websocket upgrade")
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		w.Header().Set("Sec-Websocket-Version", "13")
+		http.Error(w, "websocket version not supported", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket version not supported")
+	}
+
+	key := r.Header.Get("Sec-Websocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		http.Error(w, "websocket key illegal", http.StatusBadRequest)
+		return nil, errors.New("websocket key illegal")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("response writer not a hijacker")
+	}
+
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "hijack err")
+	}
+
+	var response strings.Builder
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	response.WriteString("Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		response.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	for k, values := range header {
+		for _, v := range values {
+			response.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+	response.WriteString("\r\n")
+
+	if _, err = netConn.Write([]byte(response.String())); err != nil {
+		netConn.Close()
+		return nil, errors.Wrap(err, "write upgrade response err")
+	}
+
+	return NewConn(netConn, rw.Reader, true), nil
+}
+
+// Dial a websocket of ws://host/path, the response of upgrade returned even if failed
+func Dial(rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "url %s illegal", rawURL)
+	}
+
+	netConn, err := net.DialTimeout("tcp", u.Host, time.Second*5)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "dial %s err", u.Host)
+	}
+
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-Websocket-Version", "13")
+	req.Header.Set("Sec-Websocket-Key", key)
+
+	if err = req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, errors.Wrap(err, "write upgrade request err")
+	}
+
+	reader := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, errors.Wrap(err, "read upgrade response err")
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-Websocket-Accept") != AcceptKey(key) {
+		netConn.Close()
+		return nil, resp, errors.Errorf("upgrade failed with %s", resp.Status)
+	}
+
+	return NewConn(netConn, reader, false), resp, nil
+}
+
+// AcceptKey the Sec-WebSocket-Accept of key
+func AcceptKey(key string) string {
+	digest := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// Conn a websocket connection, frames of server unmasked and the ones of client masked
+type Conn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	server    bool
+	readLimit int64
+	onPong    func()
+
+	writeMux sync.Mutex
+	closed   bool
+}
+
+// NewConn wrap an upgraded connection
+func NewConn(conn net.Conn, reader *bufio.Reader, server bool) *Conn {
+	if reader == nil {
+		reader = bufio.NewReader(conn)
+	}
+
+	return &Conn{conn: conn, reader: reader, server: server}
+}
+
+// SetReadLimit the max size of a message, exceeded closed with CloseMessageTooBig
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// SetPongHandler called on pong received, or on any frame received to keep alive
+func (c *Conn) SetPongHandler(handler func()) {
+	c.onPong = handler
+}
+
+// SetReadDeadline of the underlying connection
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close the underlying connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// ReadMessage a text or binary message, the control frames handled in place; a *CloseError returned if closed by peer
+func (c *Conn) ReadMessage() (messageType int, payload []byte, err error) {
+	messageType = -1
+	for {
+		fin, opcode, data, err := c.readFrame()
+		if err != nil {
+			return -1, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err = c.WriteControl(PongMessage, data, time.Now().Add(time.Second*5)); err != nil {
+				return -1, nil, err
+			}
+			continue
+
+		case PongMessage:
+			if c.onPong != nil {
+				c.onPong()
+			}
+			continue
+
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNormalClosure}
+			if len(data) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(data))
+				closeErr.Reason = string(data[2:])
+			}
+			c.WriteClose(closeErr.Code, "")
+			return -1, nil, closeErr
+
+		case TextMessage, BinaryMessage:
+			if messageType != -1 {
+				c.WriteClose(CloseProtocolError, "fragmented message interleaved")
+				return -1, nil, errors.New("fragmented message interleaved")
+			}
+			messageType = opcode
+
+		case continuationFrame:
+			if messageType == -1 {
+				c.WriteClose(CloseProtocolError, "unexpected continuation")
+				return -1, nil, errors.New("unexpected continuation")
+			}
+
+		default:
+			c.WriteClose(CloseProtocolError, "unknown opcode")
+			return -1, nil, errors.Errorf("unknown opcode %d", opcode)
+		}
+
+		if c.readLimit > 0 && int64(len(payload)+len(data)) > c.readLimit {
+			c.WriteClose(CloseMessageTooBig, "message too big")
+			return -1, nil, errors.Errorf("message exceeded %d bytes", c.readLimit)
+		}
+		payload = append(payload, data...)
+
+		if fin {
+			return messageType, payload, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(c.reader, header); err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		c.WriteClose(CloseProtocolError, "reserved bits set")
+		return false, 0, nil, errors.New("reserved bits set")
+	}
+	if masked != c.server {
+		c.WriteClose(CloseProtocolError, "mask mismatched")
+		return false, 0, nil, errors.New("mask mismatched")
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(c.reader, ext); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(ext))
+
+	case 127:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(c.reader, ext); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint64(ext))
+	}
+
+	if opcode >= CloseMessage && (length > maxControlLength || !fin) {
+		c.WriteClose(CloseProtocolError, "control frame illegal")
+		return false, 0, nil, errors.New("control frame illegal")
+	}
+	if length < 0 || (c.readLimit > 0 && length > c.readLimit) {
+		c.WriteClose(CloseMessageTooBig, "message too big")
+		return false, 0, nil, errors.Errorf("frame of %d bytes exceeded", length)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.reader, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	if c.onPong != nil && opcode != PongMessage {
+		c.onPong() // any frame proves the peer alive
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage a text or binary message in one frame, failed if not written before deadline
+func (c *Conn) WriteMessage(messageType int, payload []byte, deadline time.Time) error {
+	return c.writeFrame(messageType, payload, deadline)
+}
+
+// WriteControl a ping or pong frame
+func (c *Conn) WriteControl(messageType int, payload []byte, deadline time.Time) error {
+	if len(payload) > maxControlLength {
+		return errors.New("control payload too long")
+	}
+	return c.writeFrame(messageType, payload, deadline)
+}
+
+// WriteClose send a close frame, the frames after it dropped
+func (c *Conn) WriteClose(code int, reason string) error {
+	if len(reason) > maxControlLength-2 {
+		reason = reason[:maxControlLength-2]
+	}
+
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+
+	err := c.writeFrame(CloseMessage, payload, time.Now().Add(time.Second*5))
+
+	c.writeMux.Lock()
+	c.closed = true
+	c.writeMux.Unlock()
+
+	return err
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte, deadline time.Time) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|byte(opcode))
+
+	maskBit := byte(0)
+	if !c.server {
+		maskBit = 0x80
+	}
+
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[len(frame)-2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(length))
+	}
+
+	if c.server {
+		frame = append(frame, payload...)
+
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return errors.Wrap(err, "generate mask err")
+		}
+
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	}
+
+	c.writeMux.Lock()
+	defer c.writeMux.Unlock()
+
+	if c.closed {
+		return errors.New("websocket closed")
+	}
+
+	c.conn.SetWriteDeadline(deadline)
+	if _, err := c.conn.Write(frame); err != nil {
+		return errors.Wrap(err, "write frame err")
+	}
+	return nil
+}
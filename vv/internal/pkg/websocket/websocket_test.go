@@ -0,0 +1,107 @@
+package websocket
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// the example of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestConn(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, "echo", http.Header{"Journal-Id": []string{"journal-1"}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.SetReadLimit(1 << 17)
+		for {
+			messageType, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(messageType, payload, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/echo"
+
+	conn, resp, err := Dial(url, nil)
+	if !assert.NoError(err) {
+		return
+	}
+	defer conn.Close()
+	assert.Equal("echo", resp.Header.Get("Sec-Websocket-Protocol"))
+	assert.Equal("journal-1", resp.Header.Get("Journal-Id"))
+
+	// short, 16 bits and 64 bits length
+	for _, size := range []int{5, 300, 70000} {
+		payload := bytes.Repeat([]byte("a"), size)
+		assert.NoError(conn.WriteMessage(TextMessage, payload, time.Now().Add(time.Second)))
+
+		messageType, echo, err := conn.ReadMessage()
+		assert.NoError(err)
+		assert.Equal(TextMessage, messageType)
+		assert.Equal(payload, echo)
+	}
+
+	// ping answered by pong
+	pong := make(chan struct{}, 1)
+	conn.SetPongHandler(func() {
+		select {
+		case pong <- struct{}{}:
+		default:
+		}
+	})
+	assert.NoError(conn.WriteControl(PingMessage, []byte("ping"), time.Now().Add(time.Second)))
+	assert.NoError(conn.WriteMessage(BinaryMessage, []byte{1, 2}, time.Now().Add(time.Second)))
+
+	messageType, echo, err := conn.ReadMessage()
+	assert.NoError(err)
+	assert.Equal(BinaryMessage, messageType)
+	assert.Equal([]byte{1, 2}, echo)
+	assert.Len(pong, 1)
+
+	// exceeded the read limit of server
+	assert.NoError(conn.WriteMessage(TextMessage, bytes.Repeat([]byte("a"), 1<<17+1), time.Now().Add(time.Second)))
+	_, _, err = conn.ReadMessage()
+	if closeErr, ok := err.(*CloseError); assert.True(ok) {
+		assert.Equal(CloseMessageTooBig, closeErr.Code)
+	}
+}
+
+func TestUpgradeRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := httptest.NewRecorder()
+	_, err := Upgrade(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "", nil)
+	assert.Error(err)
+	assert.Equal(http.StatusBadRequest, recorder.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-Websocket-Version", "8")
+	assert.True(IsUpgrade(req))
+
+	recorder = httptest.NewRecorder()
+	_, err = Upgrade(recorder, req, "", nil)
+	assert.Error(err)
+	assert.Equal(http.StatusUpgradeRequired, recorder.Code)
+	assert.Equal("13", recorder.Header().Get("Sec-Websocket-Version"))
+}
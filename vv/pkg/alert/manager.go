@@ -0,0 +1,272 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+)
+
+const (
+	defaultWindow = time.Minute
+)
+
+// QuietHours alerts less severe than Severity are held during [Start, End) of a day, and summarized when it's over
+type QuietHours struct {
+	// Start the offset from midnight, e.g. 22*time.Hour
+	Start time.Duration
+	// End the offset from midnight, earlier than Start means across midnight, e.g. 8*time.Hour
+	End time.Duration
+	// Location default time.Local
+	Location *time.Location
+	// Severity the least severity still sent during quiet hours, default proposal.SeverityCritical
+	Severity proposal.Severity
+}
+
+func (q *QuietHours) contains(ts time.Time) bool {
+	if q == nil || q.Start == q.End {
+		return false
+	}
+
+	location := q.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	ts = ts.In(location)
+	offset := time.Duration(ts.Hour())*time.Hour + time.Duration(ts.Minute())*time.Minute + time.Duration(ts.Second())*time.Second
+
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+func (q *QuietHours) holds(ts time.Time, severity proposal.Severity) bool {
+	if !q.contains(ts) {
+		return false
+	}
+
+	least := q.Severity
+	if least == 0 {
+		least = proposal.SeverityCritical
+	}
+	return severity < least
+}
+
+// Config customize the manager, zero value means the default
+type Config struct {
+	// Window the repeats of an alert suppressed within it, and summarized at the end of it; default 1m
+	Window time.Duration
+	// MinSeverity alerts less severe are dropped, default none dropped
+	MinSeverity proposal.Severity
+	// QuietHours hold the less severe alerts during quiet hours, default none
+	QuietHours *QuietHours
+}
+
+type group struct {
+	sentAt     time.Time
+	suppressed int
+	since      time.Time
+	latest     *proposal.AlertMessage
+}
+
+// Manager deduplicate, throttle and aggregate alerts between interceptors and the NotifyHandler.
+// Alerts are fingerprinted by method, bzCode and origin; the first one in a window sent immediately,
+// the repeats suppressed and summarized as one alert with occurrences at the end of window.
+type Manager struct {
+	notify proposal.NotifyHandler
+	config Config
+	now    func() time.Time
+
+	mux    sync.Mutex
+	groups map[string]*group
+	closed bool
+
+	done chan struct{}
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewManager create a manager of notify, use Manager.Notify as the NotifyHandler of builders, call Close on shutdown to flush the summaries
+func NewManager(notify proposal.NotifyHandler, config *Config) *Manager {
+	return newManager(notify, config, time.Now)
+}
+
+func newManager(notify proposal.NotifyHandler, config *Config, now func() time.Time) *Manager {
+	if notify == nil {
+		panic("notify required")
+	}
+
+	m := &Manager{
+		notify: notify,
+		now:    now,
+		groups: make(map[string]*group),
+		done:   make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+
+	if config != nil {
+		m.config = *config
+	}
+	if m.config.Window <= 0 {
+		m.config.Window = defaultWindow
+	}
+
+	go m.ticker()
+	return m
+}
+
+// Fingerprint the identity of an alert, alerts with the same fingerprint are deduplicated
+func Fingerprint(alert *proposal.AlertMessage) string {
+	return strings.Join([]string{alert.Method, strconv.Itoa(alert.BzCode), alert.Origin}, "|")
+}
+
+func severityOf(alert *proposal.AlertMessage) proposal.Severity {
+	if alert.Severity == 0 {
+		return proposal.SeverityError
+	}
+	return alert.Severity
+}
+
+// Notify the proposal.NotifyHandler
+func (m *Manager) Notify(alert *proposal.AlertMessage) {
+	if alert == nil {
+		return
+	}
+
+	severity := severityOf(alert)
+	if severity < m.config.MinSeverity {
+		return
+	}
+
+	now := m.now()
+	fingerprint := Fingerprint(alert)
+
+	m.mux.Lock()
+	if m.closed {
+		m.mux.Unlock()
+		m.notify(alert) // flushed already, send it as it is
+		return
+	}
+
+	g, ok := m.groups[fingerprint]
+	if !ok {
+		g = new(group)
+		m.groups[fingerprint] = g
+	}
+
+	if (ok && now.Sub(g.sentAt) < m.config.Window) || m.config.QuietHours.holds(now, severity) {
+		if g.suppressed == 0 {
+			g.since = now
+		}
+		g.suppressed++
+		g.latest = alert
+		m.mux.Unlock()
+		return
+	}
+
+	g.sentAt = now
+	m.mux.Unlock()
+
+	m.notify(alert)
+}
+
+func (m *Manager) ticker() {
+	defer close(m.done)
+
+	interval := m.config.Window / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	if interval <= 0 {
+		interval = m.config.Window
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+
+		case <-ticker.C:
+			m.flush()
+		}
+	}
+}
+
+// flush send the summaries of windows ended
+func (m *Manager) flush() {
+	m.mux.Lock()
+	summaries := m.summaries(m.now(), false)
+	m.mux.Unlock()
+
+	for _, alert := range summaries {
+		m.notify(alert)
+	}
+}
+
+// summaries of windows ended, or all of them if force; the caller should hold the lock
+func (m *Manager) summaries(now time.Time, force bool) []*proposal.AlertMessage {
+	var summaries []*proposal.AlertMessage
+	for fingerprint, g := range m.groups {
+		if !force && now.Sub(g.sentAt) < m.config.Window {
+			continue
+		}
+
+		if g.suppressed == 0 {
+			delete(m.groups, fingerprint)
+			continue
+		}
+
+		if !force && m.config.QuietHours.holds(now, severityOf(g.latest)) {
+			continue
+		}
+
+		summaries = append(summaries, summary(g, now))
+		g.sentAt = now
+		g.suppressed = 0
+		g.latest = nil
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Timestamp.Before(summaries[j].Timestamp)
+	})
+	return summaries
+}
+
+func summary(g *group, now time.Time) *proposal.AlertMessage {
+	more := "more "
+	if g.sentAt.IsZero() { // the first one held in quiet hours
+		more = ""
+	}
+
+	alert := *g.latest
+	alert.Occurrences = g.suppressed
+	alert.ErrorVerbose = fmt.Sprintf("%d %soccurrences in %s since %s, the latest one:\n%s",
+		g.suppressed, more, now.Sub(g.since).Round(time.Second), g.since.Format(time.RFC3339), alert.ErrorVerbose)
+	return &alert
+}
+
+// Close stop the ticker and send all pending summaries, quiet hours ignored; alerts after closed are sent directly
+func (m *Manager) Close() {
+	m.once.Do(func() {
+		close(m.stop)
+		<-m.done
+
+		m.mux.Lock()
+		m.closed = true
+		summaries := m.summaries(m.now(), true)
+		m.mux.Unlock()
+
+		for _, alert := range summaries {
+			m.notify(alert)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package alert
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type clock struct {
+	sync.Mutex
+	ts time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.ts
+}
+
+func (c *clock) add(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.ts = c.ts.Add(d)
+}
+
+type recorder struct {
+	sync.Mutex
+	alerts []*proposal.AlertMessage
+}
+
+func (r *recorder) notify(alert *proposal.AlertMessage) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.alerts = append(r.alerts, alert)
+}
+
+func (r *recorder) sent() []*proposal.AlertMessage {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]*proposal.AlertMessage(nil), r.alerts...)
+}
+
+func alert(bzCode int, severity proposal.Severity) *proposal.AlertMessage {
+	return &proposal.AlertMessage{
+		Method:       "/dummy.DummyService/Echo",
+		BzCode:       bzCode,
+		Origin:       "main.echo main.go:12",
+		Severity:     severity,
+		ErrorVerbose: "redis: connection refused",
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &clock{ts: time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)}
+	recorder := new(recorder)
+	manager := newManager(recorder.notify, &Config{Window: time.Hour}, clock.now)
+	defer manager.Close()
+
+	for i := 0; i < 5; i++ {
+		manager.Notify(alert(10001, 0))
+		clock.add(time.Second)
+	}
+	manager.Notify(alert(10002, 0))
+	assert.Len(recorder.sent(), 2)
+
+	manager.flush() // window not ended
+	assert.Len(recorder.sent(), 2)
+
+	clock.add(time.Hour)
+	manager.flush()
+
+	sent := recorder.sent()
+	if assert.Len(sent, 3) {
+		assert.Equal(4, sent[2].Occurrences)
+		assert.Equal(10001, sent[2].BzCode)
+		assert.Contains(sent[2].ErrorVerbose, "4 more occurrences in 1h0m4s since 2021-01-01T12:00:01Z")
+		assert.Contains(sent[2].ErrorVerbose, "redis: connection refused")
+	}
+
+	clock.add(time.Hour)
+	manager.flush() // idle groups released
+	manager.Notify(alert(10001, 0))
+	assert.Len(recorder.sent(), 4)
+}
+
+func TestMinSeverity(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := new(recorder)
+	manager := NewManager(recorder.notify, &Config{MinSeverity: proposal.SeverityError})
+	defer manager.Close()
+
+	manager.Notify(alert(10001, proposal.SeverityWarning))
+	manager.Notify(alert(10002, 0))
+	manager.Notify(alert(10003, proposal.SeverityCritical))
+
+	sent := recorder.sent()
+	if assert.Len(sent, 2) {
+		assert.Equal(10002, sent[0].BzCode)
+		assert.Equal(10003, sent[1].BzCode)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &clock{ts: time.Date(2021, 1, 1, 23, 0, 0, 0, time.UTC)}
+	recorder := new(recorder)
+	manager := newManager(recorder.notify, &Config{
+		Window: time.Minute,
+		QuietHours: &QuietHours{
+			Start:    22 * time.Hour,
+			End:      8 * time.Hour,
+			Location: time.UTC,
+		},
+	}, clock.now)
+	defer manager.Close()
+
+	manager.Notify(alert(10001, proposal.SeverityError))
+	manager.Notify(alert(10001, proposal.SeverityError))
+	manager.Notify(alert(10002, proposal.SeverityCritical))
+
+	sent := recorder.sent()
+	if assert.Len(sent, 1) {
+		assert.Equal(10002, sent[0].BzCode)
+	}
+
+	clock.add(time.Hour * 2) // 01:00 still quiet
+	manager.flush()
+	assert.Len(recorder.sent(), 1)
+
+	clock.add(time.Hour * 8) // 09:00
+	manager.flush()
+
+	sent = recorder.sent()
+	if assert.Len(sent, 2) {
+		assert.Equal(10001, sent[1].BzCode)
+		assert.Equal(2, sent[1].Occurrences)
+		assert.Contains(sent[1].ErrorVerbose, "2 occurrences in 10h0m0s")
+	}
+}
+
+func TestFlushOnClose(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &clock{ts: time.Date(2021, 1, 1, 23, 0, 0, 0, time.UTC)}
+	recorder := new(recorder)
+	manager := newManager(recorder.notify, &Config{
+		Window:     time.Hour,
+		QuietHours: &QuietHours{Start: 22 * time.Hour, End: 8 * time.Hour, Location: time.UTC},
+	}, clock.now)
+
+	manager.Notify(alert(10001, proposal.SeverityCritical))
+	manager.Notify(alert(10001, proposal.SeverityCritical)) // suppressed by window
+	manager.Notify(alert(10002, proposal.SeverityWarning))  // held by quiet hours
+	manager.Notify(alert(10002, proposal.SeverityWarning))
+	manager.Notify(alert(10002, proposal.SeverityWarning))
+	assert.Len(recorder.sent(), 1)
+
+	manager.Close()
+	manager.Close()
+
+	sent := recorder.sent()
+	if assert.Len(sent, 3) {
+		occurrences := map[int]int{sent[1].BzCode: sent[1].Occurrences, sent[2].BzCode: sent[2].Occurrences}
+		assert.Equal(map[int]int{10001: 1, 10002: 3}, occurrences)
+	}
+
+	manager.Notify(alert(10001, proposal.SeverityCritical)) // sent directly after closed
+	assert.Len(recorder.sent(), 4)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(Fingerprint(alert(10001, proposal.SeverityWarning)), Fingerprint(alert(10001, proposal.SeverityCritical)))
+	assert.NotEqual(Fingerprint(alert(10001, 0)), Fingerprint(alert(10002, 0)))
+
+	other := alert(10001, 0)
+	other.Origin = "main.ping main.go:20"
+	assert.NotEqual(Fingerprint(alert(10001, 0)), Fingerprint(other))
+}
+
+func TestSeverityJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	message := &proposal.AlertMessage{Severity: proposal.SeverityCritical}
+	assert.Contains(string(message.Marshal()), `"severity":"critical"`)
+
+	decoded := new(proposal.AlertMessage)
+	assert.NoError(decoded.Unmarshal(message.Marshal()))
+	assert.Equal(proposal.SeverityCritical, decoded.Severity)
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/internal/interceptor"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/go-redis/redis/v7"
+)
+
+const defaultCapacity = 10000
+
+// Invalidate drop the cached responses of methods, e.g. called by the handler updated the resource;
+// the store should be the one shared with gateway, e.g. a RedisStore
+func Invalidate(store proposal.CacheStore, fullMethods ...string) error {
+	if store == nil {
+		return errors.New("cache store required")
+	}
+
+	return interceptor.InvalidateCache(store, fullMethods...)
+}
+
+var _ proposal.CacheStore = (*LRUStore)(nil)
+
+// LRUStore keep the responses in memory, the least recently used evicted if capacity exceeded, only for a single instance
+type LRUStore struct {
+	now      func() time.Time
+	capacity int
+
+	mux      sync.Mutex
+	elements map[string]*list.Element
+	lru      *list.List
+}
+
+type item struct {
+	key      string
+	value    []byte
+	expireAt time.Time // zero if never expired
+}
+
+// NewLRUStore create a LRUStore holds capacity (default 10000) items at most
+func NewLRUStore(capacity int) *LRUStore {
+	return newLRUStore(capacity, time.Now)
+}
+
+func newLRUStore(capacity int, now func() time.Time) *LRUStore {
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+
+	return &LRUStore{
+		now:      now,
+		capacity: capacity,
+		elements: make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (l *LRUStore) Get(key string) ([]byte, bool, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	element, ok := l.elements[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := element.Value.(*item)
+	if !item.expireAt.IsZero() && !l.now().Before(item.expireAt) {
+		l.lru.Remove(element)
+		delete(l.elements, key)
+		return nil, false, nil
+	}
+
+	l.lru.MoveToFront(element)
+	return item.value, true, nil
+}
+
+func (l *LRUStore) Set(key string, value []byte, ttl time.Duration) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = l.now().Add(ttl)
+	}
+
+	if element, ok := l.elements[key]; ok {
+		item := element.Value.(*item)
+		item.value = append([]byte{}, value...)
+		item.expireAt = expireAt
+
+		l.lru.MoveToFront(element)
+		return nil
+	}
+
+	l.elements[key] = l.lru.PushFront(&item{
+		key:      key,
+		value:    append([]byte{}, value...),
+		expireAt: expireAt,
+	})
+
+	for l.lru.Len() > l.capacity {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.elements, oldest.Value.(*item).key)
+	}
+	return nil
+}
+
+func (l *LRUStore) Close() error {
+	return nil
+}
+
+// Redis the commands used by RedisStore, e.g. *redis.Client
+type Redis interface {
+	// Close the redis
+	Close() error
+	// Set by key
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	// Get by key
+	Get(key string) *redis.StringCmd
+}
+
+var _ proposal.CacheStore = (*RedisStore)(nil)
+
+// RedisStore keep the responses in redis, shared by instances and the servers invalidating them
+type RedisStore struct {
+	redis Redis
+}
+
+// NewRedisStore create a RedisStore, the redis closed along with the store
+func NewRedisStore(redis Redis) *RedisStore {
+	if redis == nil {
+		panic("redis required")
+	}
+
+	return &RedisStore{redis: redis}
+}
+
+func (r *RedisStore) Get(key string) ([]byte, bool, error) {
+	value, err := r.redis.Get(key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "get %s err", key)
+	}
+
+	return value, true, nil
+}
+
+func (r *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	if err := r.redis.Set(key, value, ttl).Err(); err != nil {
+		return errors.Wrapf(err, "set %s err", key)
+	}
+	return nil
+}
+
+func (r *RedisStore) Close() error {
+	return r.redis.Close()
+}
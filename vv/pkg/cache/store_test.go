@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRedis struct {
+	sync.Mutex
+	values map[string][]byte
+}
+
+func (f *fakeRedis) Close() error {
+	return nil
+}
+
+func (f *fakeRedis) Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	f.values[key] = value.([]byte)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) Get(key string) *redis.StringCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	value, ok := f.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(string(value), nil)
+}
+
+func testStore(t *testing.T, store proposal.CacheStore) {
+	assert := assert.New(t)
+
+	_, ok, err := store.Get("article:1")
+	assert.NoError(err)
+	assert.False(ok)
+
+	assert.NoError(store.Set("article:1", []byte("hello"), time.Minute))
+	value, ok, err := store.Get("article:1")
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal([]byte("hello"), value)
+
+	version, _, _ := store.Get("vv:cache:version:/dummy.ArticleService/Get")
+	assert.NoError(Invalidate(store, "/dummy.ArticleService/Get"))
+	bumped, ok, _ := store.Get("vv:cache:version:/dummy.ArticleService/Get")
+	assert.True(ok)
+	assert.NotEqual(version, bumped)
+
+	assert.NoError(store.Close())
+}
+
+func TestLRUStore(t *testing.T) {
+	testStore(t, NewLRUStore(0))
+
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newLRUStore(2, func() time.Time { return now })
+
+	store.Set("a", []byte("1"), time.Minute)
+	store.Set("b", []byte("2"), 0)
+	store.Get("a")
+	store.Set("c", []byte("3"), 0) // b evicted
+
+	_, ok, _ := store.Get("b")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute) // a expired
+	_, ok, _ = store.Get("a")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("c")
+	assert.True(t, ok)
+}
+
+func TestRedisStore(t *testing.T) {
+	testStore(t, NewRedisStore(&fakeRedis{values: make(map[string][]byte)}))
+}
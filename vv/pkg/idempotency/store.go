@@ -0,0 +1,187 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/go-redis/redis/v7"
+)
+
+const sweepInterval = time.Minute
+
+var _ proposal.IdempotencyStore = (*MemoryStore)(nil)
+
+// MemoryStore keep the responses in memory, only for a single instance
+type MemoryStore struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	now    func() time.Time
+
+	mux     sync.Mutex
+	records map[string]*record
+}
+
+type record struct {
+	response []byte // nil if in flight
+	expireAt time.Time
+}
+
+// NewMemoryStore create a MemoryStore, the expired records swept every minute
+func NewMemoryStore() *MemoryStore {
+	return newMemoryStore(time.Now)
+}
+
+func newMemoryStore(now func() time.Time) *MemoryStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &MemoryStore{
+		ctx:     ctx,
+		cancel:  cancel,
+		now:     now,
+		records: make(map[string]*record),
+	}
+
+	go store.sweep()
+	return store
+}
+
+func (m *MemoryStore) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+
+		case <-ticker.C:
+			now := m.now()
+
+			m.mux.Lock()
+			for key, record := range m.records {
+				if !now.Before(record.expireAt) {
+					delete(m.records, key)
+				}
+			}
+			m.mux.Unlock()
+		}
+	}
+}
+
+func (m *MemoryStore) Acquire(key string, lockTTL time.Duration) (bool, []byte, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	now := m.now()
+	if record, ok := m.records[key]; ok && now.Before(record.expireAt) {
+		return false, record.response, nil
+	}
+
+	m.records[key] = &record{expireAt: now.Add(lockTTL)}
+	return true, nil, nil
+}
+
+func (m *MemoryStore) Complete(key string, response []byte, ttl time.Duration) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.records[key] = &record{
+		response: append([]byte{}, response...),
+		expireAt: m.now().Add(ttl),
+	}
+	return nil
+}
+
+func (m *MemoryStore) Release(key string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if record, ok := m.records[key]; ok && record.response == nil {
+		delete(m.records, key)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Close() error {
+	m.cancel()
+	return nil
+}
+
+// Redis the commands used by RedisStore, e.g. *redis.Client
+type Redis interface {
+	// Close the redis
+	Close() error
+	// SetNX set if not exists
+	SetNX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	// Set by key
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	// Get by key
+	Get(key string) *redis.StringCmd
+	// Del by keys
+	Del(keys ...string) *redis.IntCmd
+}
+
+var _ proposal.IdempotencyStore = (*RedisStore)(nil)
+
+const (
+	inFlight  = "0"
+	completed = "1"
+)
+
+// RedisStore keep the responses in redis, shared by instances
+type RedisStore struct {
+	redis Redis
+}
+
+// NewRedisStore create a RedisStore, the redis closed along with the store
+func NewRedisStore(redis Redis) *RedisStore {
+	if redis == nil {
+		panic("redis required")
+	}
+
+	return &RedisStore{redis: redis}
+}
+
+func (r *RedisStore) Acquire(key string, lockTTL time.Duration) (bool, []byte, error) {
+	acquired, err := r.redis.SetNX(key, inFlight, lockTTL).Result()
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "setnx %s err", key)
+	}
+	if acquired {
+		return true, nil, nil
+	}
+
+	value, err := r.redis.Get(key).Result()
+	if err == redis.Nil { // expired just now
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "get %s err", key)
+	}
+
+	if len(value) > 0 && value[:1] == completed {
+		return false, []byte(value[1:]), nil
+	}
+	return false, nil, nil
+}
+
+func (r *RedisStore) Complete(key string, response []byte, ttl time.Duration) error {
+	if err := r.redis.Set(key, completed+string(response), ttl).Err(); err != nil {
+		return errors.Wrapf(err, "set %s err", key)
+	}
+	return nil
+}
+
+func (r *RedisStore) Release(key string) error {
+	if err := r.redis.Del(key).Err(); err != nil {
+		return errors.Wrapf(err, "del %s err", key)
+	}
+	return nil
+}
+
+func (r *RedisStore) Close() error {
+	return r.redis.Close()
+}
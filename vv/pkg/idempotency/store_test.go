@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRedis struct {
+	sync.Mutex
+	values map[string]string
+}
+
+func (f *fakeRedis) Close() error {
+	return nil
+}
+
+func (f *fakeRedis) SetNX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.values[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	f.values[key] = value.(string)
+	return redis.NewBoolResult(true, nil)
+}
+
+func (f *fakeRedis) Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	f.values[key] = value.(string)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) Get(key string) *redis.StringCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	value, ok := f.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (f *fakeRedis) Del(keys ...string) *redis.IntCmd {
+	f.Lock()
+	defer f.Unlock()
+
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func testStore(t *testing.T, store proposal.IdempotencyStore) {
+	assert := assert.New(t)
+
+	acquired, response, err := store.Acquire("order:1", time.Minute)
+	assert.NoError(err)
+	assert.True(acquired)
+	assert.Nil(response)
+
+	acquired, response, err = store.Acquire("order:1", time.Minute) // in flight
+	assert.NoError(err)
+	assert.False(acquired)
+	assert.Nil(response)
+
+	assert.NoError(store.Release("order:1"))
+	acquired, _, _ = store.Acquire("order:1", time.Minute)
+	assert.True(acquired)
+
+	assert.NoError(store.Complete("order:1", []byte("created"), time.Hour))
+	acquired, response, err = store.Acquire("order:1", time.Minute)
+	assert.NoError(err)
+	assert.False(acquired)
+	assert.Equal([]byte("created"), response)
+
+	assert.NoError(store.Release("order:1")) // completed one kept by memory store
+	assert.NoError(store.Close())
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore(func() time.Time { return now })
+	defer store.Close()
+
+	acquired, _, _ := store.Acquire("order:2", time.Minute)
+	assert.True(t, acquired)
+
+	now = now.Add(time.Minute) // lock expired
+	acquired, _, _ = store.Acquire("order:2", time.Minute)
+	assert.True(t, acquired)
+}
+
+func TestRedisStore(t *testing.T) {
+	testStore(t, NewRedisStore(&fakeRedis{values: make(map[string]string)}))
+}
@@ -0,0 +1,239 @@
+package journal
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/sequential"
+	"github.com/bluekaki/pkg/vv/proposal"
+)
+
+var _ proposal.JournalSink = (*FileSink)(nil)
+
+// FileSink append journals into the files of sequential
+type FileSink struct {
+	sequential sequential.Sequential
+}
+
+// NewFileSink create a sink of sequential, the sequential closed along with the sink
+func NewFileSink(sequential sequential.Sequential) *FileSink {
+	if sequential == nil {
+		panic("sequential required")
+	}
+
+	return &FileSink{sequential: sequential}
+}
+
+func (f *FileSink) Write(journals []*proposal.Journal) error {
+	for _, journal := range journals {
+		if _, err := f.sequential.Write(journal.Raw); err != nil {
+			return errors.Wrapf(err, "write journal %s into sequential err", journal.ID)
+		}
+	}
+	return nil
+}
+
+func (f *FileSink) Close() error {
+	f.sequential.Close()
+	return nil
+}
+
+// Producer publish messages into a kafka-like queue, e.g. wrap a kafka producer
+type Producer interface {
+	Produce(topic string, key, value []byte) error
+	Close() error
+}
+
+var _ proposal.JournalSink = (*QueueSink)(nil)
+
+// QueueSink produce journals into the topic, keyed by journal id so the journals of a request kept in one partition
+type QueueSink struct {
+	producer Producer
+	topic    string
+}
+
+// NewQueueSink create a sink of producer, the producer closed along with the sink
+func NewQueueSink(producer Producer, topic string) *QueueSink {
+	if producer == nil {
+		panic("producer required")
+	}
+	if topic == "" {
+		panic("topic required")
+	}
+
+	return &QueueSink{producer: producer, topic: topic}
+}
+
+func (q *QueueSink) Write(journals []*proposal.Journal) error {
+	for _, journal := range journals {
+		if err := q.producer.Produce(q.topic, []byte(journal.ID), journal.Raw); err != nil {
+			return errors.Wrapf(err, "produce journal %s into topic %s err", journal.ID, q.topic)
+		}
+	}
+	return nil
+}
+
+func (q *QueueSink) Close() error {
+	return q.producer.Close()
+}
+
+// ErrQueueClosed the local queue has closed
+var ErrQueueClosed = errors.New("local queue has closed")
+
+// Message a message of local queue
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+var _ Producer = (*LocalQueue)(nil)
+
+// LocalQueue an in-process stand-in of kafka, messages partitioned by the hash of key and consumed by offset
+type LocalQueue struct {
+	mux        sync.RWMutex
+	partitions int
+	topics     map[string][][]*Message
+	closed     bool
+}
+
+// NewLocalQueue create a local queue with partitions of each topic, at least 1
+func NewLocalQueue(partitions int) *LocalQueue {
+	if partitions < 1 {
+		partitions = 1
+	}
+
+	return &LocalQueue{
+		partitions: partitions,
+		topics:     make(map[string][][]*Message),
+	}
+}
+
+func (l *LocalQueue) Produce(topic string, key, value []byte) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.closed {
+		return ErrQueueClosed
+	}
+
+	partitions, ok := l.topics[topic]
+	if !ok {
+		partitions = make([][]*Message, l.partitions)
+		l.topics[topic] = partitions
+	}
+
+	hash := fnv.New32a()
+	hash.Write(key)
+	partition := int(hash.Sum32() % uint32(l.partitions))
+
+	partitions[partition] = append(partitions[partition], &Message{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    int64(len(partitions[partition])),
+		Key:       key,
+		Value:     value,
+	})
+	return nil
+}
+
+// Consume at most max messages of the partition from offset, max <= 0 means all
+func (l *LocalQueue) Consume(topic string, partition int, offset int64, max int) []*Message {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	partitions := l.topics[topic]
+	if partition < 0 || partition >= len(partitions) || offset < 0 || offset >= int64(len(partitions[partition])) {
+		return nil
+	}
+
+	messages := partitions[partition][offset:]
+	if max > 0 && len(messages) > max {
+		messages = messages[:max]
+	}
+
+	consumed := make([]*Message, len(messages))
+	copy(consumed, messages)
+	return consumed
+}
+
+// Partitions the number of partitions of each topic
+func (l *LocalQueue) Partitions() int {
+	return l.partitions
+}
+
+// Close reject the subsequent messages, the produced ones still could be consumed
+func (l *LocalQueue) Close() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.closed = true
+	return nil
+}
+
+var _ proposal.JournalSink = (*RingBuffer)(nil)
+
+// RingBuffer keep the latest journals in memory, for tests
+type RingBuffer struct {
+	mux      sync.Mutex
+	journals []*proposal.Journal
+	next     int
+	full     bool
+}
+
+// NewRingBuffer create a ring buffer keeps at most capacity journals
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		panic("capacity should be positive")
+	}
+
+	return &RingBuffer{journals: make([]*proposal.Journal, capacity)}
+}
+
+func (r *RingBuffer) Write(journals []*proposal.Journal) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for _, journal := range journals {
+		r.journals[r.next] = journal
+		if r.next = (r.next + 1) % len(r.journals); r.next == 0 {
+			r.full = true
+		}
+	}
+	return nil
+}
+
+func (r *RingBuffer) Close() error {
+	return nil
+}
+
+// Journals the kept journals, the oldest first
+func (r *RingBuffer) Journals() []*proposal.Journal {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if !r.full {
+		journals := make([]*proposal.Journal, r.next)
+		copy(journals, r.journals[:r.next])
+		return journals
+	}
+
+	journals := make([]*proposal.Journal, 0, len(r.journals))
+	journals = append(journals, r.journals[r.next:]...)
+	return append(journals, r.journals[:r.next]...)
+}
+
+// Reset drop all journals
+func (r *RingBuffer) Reset() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for i := range r.journals {
+		r.journals[i] = nil
+	}
+	r.next = 0
+	r.full = false
+}
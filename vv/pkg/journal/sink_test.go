@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bluekaki/pkg/sequential"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func journals(ids ...string) []*proposal.Journal {
+	journals := make([]*proposal.Journal, len(ids))
+	for i, id := range ids {
+		journals[i] = &proposal.Journal{ID: id, Raw: []byte(fmt.Sprintf(`{"id":%q}`, id))}
+	}
+	return journals
+}
+
+func TestRingBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	ring := NewRingBuffer(3)
+	assert.Empty(ring.Journals())
+
+	assert.NoError(ring.Write(journals("a", "b")))
+	assert.Len(ring.Journals(), 2)
+
+	assert.NoError(ring.Write(journals("c", "d", "e")))
+
+	var ids []string
+	for _, journal := range ring.Journals() {
+		ids = append(ids, journal.ID)
+	}
+	assert.Equal([]string{"c", "d", "e"}, ids)
+
+	ring.Reset()
+	assert.Empty(ring.Journals())
+}
+
+func TestLocalQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := NewLocalQueue(4)
+	sink := NewQueueSink(queue, "journal")
+
+	assert.NoError(sink.Write(journals("a", "b", "a", "c", "a")))
+
+	total, partitions := 0, make(map[string]map[int]bool)
+	for partition := 0; partition < queue.Partitions(); partition++ {
+		messages := queue.Consume("journal", partition, 0, 0)
+		total += len(messages)
+
+		for i, message := range messages {
+			assert.Equal(int64(i), message.Offset)
+			assert.Equal(partition, message.Partition)
+
+			if partitions[string(message.Key)] == nil {
+				partitions[string(message.Key)] = make(map[int]bool)
+			}
+			partitions[string(message.Key)][partition] = true
+		}
+	}
+	assert.Len(partitions["a"], 1) // the journals of a request kept in one partition
+	assert.Equal(5, total)
+
+	assert.Nil(queue.Consume("journal", 4, 0, 0))
+	assert.Nil(queue.Consume("unknown", 0, 0, 0))
+
+	assert.NoError(sink.Close())
+	assert.Equal(ErrQueueClosed, queue.Produce("journal", nil, nil))
+}
+
+func TestLocalQueueConsume(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := NewLocalQueue(1)
+	for i := 0; i < 5; i++ {
+		assert.NoError(queue.Produce("journal", nil, []byte{byte(i)}))
+	}
+
+	messages := queue.Consume("journal", 0, 1, 2)
+	if assert.Len(messages, 2) {
+		assert.Equal(int64(1), messages[0].Offset)
+		assert.Equal([]byte{2}, messages[1].Value)
+	}
+	assert.Len(queue.Consume("journal", 0, 3, 0), 2)
+	assert.Nil(queue.Consume("journal", 0, 5, 0))
+}
+
+func TestFileSink(t *testing.T) {
+	assert := assert.New(t)
+
+	files := sequential.New(t.TempDir(), zap.NewNop())
+	sink := NewFileSink(files)
+
+	assert.NoError(sink.Write(journals("a", "b")))
+
+	raw, err := files.Get(2)
+	assert.NoError(err)
+	assert.Equal(`{"id":"b"}`, string(raw))
+
+	assert.NoError(sink.Close())
+	_, err = files.Get(1)
+	assert.Equal(sequential.ErrClosed, err)
+}
@@ -0,0 +1,247 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+)
+
+const (
+	defaultRefresh   = time.Minute * 10
+	minRefresh       = time.Second * 30 // refetch for an unknown kid at most once in it
+	jwksFetchTimeout = time.Second * 5
+	maxJWKSSize      = 1 << 20
+)
+
+// JWKS the keys of a JSON Web Key Set document, loaded from a file or an http(s) url; refetched every refresh interval,
+// or for an unknown kid, so that rotated keys are picked up; the last keys kept if refetch failed
+type JWKS struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	now     func() time.Time
+
+	fetching  sync.Mutex // one refetch at a time
+	mux       sync.RWMutex
+	keys      []*jwk
+	fetchedAt time.Time
+}
+
+type jwk struct {
+	kid string
+	alg string // optional
+	key interface{}
+}
+
+// NewJWKS create a JWKS from a file path or an http(s) url, refresh default 10m; the document fetched immediately
+func NewJWKS(source string, refresh time.Duration) (*JWKS, error) {
+	return newJWKS(source, refresh, time.Now)
+}
+
+func newJWKS(source string, refresh time.Duration, now func() time.Time) (*JWKS, error) {
+	if source = strings.TrimSpace(source); source == "" {
+		return nil, errors.New("jwks source required")
+	}
+	if refresh <= 0 {
+		refresh = defaultRefresh
+	}
+
+	jwks := &JWKS{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksFetchTimeout},
+		now:     now,
+	}
+
+	keys, err := jwks.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	jwks.keys, jwks.fetchedAt = keys, now()
+	return jwks, nil
+}
+
+// keysFor the candidate keys of kid, all keys if kid is empty; never blocked by the refetch unless the kid unknown
+func (j *JWKS) keysFor(kid string) []*jwk {
+	j.mux.RLock()
+	keys, fetchedAt := j.keys, j.fetchedAt
+	j.mux.RUnlock()
+
+	now := j.now()
+	candidates := matchKeys(keys, kid)
+	if now.Sub(fetchedAt) < j.refresh && (len(candidates) > 0 || now.Sub(fetchedAt) < minRefresh) {
+		return candidates
+	}
+
+	if len(candidates) > 0 {
+		if !j.fetching.TryLock() {
+			return candidates // refetched by another caller, the current keys used meanwhile
+		}
+	} else {
+		j.fetching.Lock() // wait for the keys rotated
+	}
+	defer j.fetching.Unlock()
+
+	j.mux.RLock()
+	refetched := !j.fetchedAt.Equal(fetchedAt)
+	keys = j.keys
+	j.mux.RUnlock()
+
+	if !refetched {
+		fetched, err := j.fetch() // outside the lock of keys
+		if err == nil {
+			keys = fetched
+		}
+
+		j.mux.Lock()
+		j.keys, j.fetchedAt = keys, now
+		j.mux.Unlock()
+	}
+
+	return matchKeys(keys, kid)
+}
+
+func matchKeys(keys []*jwk, kid string) []*jwk {
+	if kid == "" {
+		return keys
+	}
+
+	for _, key := range keys {
+		if key.kid == kid {
+			return []*jwk{key}
+		}
+	}
+	return nil
+}
+
+func (j *JWKS) fetch() ([]*jwk, error) {
+	var raw []byte
+	if strings.HasPrefix(j.source, "http://") || strings.HasPrefix(j.source, "https://") {
+		resp, err := j.client.Get(j.source)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get jwks %s err", j.source)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("get jwks %s status %d", j.source, resp.StatusCode)
+		}
+
+		if raw, err = io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize)); err != nil {
+			return nil, errors.Wrapf(err, "read jwks %s err", j.source)
+		}
+
+	} else {
+		var err error
+		if raw, err = os.ReadFile(j.source); err != nil {
+			return nil, errors.Wrapf(err, "read jwks %s err", j.source)
+		}
+	}
+
+	return parseJWKS(raw)
+}
+
+// parseJWKS the keys of RSA, EC P-256, OKP Ed25519 and oct, the keys not for sig skipped
+func parseJWKS(raw []byte) ([]*jwk, error) {
+	document := new(struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	})
+	if err := json.Unmarshal(raw, document); err != nil {
+		return nil, errors.Wrap(err, "unmarshal jwks err")
+	}
+
+	var keys []*jwk
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var public interface{}
+		switch key.Kty {
+		case "RSA":
+			n, err := decodeSegment(key.N)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwk %s n illegal", key.Kid)
+			}
+			e, err := decodeSegment(key.E)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwk %s e illegal", key.Kid)
+			}
+
+			public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+		case "EC":
+			if key.Crv != "P-256" {
+				continue
+			}
+
+			x, err := decodeSegment(key.X)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwk %s x illegal", key.Kid)
+			}
+			y, err := decodeSegment(key.Y)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwk %s y illegal", key.Kid)
+			}
+
+			ecKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !ecKey.Curve.IsOnCurve(ecKey.X, ecKey.Y) {
+				return nil, errors.Errorf("jwk %s not on curve", key.Kid)
+			}
+			public = ecKey
+
+		case "OKP":
+			if key.Crv != "Ed25519" {
+				continue
+			}
+
+			x, err := decodeSegment(key.X)
+			if err != nil || len(x) != ed25519.PublicKeySize {
+				return nil, errors.Errorf("jwk %s x illegal", key.Kid)
+			}
+			public = ed25519.PublicKey(x)
+
+		case "oct":
+			k, err := decodeSegment(key.K)
+			if err != nil || len(k) == 0 {
+				return nil, errors.Errorf("jwk %s k illegal", key.Kid)
+			}
+			public = k
+
+		default:
+			continue
+		}
+
+		keys = append(keys, &jwk{kid: key.Kid, alg: key.Alg, key: public})
+	}
+
+	return keys, nil
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
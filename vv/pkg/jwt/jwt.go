@@ -0,0 +1,326 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+)
+
+// the supported algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+const defaultClockSkew = time.Minute
+
+var _ proposal.ScopedUserinfo = (*Userinfo)(nil)
+
+// Userinfo the claims of a verified token, the context value of interceptor.SessionUserinfo
+type Userinfo struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time // zero if absent
+	IssuedAt  time.Time // zero if absent
+	ID        string
+	Name      string
+	Email     string
+	Scopes    []string               // scope separated by space, or scp
+	Claims    map[string]interface{} // all the claims, numbers are json.Number
+}
+
+// HasScope whether the scope granted
+func (u *Userinfo) HasScope(scope string) bool {
+	for _, granted := range u.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Config the keys and the claims expected by Validator
+type Config struct {
+	Issuer     string        // iss should equal to it, not checked if empty
+	Audiences  []string      // aud should contain one of them, not checked if empty
+	Algorithms []string      // allowed algorithms, default all the supported ones
+	ClockSkew  time.Duration // tolerance of exp and nbf, default 1m
+	Secret     []byte        // the key of HS256
+	JWKS       *JWKS         // the keys of all the algorithms, oct ones for HS256
+}
+
+// Validator verify the JWTs of Authorization, e.g. server.RegisteAuthorizationValidator("jwt", validator.UserinfoHandler)
+type Validator struct {
+	config     Config
+	algorithms map[string]bool
+	now        func() time.Time
+}
+
+// NewValidator create a Validator, panic if no keys or unsupported algorithms
+func NewValidator(config *Config) *Validator {
+	return newValidator(config, time.Now)
+}
+
+func newValidator(config *Config, now func() time.Time) *Validator {
+	if config == nil {
+		panic("jwt config required")
+	}
+	if len(config.Secret) == 0 && config.JWKS == nil {
+		panic("jwt secret or jwks required")
+	}
+
+	validator := &Validator{
+		config:     *config,
+		algorithms: make(map[string]bool),
+		now:        now,
+	}
+
+	if validator.config.ClockSkew <= 0 {
+		validator.config.ClockSkew = defaultClockSkew
+	}
+
+	algorithms := config.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{HS256, RS256, ES256, EdDSA}
+	}
+	for _, alg := range algorithms {
+		switch alg {
+		case HS256, RS256, ES256, EdDSA:
+			validator.algorithms[alg] = true
+		default:
+			panic(fmt.Sprintf("jwt algorithm %s not supported", alg))
+		}
+	}
+
+	return validator
+}
+
+// UserinfoHandler the proposal.UserinfoHandler of options.authorization, the token is the Bearer one of Authorization
+func (v *Validator) UserinfoHandler(authorization string, payload proposal.Payload) (interface{}, error) {
+	token := strings.TrimSpace(authorization)
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+
+	return v.Validate(token)
+}
+
+// Validate verify the signature and the registered claims of token
+func (v *Validator) Validate(token string) (*Userinfo, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt illegal")
+	}
+
+	raw, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt header illegal")
+	}
+
+	header := new(struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	})
+	if err = json.Unmarshal(raw, header); err != nil {
+		return nil, errors.Wrap(err, "jwt header illegal")
+	}
+	if !v.algorithms[header.Alg] {
+		return nil, errors.Errorf("jwt algorithm %s not allowed", header.Alg)
+	}
+
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt signature illegal")
+	}
+	if !v.verify(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, errors.Errorf("jwt signature of kid %s mismatched", header.Kid)
+	}
+
+	if raw, err = decodeSegment(parts[1]); err != nil {
+		return nil, errors.Wrap(err, "jwt claims illegal")
+	}
+
+	claims := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(&claims); err != nil {
+		return nil, errors.Wrap(err, "jwt claims illegal")
+	}
+
+	return v.userinfo(claims)
+}
+
+// verify the signature by the keys of alg, the ones of JWKS matched by kid
+func (v *Validator) verify(alg, kid string, signed, signature []byte) bool {
+	if alg == HS256 && len(v.config.Secret) > 0 && verify(alg, v.config.Secret, signed, signature) {
+		return true
+	}
+
+	if v.config.JWKS != nil {
+		for _, key := range v.config.JWKS.keysFor(kid) {
+			if key.alg != "" && key.alg != alg {
+				continue
+			}
+			if verify(alg, key.key, signed, signature) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func verify(alg string, key interface{}, signed, signature []byte) bool {
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+
+	case RS256:
+		public, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+
+	case ES256:
+		public, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+
+		digest := sha256.Sum256(signed)
+		return ecdsa.Verify(public, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+
+	case EdDSA:
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return false
+		}
+
+		return ed25519.Verify(public, signed, signature)
+	}
+
+	return false
+}
+
+// userinfo check exp, nbf, iss and aud, then map the claims
+func (v *Validator) userinfo(claims map[string]interface{}) (*Userinfo, error) {
+	now := v.now()
+
+	userinfo := &Userinfo{
+		Subject: stringClaim(claims, "sub"),
+		Issuer:  stringClaim(claims, "iss"),
+		ID:      stringClaim(claims, "jti"),
+		Name:    stringClaim(claims, "name"),
+		Email:   stringClaim(claims, "email"),
+		Claims:  claims,
+	}
+
+	var ok bool
+	if userinfo.ExpiresAt, ok = timeClaim(claims, "exp"); !ok {
+		return nil, errors.New("jwt exp required")
+	}
+	if !now.Before(userinfo.ExpiresAt.Add(v.config.ClockSkew)) {
+		return nil, errors.Errorf("jwt expired at %s", userinfo.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if userinfo.NotBefore, ok = timeClaim(claims, "nbf"); ok && now.Add(v.config.ClockSkew).Before(userinfo.NotBefore) {
+		return nil, errors.Errorf("jwt not valid before %s", userinfo.NotBefore.Format(time.RFC3339))
+	}
+	userinfo.IssuedAt, _ = timeClaim(claims, "iat")
+
+	if v.config.Issuer != "" && userinfo.Issuer != v.config.Issuer {
+		return nil, errors.Errorf("jwt issuer %s mismatched", userinfo.Issuer)
+	}
+
+	userinfo.Audience = stringsClaim(claims, "aud")
+	if len(v.config.Audiences) > 0 && !intersected(userinfo.Audience, v.config.Audiences) {
+		return nil, errors.Errorf("jwt audience %v mismatched", userinfo.Audience)
+	}
+
+	if scope := stringClaim(claims, "scope"); scope != "" {
+		userinfo.Scopes = strings.Fields(scope)
+	} else {
+		userinfo.Scopes = stringsClaim(claims, "scp")
+	}
+
+	return userinfo, nil
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// stringsClaim a string or an array of strings
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return strings.Fields(value)
+
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+// timeClaim a NumericDate, seconds since epoch
+func timeClaim(claims map[string]interface{}, name string) (time.Time, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+func intersected(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
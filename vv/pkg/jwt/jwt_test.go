@@ -0,0 +1,298 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func encode(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func sign(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+
+	case RS256:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	case EdDSA:
+		signature = ed25519.Sign(key.(ed25519.PrivateKey), []byte(signed))
+	}
+
+	return signed + "." + encode(signature)
+}
+
+func claims(extra map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":   "https://sso.example.com",
+		"aud":   []string{"orders", "payments"},
+		"sub":   "1001",
+		"name":  "Alice",
+		"scope": "order:read order:write",
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+type keys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+	secret  []byte
+}
+
+func newKeys(t *testing.T) *keys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &keys{rsa: rsaKey, ecdsa: ecdsaKey, ed25519: ed25519Key, secret: []byte("jwks-secret")}
+}
+
+func (k *keys) document(suffix string) []byte {
+	raw, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa" + suffix, "alg": RS256, "use": "sig", "n": encode(k.rsa.N.Bytes()), "e": encode(big.NewInt(int64(k.rsa.E)).Bytes())},
+			{"kty": "EC", "kid": "ec" + suffix, "crv": "P-256", "x": encode(k.ecdsa.X.FillBytes(make([]byte, 32))), "y": encode(k.ecdsa.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "OKP", "kid": "ed" + suffix, "crv": "Ed25519", "x": encode(k.ed25519.Public().(ed25519.PublicKey))},
+			{"kty": "oct", "kid": "oct" + suffix, "k": encode(k.secret)},
+			{"kty": "RSA", "kid": "enc" + suffix, "use": "enc", "n": encode(k.rsa.N.Bytes()), "e": "AQAB"},
+		},
+	})
+	return raw
+}
+
+func TestValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := newKeys(t)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(os.WriteFile(file, keys.document(""), 0o600))
+
+	jwks, err := newJWKS(file, 0, func() time.Time { return now })
+	if !assert.NoError(err) {
+		return
+	}
+
+	validator := newValidator(&Config{
+		Issuer:    "https://sso.example.com",
+		Audiences: []string{"orders"},
+		Secret:    []byte("secret"),
+		JWKS:      jwks,
+	}, func() time.Time { return now })
+
+	for _, token := range []string{
+		sign(t, HS256, "", []byte("secret"), claims(nil)),
+		sign(t, HS256, "oct", keys.secret, claims(nil)),
+		sign(t, RS256, "rsa", keys.rsa, claims(nil)),
+		sign(t, ES256, "ec", keys.ecdsa, claims(nil)),
+		sign(t, EdDSA, "ed", keys.ed25519, claims(nil)),
+		sign(t, EdDSA, "", keys.ed25519, claims(nil)), // no kid
+	} {
+		userinfo, err := validator.UserinfoHandler("Bearer "+token, nil)
+		if assert.NoError(err) {
+			userinfo := userinfo.(*Userinfo)
+			assert.Equal("1001", userinfo.Subject)
+			assert.Equal("Alice", userinfo.Name)
+			assert.Equal([]string{"orders", "payments"}, userinfo.Audience)
+			assert.True(userinfo.HasScope("order:write"))
+			assert.False(userinfo.HasScope("order:delete"))
+			assert.Equal(now.Add(time.Hour), userinfo.ExpiresAt.UTC())
+		}
+	}
+
+	rejected := map[string]string{
+		"tampered":      sign(t, RS256, "rsa", keys.rsa, claims(nil))[:20] + "x" + sign(t, RS256, "rsa", keys.rsa, claims(nil))[21:],
+		"wrong secret":  sign(t, HS256, "", []byte("guess"), claims(nil)),
+		"wrong kid":     sign(t, ES256, "rsa", keys.ecdsa, claims(nil)),
+		"unknown kid":   sign(t, RS256, "unknown", keys.rsa, claims(nil)),
+		"enc key":       sign(t, RS256, "enc", keys.rsa, claims(nil)),
+		"expired":       sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"exp": now.Add(-time.Minute * 2).Unix()})),
+		"no exp":        sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"exp": nil})),
+		"not yet valid": sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"nbf": now.Add(time.Minute * 2).Unix()})),
+		"issuer":        sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"iss": "https://evil.com"})),
+		"audience":      sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"aud": "reports"})),
+		"none":          encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(`{"sub":"1001"}`)) + ".",
+		"malformed":     "abc",
+	}
+	for name, token := range rejected {
+		_, err := validator.Validate(token)
+		assert.Error(err, name)
+	}
+
+	// tolerated by clock skew
+	_, err = validator.Validate(sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"exp": now.Add(-time.Second * 30).Unix(), "nbf": now.Add(time.Second * 30).Unix()})))
+	assert.NoError(err)
+
+	// scp as array
+	userinfo, err := validator.Validate(sign(t, RS256, "rsa", keys.rsa, claims(map[string]interface{}{"scope": nil, "scp": []string{"report:read"}})))
+	if assert.NoError(err) {
+		assert.Equal([]string{"report:read"}, userinfo.Scopes)
+	}
+
+	// algorithm not allowed
+	rsaOnly := newValidator(&Config{Algorithms: []string{RS256}, JWKS: jwks}, func() time.Time { return now })
+	_, err = rsaOnly.Validate(sign(t, HS256, "oct", keys.secret, claims(nil)))
+	assert.Error(err)
+
+	assert.Panics(func() { NewValidator(&Config{}) })
+	assert.Panics(func() { NewValidator(&Config{Secret: []byte("secret"), Algorithms: []string{"HS512"}}) })
+}
+
+func TestJWKSRotation(t *testing.T) {
+	assert := assert.New(t)
+
+	current, next := newKeys(t), newKeys(t)
+
+	var mux sync.Mutex
+	document, fetched := current.document("-1"), 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		fetched++
+		w.Write(document)
+	}))
+	defer server.Close()
+
+	ts := now
+	jwks, err := newJWKS(server.URL, time.Hour, func() time.Time { return ts })
+	if !assert.NoError(err) {
+		return
+	}
+	validator := newValidator(&Config{JWKS: jwks}, func() time.Time { return ts })
+
+	_, err = validator.Validate(sign(t, ES256, "ec-1", current.ecdsa, claims(nil)))
+	assert.NoError(err)
+	assert.Equal(1, fetched) // cached
+
+	mux.Lock()
+	document = next.document("-2") // rotated
+	mux.Unlock()
+
+	_, err = validator.Validate(sign(t, ES256, "ec-2", next.ecdsa, claims(nil)))
+	assert.Error(err) // refetched at most once in 30s
+	assert.Equal(1, fetched)
+
+	ts = ts.Add(minRefresh)
+	_, err = validator.Validate(sign(t, ES256, "ec-2", next.ecdsa, claims(nil)))
+	assert.NoError(err)
+	assert.Equal(2, fetched)
+
+	_, err = validator.Validate(sign(t, ES256, "ec-1", current.ecdsa, claims(nil)))
+	assert.Error(err) // retired
+
+	server.Close()
+	ts = ts.Add(time.Hour) // refetch failed, the last keys kept
+	_, err = validator.Validate(sign(t, ES256, "ec-2", next.ecdsa, claims(nil)))
+	assert.NoError(err)
+
+	_, err = NewJWKS(server.URL, 0)
+	assert.Error(err)
+}
+
+func TestJWKSRefetchNotBlocking(t *testing.T) {
+	assert := assert.New(t)
+
+	current := newKeys(t)
+
+	var slow int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			<-release
+		}
+		w.Write(current.document("-1"))
+	}))
+	defer server.Close()
+
+	var ts atomic.Value
+	ts.Store(now)
+	clock := func() time.Time { return ts.Load().(time.Time) }
+
+	jwks, err := newJWKS(server.URL, time.Minute, clock)
+	if !assert.NoError(err) {
+		return
+	}
+	validator := newValidator(&Config{JWKS: jwks}, clock)
+
+	atomic.StoreInt32(&slow, 1)
+	ts.Store(now.Add(time.Minute)) // stale
+
+	refetched := make(chan error)
+	go func() {
+		_, err := validator.Validate(sign(t, ES256, "ec-1", current.ecdsa, claims(nil)))
+		refetched <- err
+	}()
+
+	time.Sleep(time.Millisecond * 50) // refetch in flight
+	done := make(chan error)
+	go func() {
+		_, err := validator.Validate(sign(t, ES256, "ec-1", current.ecdsa, claims(nil)))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Error("blocked by the refetch")
+	}
+
+	close(release)
+	assert.NoError(<-refetched)
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/bluekaki/pkg/errors"
 	"github.com/bluekaki/pkg/vv/proposal"
+
+	"golang.org/x/text/language"
+	"google.golang.org/protobuf/proto"
 )
 
 var _ proposal.Code = (*code)(nil)
@@ -35,8 +38,6 @@ func (c *code) WithDesc(desc string) proposal.Code {
 	return &clone
 }
 
-var bzCodes = make(map[int]bool)
-
 // NewCode create a proposal code
 func NewCode(bzCode, httpCode int, desc string) proposal.Code {
 	if bzCode <= 0 || bzCode > 99999999 {
@@ -46,26 +47,50 @@ func NewCode(bzCode, httpCode int, desc string) proposal.Code {
 		panic(fmt.Sprintf("httpCode %d not defined in http", httpCode))
 	}
 
-	if bzCodes[bzCode] {
-		panic(fmt.Sprintf("bzCode %d duplicated", bzCode))
-	}
-	bzCodes[bzCode] = true
-
-	return &code{
+	code := &code{
 		bzCode:   bzCode,
 		httpCode: httpCode,
 		desc:     desc,
 	}
-}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.codes[bzCode]; ok {
+		panic(fmt.Sprintf("bzCode %d duplicated", bzCode))
+	}
+	registry.codes[bzCode] = &entry{code: code, i18n: make(map[language.Tag]string)}
+
+	return code
+}
+
+// codes reserved by vv itself, bzCode between 99990000 and 99999999
+var (
+	// TooManyRequests rate limit exceeded, declared by options.rate_limit
+	TooManyRequests = NewCode(99990429, http.StatusTooManyRequests, "too many requests")
+	// IdempotencyConflict another request with the same Idempotency-Key in flight, declared by options.idempotent
+	IdempotencyConflict = NewCode(99990409, http.StatusConflict, "request with the same idempotency key in flight")
+	// IdempotencyMismatch the Idempotency-Key reused by a request different from the first one, declared by options.idempotent
+	IdempotencyMismatch = NewCode(99990422, http.StatusUnprocessableEntity, "idempotency key reused by a different request")
+	// PermissionDenied the userinfo not granted the roles or permissions declared by options.roles and options.permissions
+	PermissionDenied = NewCode(99990403, http.StatusForbidden, "permission denied")
+	// CircuitOpen rejected by the circuit breaker of client
+	CircuitOpen = NewCode(99990503, http.StatusServiceUnavailable, "circuit breaker open")
+)
 
 type BzError interface {
 	proposal.BzError
 	AlertError(*proposal.AlertMessageMeta) proposal.AlertError
+	// WithDetails attach google.rpc error details, e.g. ErrorInfo, RetryInfo, QuotaFailure and ResourceInfo
+	WithDetails(details ...proto.Message) BzError
+	// Details the attached error details
+	Details() []proto.Message
 }
 
 type bzError struct {
 	proposal.Code
-	err errors.Error
+	err     errors.Error
+	details []proto.Message
 }
 
 func (b *bzError) Error() string {
@@ -76,6 +101,16 @@ func (b *bzError) StackErr() errors.Error {
 	return b.err
 }
 
+func (b *bzError) WithDetails(details ...proto.Message) BzError {
+	clone := *b
+	clone.details = append(append([]proto.Message(nil), b.details...), details...)
+	return &clone
+}
+
+func (b *bzError) Details() []proto.Message {
+	return b.details
+}
+
 func (b *bzError) AlertError(meta *proposal.AlertMessageMeta) proposal.AlertError {
 	return &alertError{
 		bzError: b,
@@ -0,0 +1,127 @@
+package cuzerr
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorInfo the reason of error, e.g. ErrorInfo("STOCK_EXHAUSTED", "order.example.com", map[string]string{"sku": "1001"})
+func ErrorInfo(reason, domain string, metadata map[string]string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	}
+}
+
+// RetryInfo the client should retry after delay, responded as Retry-After by gateway
+func RetryInfo(delay time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+}
+
+// QuotaFailure the quota of subject exhausted, e.g. QuotaFailure("user:1001", "daily orders limit exceeded")
+func QuotaFailure(subject, description string) *errdetails.QuotaFailure {
+	return &errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     subject,
+			Description: description,
+		}},
+	}
+}
+
+// ResourceInfo the resource accessed, e.g. ResourceInfo("order", "orders/1001", "user:1001", "order not found")
+func ResourceInfo(resourceType, resourceName, owner, description string) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Owner:        owner,
+		Description:  description,
+	}
+}
+
+// GRPCStatus the status with bzCode as code, desc as message and the details
+func (b *bzError) GRPCStatus() *status.Status {
+	s := status.New(codes.Code(b.BzCode()), b.Desc()).Proto()
+	for _, detail := range b.details {
+		if any, err := anypb.New(detail); err == nil {
+			s.Details = append(s.Details, any)
+		}
+	}
+
+	return status.FromProto(s)
+}
+
+// FromStatus reconstruct the BzError responded by a vv server, the google.rpc details of status kept
+func FromStatus(s *status.Status, httpCode int) BzError {
+	if s == nil {
+		panic("status required")
+	}
+
+	bzCode := int(s.Code())
+	if http.StatusText(httpCode) == "" {
+		httpCode = http.StatusInternalServerError
+		if registered, ok := Lookup(bzCode); ok {
+			httpCode = registered.HTTPCode()
+		}
+	}
+
+	bzError := &bzError{err: errors.New(s.Message())}
+	bzError.Code = &code{
+		bzCode:   bzCode,
+		httpCode: httpCode,
+		desc:     s.Message(),
+	}
+
+	for _, detail := range s.Details() {
+		if detail, ok := detail.(proto.Message); ok {
+			bzError.details = append(bzError.details, detail)
+		}
+	}
+
+	return bzError
+}
+
+// FindDetail find the detail with the same type in err, true and the detail filled if found,
+// e.g. FindDetail(err, new(errdetails.RetryInfo))
+func FindDetail(err error, detail proto.Message) bool {
+	if err == nil || detail == nil {
+		return false
+	}
+
+	if alertErr, ok := err.(proposal.AlertError); ok {
+		err = alertErr.BzError()
+	}
+
+	var details []proto.Message
+	if detailed, ok := err.(proposal.DetailedError); ok {
+		details = detailed.Details()
+
+	} else if s, ok := status.FromError(err); ok {
+		for _, item := range s.Details() {
+			if item, ok := item.(proto.Message); ok {
+				details = append(details, item)
+			}
+		}
+	}
+
+	name := detail.ProtoReflect().Descriptor().FullName()
+	for _, item := range details {
+		if item.ProtoReflect().Descriptor().FullName() == name {
+			proto.Reset(detail)
+			proto.Merge(detail, item)
+			return true
+		}
+	}
+
+	return false
+}
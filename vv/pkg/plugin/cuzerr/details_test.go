@@ -0,0 +1,42 @@
+package cuzerr
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/bluekaki/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDetails(t *testing.T) {
+	assert := assert.New(t)
+
+	bzErr := NewBzError(orderClosed, errors.New("order 1001 closed"))
+	detailed := bzErr.WithDetails(ResourceInfo("order", "orders/1001", "user:1001", "closed at 2021-01-01"))
+	assert.Empty(bzErr.Details()) // cloned
+	assert.Len(detailed.Details(), 1)
+
+	s := status.Convert(detailed)
+	assert.Equal(codes.Code(10409), s.Code())
+	assert.Equal("order | closed", s.Message())
+
+	resourceInfo := new(errdetails.ResourceInfo)
+	assert.True(FindDetail(s.Err(), resourceInfo))
+	assert.Equal("orders/1001", resourceInfo.ResourceName)
+
+	resourceInfo = new(errdetails.ResourceInfo)
+	assert.True(FindDetail(detailed.AlertError(nil), resourceInfo))
+	assert.Equal("user:1001", resourceInfo.Owner)
+
+	reconstructed := FromStatus(s, 0)
+	assert.Equal(http.StatusConflict, reconstructed.HTTPCode()) // from the registered
+	assert.Len(reconstructed.Details(), 1)
+
+	reconstructed = FromStatus(status.New(codes.Code(10499), "unregistered"), 0)
+	assert.Equal(http.StatusInternalServerError, reconstructed.HTTPCode())
+	assert.False(FindDetail(reconstructed, new(errdetails.ErrorInfo)))
+}
@@ -0,0 +1,129 @@
+package cuzerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bluekaki/pkg/errors"
+)
+
+// ExportJSON write the registered codes as json, which could be the codes of protoc-gen-openapi,
+// e.g. [{"bz_code":1101,"http_code":400,"desc":"some business error occurs","i18n":{"zh-CN":"..."}}]
+func ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(Codes()); err != nil {
+		return errors.Wrap(err, "export codes as json err")
+	}
+	return nil
+}
+
+// ExportMarkdown write the registered codes as a markdown table
+func ExportMarkdown(w io.Writer) error {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("| bz_code | http_code | desc | i18n |\n")
+	buf.WriteString("| --- | --- | --- | --- |\n")
+
+	escape := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+	for _, entry := range Codes() {
+		var i18n []string
+		for _, lang := range langs(entry.I18n) {
+			i18n = append(i18n, fmt.Sprintf("%s: %s", lang, escape(entry.I18n[lang])))
+		}
+
+		fmt.Fprintf(buf, "| %d | %d %s | %s | %s |\n", entry.BzCode, entry.HTTPCode, http.StatusText(entry.HTTPCode), escape(entry.Desc), strings.Join(i18n, "<br>"))
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "export codes as markdown err")
+	}
+	return nil
+}
+
+// ExportTypeScript write the registered codes as a typescript enum named enumName, with the http codes and descs of it;
+// the member named by pascal case of desc, e.g. TooManyRequests
+func ExportTypeScript(w io.Writer, enumName string) error {
+	if enumName == "" {
+		enumName = "BzCode"
+	}
+
+	entries := Codes()
+	names := memberNames(entries)
+
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("// Code generated by cuzerr. DO NOT EDIT.\n\n")
+
+	fmt.Fprintf(buf, "export enum %s {\n", enumName)
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "  %s = %d,\n", names[entry.BzCode], entry.BzCode)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "export const %sHTTPCodes: Record<%s, number> = {\n", enumName, enumName)
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "  [%s.%s]: %d,\n", enumName, names[entry.BzCode], entry.HTTPCode)
+	}
+	buf.WriteString("};\n\n")
+
+	fmt.Fprintf(buf, "export const %sDescs: Record<%s, Record<string, string>> = {\n", enumName, enumName)
+	for _, entry := range entries {
+		descs := []string{fmt.Sprintf("%q: %s", "", strconv.Quote(entry.Desc))}
+		for _, lang := range langs(entry.I18n) {
+			descs = append(descs, fmt.Sprintf("%q: %s", lang, strconv.Quote(entry.I18n[lang])))
+		}
+
+		fmt.Fprintf(buf, "  [%s.%s]: { %s },\n", enumName, names[entry.BzCode], strings.Join(descs, ", "))
+	}
+	buf.WriteString("};\n")
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "export codes as typescript err")
+	}
+	return nil
+}
+
+func langs(i18n map[string]string) []string {
+	langs := make([]string, 0, len(i18n))
+	for lang := range i18n {
+		langs = append(langs, lang)
+	}
+
+	sort.Strings(langs)
+	return langs
+}
+
+// memberNames pascal case of desc, Code{bzCode} if empty or not an identifier, suffixed with bzCode if duplicated
+func memberNames(entries []*Entry) map[int]string {
+	names := make(map[int]string, len(entries))
+	used := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		var name strings.Builder
+		for _, word := range strings.FieldsFunc(entry.Desc, func(r rune) bool {
+			return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+		}) {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+
+		member := name.String()
+		if member == "" || unicode.IsDigit(rune(member[0])) {
+			member = fmt.Sprintf("Code%d", entry.BzCode)
+		}
+		if used[member] {
+			member = fmt.Sprintf("%s%d", member, entry.BzCode)
+		}
+
+		used[member] = true
+		names[entry.BzCode] = member
+	}
+
+	return names
+}
@@ -0,0 +1,130 @@
+package cuzerr
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bluekaki/pkg/vv/proposal"
+
+	"golang.org/x/text/language"
+)
+
+var registry = struct {
+	sync.RWMutex
+	codes map[int]*entry
+}{
+	codes: make(map[int]*entry),
+}
+
+type entry struct {
+	code    *code
+	i18n    map[language.Tag]string
+	tags    []language.Tag // language.Und first as the default desc
+	matcher language.Matcher
+}
+
+// RegisteDesc add the localized desc of code, e.g. zh-CN, selected by the Accept-Language of gateway
+func RegisteDesc(code proposal.Code, lang, desc string) {
+	if code == nil {
+		panic("proposal.Code required")
+	}
+
+	tag, err := language.Parse(lang)
+	if err != nil {
+		panic(fmt.Sprintf("lang %s illegal, %v", lang, err))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	entry, ok := registry.codes[code.BzCode()]
+	if !ok {
+		panic(fmt.Sprintf("bzCode %d not registered", code.BzCode()))
+	}
+
+	if _, ok := entry.i18n[tag]; !ok {
+		entry.tags = append(entry.tags, tag)
+	}
+	entry.i18n[tag] = desc
+
+	if entry.tags[0] != language.Und {
+		entry.tags = append([]language.Tag{language.Und}, entry.tags...)
+	}
+	entry.matcher = language.NewMatcher(entry.tags)
+}
+
+// Lookup the registered code of bzCode
+func Lookup(bzCode int) (proposal.Code, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entry, ok := registry.codes[bzCode]
+	if !ok {
+		return nil, false
+	}
+	return entry.code, true
+}
+
+// LocalizedDesc the desc of bzCode best matched by accept-language, the default desc if nothing matched; false if bzCode not registered
+func LocalizedDesc(bzCode int, acceptLanguage string) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entry, ok := registry.codes[bzCode]
+	if !ok {
+		return "", false
+	}
+
+	if entry.matcher == nil || acceptLanguage == "" {
+		return entry.code.desc, true
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return entry.code.desc, true
+	}
+
+	_, index, confidence := entry.matcher.Match(tags...)
+	if index == 0 || confidence == language.No {
+		return entry.code.desc, true
+	}
+	return entry.i18n[entry.tags[index]], true
+}
+
+// Entry a registered code, compatible with the codes of protoc-gen-openapi
+type Entry struct {
+	BzCode   int               `json:"bz_code"`
+	HTTPCode int               `json:"http_code"`
+	Desc     string            `json:"desc"`
+	I18n     map[string]string `json:"i18n,omitempty"`
+}
+
+// Codes all the registered codes order by bzCode
+func Codes() []*Entry {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entries := make([]*Entry, 0, len(registry.codes))
+	for _, entry := range registry.codes {
+		item := &Entry{
+			BzCode:   entry.code.bzCode,
+			HTTPCode: entry.code.httpCode,
+			Desc:     entry.code.desc,
+		}
+
+		if len(entry.i18n) > 0 {
+			item.I18n = make(map[string]string, len(entry.i18n))
+			for tag, desc := range entry.i18n {
+				item.I18n[tag.String()] = desc
+			}
+		}
+
+		entries = append(entries, item)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].BzCode < entries[j].BzCode
+	})
+	return entries
+}
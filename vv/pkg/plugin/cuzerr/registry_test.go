@@ -0,0 +1,117 @@
+package cuzerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	illegalToken = NewCode(10401, http.StatusUnauthorized, "illegal token")
+	orderClosed  = NewCode(10409, http.StatusConflict, "order | closed")
+	_            = NewCode(10410, http.StatusConflict, "illegal token") // the same desc
+)
+
+func init() {
+	RegisteDesc(illegalToken, "zh-CN", "令牌无效")
+	RegisteDesc(illegalToken, "ja", "無効なトークン")
+}
+
+func TestLocalizedDesc(t *testing.T) {
+	assert := assert.New(t)
+
+	for acceptLanguage, expected := range map[string]string{
+		"":                         "illegal token",
+		"zh-CN,zh;q=0.9,en;q=0.8":  "令牌无效",
+		"zh":                       "令牌无效",
+		"en-US,en;q=0.9":           "illegal token",
+		"fr;q=0.9, ja;q=0.8":       "無効なトークン",
+		"ja;q=0.5, zh-CN;q=0.8":    "令牌无效",
+		"not a language;;q=x, ???": "illegal token",
+	} {
+		desc, ok := LocalizedDesc(illegalToken.BzCode(), acceptLanguage)
+		assert.True(ok)
+		assert.Equal(expected, desc, acceptLanguage)
+	}
+
+	desc, ok := LocalizedDesc(orderClosed.BzCode(), "zh-CN")
+	assert.True(ok)
+	assert.Equal("order | closed", desc)
+
+	_, ok = LocalizedDesc(10499, "zh-CN")
+	assert.False(ok)
+
+	registered, ok := Lookup(10401)
+	assert.True(ok)
+	assert.Equal(illegalToken, registered)
+
+	assert.Panics(func() { RegisteDesc(illegalToken, "??", "") })
+	assert.Panics(func() { RegisteDesc(&code{bzCode: 10499, httpCode: http.StatusConflict}, "zh-CN", "") })
+	assert.Panics(func() { NewCode(10401, http.StatusUnauthorized, "duplicated") })
+}
+
+func TestExportJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(ExportJSON(buf))
+
+	var codes []struct {
+		BzCode   int               `json:"bz_code"`
+		HTTPCode int               `json:"http_code"`
+		Desc     string            `json:"desc"`
+		I18n     map[string]string `json:"i18n"`
+	}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &codes))
+
+	found := false
+	for i, code := range codes {
+		if i > 0 {
+			assert.Less(codes[i-1].BzCode, code.BzCode)
+		}
+
+		if code.BzCode == 10401 {
+			found = true
+			assert.Equal(http.StatusUnauthorized, code.HTTPCode)
+			assert.Equal("illegal token", code.Desc)
+			assert.Equal(map[string]string{"zh-CN": "令牌无效", "ja": "無効なトークン"}, code.I18n)
+		}
+	}
+	assert.True(found)
+}
+
+func TestExportMarkdown(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(ExportMarkdown(buf))
+
+	markdown := buf.String()
+	assert.True(strings.HasPrefix(markdown, "| bz_code | http_code | desc | i18n |\n| --- | --- | --- | --- |\n"))
+	assert.Contains(markdown, "| 10401 | 401 Unauthorized | illegal token | ja: 無効なトークン<br>zh-CN: 令牌无效 |\n")
+	assert.Contains(markdown, "| 10409 | 409 Conflict | order \\| closed |  |\n")
+	assert.Contains(markdown, "| 99990429 | 429 Too Many Requests | too many requests |  |\n")
+}
+
+func TestExportTypeScript(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(ExportTypeScript(buf, ""))
+
+	ts := buf.String()
+	assert.Contains(ts, "export enum BzCode {\n")
+	assert.Contains(ts, "  IllegalToken = 10401,\n")
+	assert.Contains(ts, "  OrderClosed = 10409,\n")
+	assert.Contains(ts, "  IllegalToken10410 = 10410,\n")
+	assert.Contains(ts, "  TooManyRequests = 99990429,\n")
+	assert.Contains(ts, "  [BzCode.IllegalToken]: 401,\n")
+	assert.Contains(ts, `  [BzCode.IllegalToken]: { "": "illegal token", "ja": "無効なトークン", "zh-CN": "令牌无效" },`)
+
+	assert.Equal("Code10410", memberNames([]*Entry{{BzCode: 10410, Desc: "404"}})[10410])
+	assert.Equal("Code10410", memberNames([]*Entry{{BzCode: 10410, Desc: "令牌"}})[10410])
+}
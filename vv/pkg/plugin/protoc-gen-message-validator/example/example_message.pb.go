@@ -0,0 +1,369 @@
+// Code generated by protoc-gen-message-validator. DO NOT EDIT.
+
+package example
+
+import (
+	validation "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/validation"
+	regexp "regexp"
+	strings "strings"
+	time "time"
+	utf8 "unicode/utf8"
+)
+
+func (a *Address) Validate() error {
+	return a.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (a *Address) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	a.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (a *Address) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	a.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (a *Address) ValidateCollect(path string, collector *validation.Collector) bool {
+
+	// City  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	a.City = strings.TrimSpace(a.City)
+
+	if a.GetCity() == "" {
+		if !collector.Add(validation.Join(path, "city"), "require", "", nil, "required") {
+			return false
+		}
+	}
+
+	if utf8.RuneCountInString(a.GetCity()) > 8 {
+		if !collector.Add(validation.Join(path, "city"), "max_len", "8", a.GetCity(), "length illegal") {
+			return false
+		}
+	}
+
+	// Zip  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	a.Zip = strings.TrimSpace(a.Zip)
+
+	if a.GetZip() != "" && !_Address_Zip_pattern.MatchString(a.GetZip()) {
+		if !collector.Add(validation.Join(path, "zip"), "pattern", "^[0-9]{6}$", a.GetZip(), "illegal") {
+			return false
+		}
+	}
+
+	return true
+}
+
+var _Address_Zip_pattern = regexp.MustCompile("^[0-9]{6}$")
+
+func (p *Profile) Validate() error {
+	return p.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (p *Profile) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	p.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (p *Profile) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	p.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (p *Profile) ValidateCollect(path string, collector *validation.Collector) bool {
+
+	// Nickname  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Nickname = strings.TrimSpace(p.Nickname)
+
+	if utf8.RuneCountInString(p.GetNickname()) < 2 {
+		if !collector.Add(validation.Join(path, "nickname"), "min_len", "2", p.GetNickname(), "length illegal") {
+			return false
+		}
+	}
+	if utf8.RuneCountInString(p.GetNickname()) > 4 {
+		if !collector.Add(validation.Join(path, "nickname"), "max_len", "4", p.GetNickname(), "length illegal") {
+			return false
+		}
+	}
+
+	// Email  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Email = strings.TrimSpace(p.Email)
+
+	if p.GetEmail() != "" && !validation.IsEmail(p.GetEmail()) {
+		if !collector.Add(validation.Join(path, "email"), "email", "", p.GetEmail(), "illegal") {
+			return false
+		}
+	}
+
+	// Homepage  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Homepage = strings.TrimSpace(p.Homepage)
+
+	if p.GetHomepage() != "" && !validation.IsURL(p.GetHomepage()) {
+		if !collector.Add(validation.Join(path, "homepage"), "url", "", p.GetHomepage(), "illegal") {
+			return false
+		}
+	}
+
+	// Id  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Id = strings.TrimSpace(p.Id)
+
+	if p.GetId() != "" && !validation.IsUUID(p.GetId()) {
+		if !collector.Add(validation.Join(path, "id"), "uuid", "", p.GetId(), "illegal") {
+			return false
+		}
+	}
+
+	// Ip  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Ip = strings.TrimSpace(p.Ip)
+
+	if p.GetIp() != "" && !validation.IsIP(p.GetIp()) {
+		if !collector.Add(validation.Join(path, "ip"), "ip", "", p.GetIp(), "illegal") {
+			return false
+		}
+	}
+
+	// Ipv4  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Ipv4 = strings.TrimSpace(p.Ipv4)
+
+	if p.GetIpv4() != "" && !validation.IsIPv4(p.GetIpv4()) {
+		if !collector.Add(validation.Join(path, "ipv4"), "ipv4", "", p.GetIpv4(), "illegal") {
+			return false
+		}
+	}
+
+	// Ipv6  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Ipv6 = strings.TrimSpace(p.Ipv6)
+
+	if p.GetIpv6() != "" && !validation.IsIPv6(p.GetIpv6()) {
+		if !collector.Add(validation.Join(path, "ipv6"), "ipv6", "", p.GetIpv6(), "illegal") {
+			return false
+		}
+	}
+
+	// Cidr  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Cidr = strings.TrimSpace(p.Cidr)
+
+	if p.GetCidr() != "" && !validation.IsCIDR(p.GetCidr()) {
+		if !collector.Add(validation.Join(path, "cidr"), "cidr", "", p.GetCidr(), "illegal") {
+			return false
+		}
+	}
+
+	// Hostname  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Hostname = strings.TrimSpace(p.Hostname)
+
+	if p.GetHostname() != "" && !validation.IsHostname(p.GetHostname()) {
+		if !collector.Add(validation.Join(path, "hostname"), "hostname", "", p.GetHostname(), "illegal") {
+			return false
+		}
+	}
+
+	// Mobile  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Mobile = strings.TrimSpace(p.Mobile)
+
+	if p.GetMobile() != "" && (len(p.GetMobile()) != 11 || p.GetMobile()[0] != '1' || strings.Trim(p.GetMobile(), "0123456789") != "") {
+		if !collector.Add(validation.Join(path, "mobile"), "cn_mobile", "", p.GetMobile(), "illegal") {
+			return false
+		}
+	}
+
+	// Birthday  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Birthday = strings.TrimSpace(p.Birthday)
+
+	if p.GetBirthday() != "" {
+		if _, err := time.ParseInLocation("2006-01-02", p.GetBirthday(), time.Local); err != nil {
+			if !collector.Wrap(err, validation.Join(path, "birthday"), "cst_day", "2006-01-02", p.GetBirthday(), "illegal") {
+				return false
+			}
+		}
+	}
+
+	// Gender  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Gender = strings.TrimSpace(p.Gender)
+
+	if !(p.GetGender() == "male" || p.GetGender() == "female") {
+		if !collector.Add(validation.Join(path, "gender"), "in", "male,female", p.GetGender(), "illegal") {
+			return false
+		}
+	}
+
+	// Role  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	p.Role = strings.TrimSpace(p.Role)
+
+	if p.GetRole() == "root" {
+		if !collector.Add(validation.Join(path, "role"), "not_in", "root", p.GetRole(), "illegal") {
+			return false
+		}
+	}
+
+	// Offset  Kind:Sint32Kind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	if !(int64(p.GetOffset()) <= 10) {
+		if !collector.Add(validation.Join(path, "offset"), "int_le", "10", p.GetOffset(), "illegal") {
+			return false
+		}
+	}
+	if !(int64(p.GetOffset()) >= -10) {
+		if !collector.Add(validation.Join(path, "offset"), "int_ge", "-10", p.GetOffset(), "illegal") {
+			return false
+		}
+	}
+
+	// Ratio  Kind:DoubleKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	if !(float64(p.GetRatio()) < 1) {
+		if !collector.Add(validation.Join(path, "ratio"), "float_lt", "1", p.GetRatio(), "illegal") {
+			return false
+		}
+	}
+	if !(float64(p.GetRatio()) > 0) {
+		if !collector.Add(validation.Join(path, "ratio"), "float_gt", "0", p.GetRatio(), "illegal") {
+			return false
+		}
+	}
+
+	// Retries  Kind:Uint32Kind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	if !(p.GetRetries() == 1 || p.GetRetries() == 3 || p.GetRetries() == 5) {
+		if !collector.Add(validation.Join(path, "retries"), "in", "1,3,5", p.GetRetries(), "illegal") {
+			return false
+		}
+	}
+
+	// Level  Kind:EnumKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	if _, ok := Level_name[int32(p.GetLevel())]; !ok {
+		if !collector.Add(validation.Join(path, "level"), "defined_only", "", p.GetLevel(), "illegal") {
+			return false
+		}
+	}
+	if p.GetLevel() == Level_LEVEL_HIGH {
+		if !collector.Add(validation.Join(path, "level"), "not_in", "LEVEL_HIGH", p.GetLevel(), "illegal") {
+			return false
+		}
+	}
+
+	// Tags  Kind:StringKind Cardinality:repeated IsList:true IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+	for idx := range p.Tags {
+		p.Tags[idx] = strings.TrimSpace(p.Tags[idx])
+	}
+
+	if len(p.GetTags()) > 3 {
+		if !collector.Add(validation.Join(path, "tags"), "max_cap", "3", len(p.GetTags()), "capacity illegal") {
+			return false
+		}
+	}
+
+	for idx, val := range p.GetTags() {
+		if val == "" {
+			if !collector.Add(validation.Index(validation.Join(path, "tags"), idx), "require", "", val, "required") {
+				return false
+			}
+		}
+		if utf8.RuneCountInString(val) > 5 {
+			if !collector.Add(validation.Index(validation.Join(path, "tags"), idx), "max_len", "5", val, "length illegal") {
+				return false
+			}
+		}
+	}
+
+	// Scores  Kind:MessageKind Cardinality:repeated IsList:false IsMap:true IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	for key, val := range p.GetScores() {
+		if key != "" && !_Profile_Scores_keys_pattern.MatchString(key) {
+			if !collector.Add(validation.Key(validation.Join(path, "scores"), key), "pattern", "^[a-z]+$", key, "illegal") {
+				return false
+			}
+		}
+		if !(int64(val) >= 0) {
+			if !collector.Add(validation.Key(validation.Join(path, "scores"), key), "int_ge", "0", val, "illegal") {
+				return false
+			}
+		}
+	}
+
+	// Addresses  Kind:MessageKind Cardinality:repeated IsList:false IsMap:true IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	for key, val := range p.GetAddresses() {
+		if val != nil {
+			if !validation.Nested(validation.Key(validation.Join(path, "addresses"), key), val, collector) {
+				return false
+			}
+		}
+	}
+
+	// History  Kind:MessageKind Cardinality:repeated IsList:true IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
+
+	for idx, val := range p.GetHistory() {
+		if val != nil {
+			if !validation.Nested(validation.Index(validation.Join(path, "history"), idx), val, collector) {
+				return false
+			}
+		}
+	}
+
+	// Home  Kind:MessageKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:true
+
+	if sub := p.GetHome(); sub != nil {
+		if !validation.Nested(validation.Join(path, "home"), sub, collector) {
+			return false
+		}
+	}
+
+	// Phone  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:true
+
+	if _, ok := p.Contact.(*Profile_Phone); ok {
+		if p.GetPhone() != "" && (len(p.GetPhone()) != 11 || p.GetPhone()[0] != '1' || strings.Trim(p.GetPhone(), "0123456789") != "") {
+			if !collector.Add(validation.Join(path, "phone"), "cn_mobile", "", p.GetPhone(), "illegal") {
+				return false
+			}
+		}
+	}
+
+	// Wechat  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:true
+
+	if _, ok := p.Contact.(*Profile_Wechat); ok {
+		if utf8.RuneCountInString(p.GetWechat()) < 6 {
+			if !collector.Add(validation.Join(path, "wechat"), "min_len", "6", p.GetWechat(), "length illegal") {
+				return false
+			}
+		}
+	}
+
+	// Remark  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:true
+	if p.Remark != nil {
+		*p.Remark = strings.TrimSpace(*p.Remark)
+	}
+
+	if p.Remark != nil {
+		if utf8.RuneCountInString(p.GetRemark()) > 10 {
+			if !collector.Add(validation.Join(path, "remark"), "max_len", "10", p.GetRemark(), "length illegal") {
+				return false
+			}
+		}
+	}
+
+	if p.Contact == nil {
+		if !collector.Add(validation.Join(path, "contact"), "require", "", nil, "required") {
+			return false
+		}
+	}
+
+	return true
+}
+
+var _Profile_Scores_keys_pattern = regexp.MustCompile("^[a-z]+$")
+
+func (p *Profile) ParseBirthday() time.Time {
+	ts, _ := time.ParseInLocation("2006-01-02", p.GetBirthday(), time.Local)
+	return ts
+}
@@ -0,0 +1,139 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/validation"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func valid() *Profile {
+	return &Profile{
+		Nickname: " 小明 ",
+		Email:    "user@example.com",
+		Homepage: "https://example.com",
+		Id:       "123e4567-e89b-12d3-a456-426614174000",
+		Ip:       "::1",
+		Ipv4:     "10.0.0.1",
+		Ipv6:     "fe80::1",
+		Cidr:     "10.0.0.0/8",
+		Hostname: "api.example.com",
+		Mobile:   "13800138000",
+		Birthday: "2000-01-02",
+		Gender:   "male",
+		Role:     "admin",
+		Offset:   -10,
+		Ratio:    0.5,
+		Retries:  3,
+		Level:    Level_LEVEL_LOW,
+		Tags:     []string{"a", "b"},
+		Scores:   map[string]int64{"math": 90},
+		Addresses: map[string]*Address{
+			"home": {City: "beijing", Zip: "100000"},
+		},
+		History: []*Address{{City: "shanghai"}},
+		Home:    &Address{City: "beijing"},
+		Contact: &Profile_Wechat{Wechat: "wechat_id"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	profile := valid()
+	assert.NoError(profile.Validate())
+	assert.Equal("小明", profile.GetNickname())
+
+	cases := []struct {
+		mutate func(*Profile)
+		err    string
+	}{
+		{func(p *Profile) { p.Nickname = "a" }, "nickname length illegal"},
+		{func(p *Profile) { p.Nickname = "abcde" }, "nickname length illegal"},
+		{func(p *Profile) { p.Email = "User <user@example.com>" }, "email illegal"},
+		{func(p *Profile) { p.Homepage = "example.com" }, "homepage illegal"},
+		{func(p *Profile) { p.Id = "123" }, "id illegal"},
+		{func(p *Profile) { p.Ip = "10.0.0" }, "ip illegal"},
+		{func(p *Profile) { p.Ipv4 = "::1" }, "ipv4 illegal"},
+		{func(p *Profile) { p.Ipv6 = "10.0.0.1" }, "ipv6 illegal"},
+		{func(p *Profile) { p.Cidr = "10.0.0.1" }, "cidr illegal"},
+		{func(p *Profile) { p.Hostname = "api_example.com" }, "hostname illegal"},
+		{func(p *Profile) { p.Mobile = "23800138000" }, "mobile illegal"},
+		{func(p *Profile) { p.Birthday = "2000/01/02" }, "birthday illegal"},
+		{func(p *Profile) { p.Gender = "unknown" }, "gender illegal"},
+		{func(p *Profile) { p.Role = "root" }, "role illegal"},
+		{func(p *Profile) { p.Offset = 11 }, "offset illegal"},
+		{func(p *Profile) { p.Offset = -11 }, "offset illegal"},
+		{func(p *Profile) { p.Ratio = 1 }, "ratio illegal"},
+		{func(p *Profile) { p.Ratio = 0 }, "ratio illegal"},
+		{func(p *Profile) { p.Retries = 2 }, "retries illegal"},
+		{func(p *Profile) { p.Level = Level(9) }, "level illegal"},
+		{func(p *Profile) { p.Level = Level_LEVEL_HIGH }, "level illegal"},
+		{func(p *Profile) { p.Tags = []string{"a", "b", "c", "d"} }, "tags capacity illegal"},
+		{func(p *Profile) { p.Tags = []string{"a", " "} }, "tags[1] required"},
+		{func(p *Profile) { p.Tags = []string{"abcdef"} }, "tags[0] length illegal"},
+		{func(p *Profile) { p.Scores = map[string]int64{"Math": 1} }, "scores[Math] illegal"},
+		{func(p *Profile) { p.Scores = map[string]int64{"math": -1} }, "scores[math] illegal"},
+		{func(p *Profile) { p.Addresses["home"].City = "" }, "addresses[home].city required"},
+		{func(p *Profile) { p.Addresses["home"].Zip = "1000" }, "addresses[home].zip illegal"},
+		{func(p *Profile) { p.History = append(p.History, &Address{}) }, "history[1].city required"},
+		{func(p *Profile) { p.Home.City = "a long city" }, "home.city length illegal"},
+		{func(p *Profile) { p.Contact = nil }, "contact required"},
+		{func(p *Profile) { p.Contact = &Profile_Wechat{Wechat: "abc"} }, "wechat length illegal"},
+		{func(p *Profile) { p.Contact = &Profile_Phone{Phone: "123"} }, "phone illegal"},
+		{func(p *Profile) { p.Remark = proto.String("more than ten runes") }, "remark length illegal"},
+	}
+
+	for _, c := range cases {
+		profile := valid()
+		c.mutate(profile)
+
+		err := profile.Validate()
+		if assert.Error(err, c.err) {
+			assert.Contains(err.Error(), c.err)
+		}
+	}
+
+	profile = valid()
+	profile.Contact = &Profile_Phone{Phone: "13800138000"}
+	profile.Remark = proto.String(" remark ")
+	assert.NoError(profile.Validate())
+	assert.Equal("remark", profile.GetRemark())
+
+	assert.Contains((&Address{}).ValidateWithPath("user.home").Error(), "user.home.city required")
+}
+
+func TestValidateAll(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(valid().ValidateAll())
+
+	profile := valid()
+	profile.Nickname = "a"
+	profile.Tags = []string{"a", ""}
+	profile.Addresses["home"].Zip = "1000"
+	profile.Birthday = "2000/01/02"
+
+	err := profile.ValidateAll()
+	violations, ok := err.(validation.Violations)
+	if !assert.True(ok) || !assert.Len(violations, 4) {
+		return
+	}
+
+	assert.Equal(validation.Violation{Field: "nickname", Rule: "min_len", Expected: "2", Actual: "a"}, exported(violations[0]))
+	assert.Equal(validation.Violation{Field: "birthday", Rule: "cst_day", Expected: "2006-01-02", Actual: "2000/01/02"}, exported(violations[1]))
+	assert.Equal(validation.Violation{Field: "tags[1]", Rule: "require"}, exported(violations[2]))
+	assert.Equal(validation.Violation{Field: "addresses[home].zip", Rule: "pattern", Expected: "^[0-9]{6}$", Actual: "1000"}, exported(violations[3]))
+	assert.Equal("nickname length illegal; birthday illegal; tags[1] required; addresses[home].zip illegal", err.Error())
+}
+
+func exported(violation *validation.Violation) validation.Violation {
+	return validation.Violation{
+		Field:    violation.Field,
+		Rule:     violation.Rule,
+		Expected: violation.Expected,
+		Actual:   violation.Actual,
+	}
+}
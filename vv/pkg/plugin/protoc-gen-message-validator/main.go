@@ -15,7 +15,7 @@ import (
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
-const version = "1.1.6"
+const version = "1.3.0"
 
 func main() {
 	showVersion := flag.Bool("version", false, "print the version and exit")
@@ -37,7 +37,7 @@ func main() {
 	})
 }
 
-// generateFile generates a _grpc.pb.go file containing gRPC service definitions.
+// generateFile generates a _message.pb.go file containing Validate methods.
 func generateFile(gen *protogen.Plugin, file *protogen.File) {
 	filename := file.GeneratedFilenamePrefix + "_message.pb.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
@@ -87,6 +87,30 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 	}
 
 	g.P("func (", prefix, "*", structName, ") Validate() error {")
+	g.P("return ", prefix, `.ValidateWithPath("")`)
+	g.P("}")
+	g.P()
+
+	g.P("// ValidateAll collect all the violations rather than the first one, the error is ", validationPackage.Ident("Violations"))
+	g.P("func (", prefix, "*", structName, ") ValidateAll() error {")
+	g.P("collector := ", validationPackage.Ident("NewCollector"), "(true)")
+	g.P(prefix, `.ValidateCollect("", collector)`)
+	g.P("return collector.Err()")
+	g.P("}")
+	g.P()
+
+	g.P("// ValidateWithPath the path prefixed to the field in error, used by nested message")
+	g.P("func (", prefix, "*", structName, ") ValidateWithPath(path string) error {")
+	g.P("collector := ", validationPackage.Ident("NewCollector"), "(false)")
+	g.P(prefix, ".ValidateCollect(path, collector)")
+	g.P("return collector.Err()")
+	g.P("}")
+	g.P()
+
+	v := &validator{g: g, structName: structName}
+
+	g.P("// ValidateCollect add the violations into collector, returns false if validating should stop")
+	g.P("func (", prefix, "*", structName, ") ValidateCollect(path string, collector *", validationPackage.Ident("Collector"), ") bool {")
 
 	for _, field := range message.Fields {
 		desc := field.Desc
@@ -104,110 +128,38 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 			" HasPresence:", desc.HasPresence(),
 		)
 
-		if desc.Kind() == protoreflect.StringKind {
-			if desc.IsList() {
-				g.P("for i := range ", prefix, ".", field.GoName, "{")
-				g.P(prefix, ".", field.GoName, "[i] = ", stringsPackage.Ident("TrimSpace"), "(", prefix, ".", field.GoName, "[i])")
-				g.P("}")
+		v.trimSpace(prefix, field)
+		v.field(prefix, field)
+	}
 
-			} else {
-				g.P(prefix, ".", field.GoName, " = ", stringsPackage.Ident("TrimSpace"), "(", prefix, ".", field.GoName, ")")
-			}
+	for _, oneof := range message.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
 		}
 
-		if fieldValidator, _ := proto.GetExtension(desc.Options(), options.E_Field).(*options.FieldValidator); fieldValidator != nil {
-			if fieldValidator.Require != nil && *fieldValidator.Require {
-				generateRequire(structName, prefix, field, g)
-			}
-
-			if fieldValidator.Eq != nil && *fieldValidator.Eq != "" {
-				generateEQNE(structName, prefix, field, "==", *fieldValidator.Eq, g)
-			}
-
-			if fieldValidator.Ne != nil && *fieldValidator.Ne != "" {
-				generateEQNE(structName, prefix, field, "!=", *fieldValidator.Ne, g)
-			}
-
-			if fieldValidator.Lt != nil {
-				generateLTLEGTGE(structName, prefix, field, "<", strconv.FormatUint(uint64(*fieldValidator.Lt), 10), g)
-			}
-
-			if fieldValidator.Le != nil {
-				generateLTLEGTGE(structName, prefix, field, "<=", strconv.FormatUint(uint64(*fieldValidator.Le), 10), g)
-			}
-
-			if fieldValidator.Gt != nil {
-				generateLTLEGTGE(structName, prefix, field, ">", strconv.FormatUint(uint64(*fieldValidator.Gt), 10), g)
-			}
-
-			if fieldValidator.Ge != nil {
-				generateLTLEGTGE(structName, prefix, field, ">=", strconv.FormatUint(uint64(*fieldValidator.Ge), 10), g)
-			}
-
-			if fieldValidator.MinCap != nil {
-				generateCAPMinMax(structName, prefix, field, ">=", strconv.FormatUint(uint64(*fieldValidator.MinCap), 10), g)
-			}
-
-			if fieldValidator.MaxCap != nil {
-				generateCAPMinMax(structName, prefix, field, "<=", strconv.FormatUint(uint64(*fieldValidator.MaxCap), 10), g)
-			}
-
-			if fieldValidator.CstDatetime != nil && *fieldValidator.CstDatetime {
-				generateCSTDatetime(structName, prefix, field, g)
-			}
-
-			if fieldValidator.CstMinute != nil && *fieldValidator.CstMinute {
-				generateCSTMinute(structName, prefix, field, g)
-			}
-
-			if fieldValidator.CstDay != nil && *fieldValidator.CstDay {
-				generateCSTDay(structName, prefix, field, g)
-			}
-
-			if fieldValidator.CnMobile != nil && *fieldValidator.CnMobile {
-				generateCNMobile(structName, prefix, field, g)
-			}
-
-			if fieldValidator.Duration != nil && *fieldValidator.Duration {
-				generateDuration(structName, prefix, field, g)
-			}
-
-		} else if desc.Kind() == protoreflect.MessageKind {
-			if desc.IsList() {
-				g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-				g.P(`if val != nil {`)
-				g.P("if err := val.Validate(); err != nil {")
-				g.P("return err")
-				g.P("}")
-				g.P("}")
-				g.P("}")
-
-			}
-
-			if desc.HasPresence() { // message{}
-				g.P()
-				g.P("if ", prefix, ".", field.GoName, " != nil {")
-				g.P("if err := ", prefix, ".", field.GoName, ".Validate(); err != nil {")
-				g.P("return err")
-				g.P("}")
-				g.P("}")
-			}
+		if oneofValidator, _ := proto.GetExtension(oneof.Desc.Options(), options.E_Oneof).(*options.OneofValidator); oneofValidator.GetRequire() {
+			v.print(v.when(code{prefix, ".", oneof.GoName, " == nil"}, v.fail(v.join(string(oneof.Desc.Name())), "require", "", "nil", "required")))
 		}
 	}
 
 	g.P()
-	g.P("return nil")
+	g.P("return true")
 	g.P("}")
 	g.P()
 
+	for _, pattern := range v.patterns {
+		g.P("var ", pattern.name, " = ", regexpPackage.Ident("MustCompile"), "(", strconv.Quote(pattern.expr), ")")
+		g.P()
+	}
+
 	for _, field := range message.Fields {
 		desc := field.Desc
 
-		if fieldValidator, _ := proto.GetExtension(desc.Options(), options.E_Field).(*options.FieldValidator); fieldValidator != nil {
+		if fieldValidator, _ := proto.GetExtension(desc.Options(), options.E_Field).(*options.FieldValidator); fieldValidator != nil && !desc.IsList() && !desc.IsMap() {
 			if fieldValidator.CstDatetime != nil && *fieldValidator.CstDatetime {
 				g.P()
 				g.P("func (", prefix, "*", structName, ") Parse", field.GoName, "() time.Time {")
-				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04:05",`, prefix, ".", field.GoName, ", time.Local)")
+				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04:05",`, prefix, ".Get", field.GoName, "(), time.Local)")
 				g.P("return ts")
 				g.P("}")
 			}
@@ -215,7 +167,7 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 			if fieldValidator.CstMinute != nil && *fieldValidator.CstMinute {
 				g.P()
 				g.P("func (", prefix, "*", structName, ") Parse", field.GoName, "() time.Time {")
-				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04",`, prefix, ".", field.GoName, ", time.Local)")
+				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04",`, prefix, ".Get", field.GoName, "(), time.Local)")
 				g.P("return ts")
 				g.P("}")
 			}
@@ -223,7 +175,7 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 			if fieldValidator.CstDay != nil && *fieldValidator.CstDay {
 				g.P()
 				g.P("func (", prefix, "*", structName, ") Parse", field.GoName, "() time.Time {")
-				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02",`, prefix, ".", field.GoName, ", time.Local)")
+				g.P("ts, _ := ", timePackage.Ident("ParseInLocation"), `("2006-01-02",`, prefix, ".Get", field.GoName, "(), time.Local)")
 				g.P("return ts")
 				g.P("}")
 			}
@@ -231,7 +183,7 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 			if fieldValidator.Duration != nil && *fieldValidator.Duration {
 				g.P()
 				g.P("func (", prefix, "*", structName, ") Parse", field.GoName, "() time.Duration {")
-				g.P("ts, _ := ", timePackage.Ident("ParseDuration"), "(", prefix, ".", field.GoName, ")")
+				g.P("ts, _ := ", timePackage.Ident("ParseDuration"), "(", prefix, ".Get", field.GoName, "())")
 				g.P("return ts")
 				g.P("}")
 			}
@@ -240,298 +192,14 @@ func generateMessage(structName, prefix string, message *protogen.Message, g *pr
 }
 
 const (
-	stringsPackage = protogen.GoImportPath("strings")
-	timePackage    = protogen.GoImportPath("time")
-	errorsPackage  = protogen.GoImportPath("github.com/bluekaki/pkg/errors")
+	stringsPackage    = protogen.GoImportPath("strings")
+	timePackage       = protogen.GoImportPath("time")
+	utf8Package       = protogen.GoImportPath("unicode/utf8")
+	regexpPackage     = protogen.GoImportPath("regexp")
+	validationPackage = protogen.GoImportPath("github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/validation")
 )
 
-func generateRequire(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-	switch desc.Kind() {
-	case protoreflect.BoolKind:
-		if desc.IsList() {
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-		}
-
-	case protoreflect.Int32Kind,
-		protoreflect.Sint32Kind,
-		protoreflect.Uint32Kind,
-		protoreflect.Int64Kind,
-		protoreflect.Sint64Kind,
-		protoreflect.Uint64Kind,
-		protoreflect.Sfixed32Kind,
-		protoreflect.Fixed32Kind,
-		protoreflect.FloatKind,
-		protoreflect.Sfixed64Kind,
-		protoreflect.Fixed64Kind,
-		protoreflect.DoubleKind:
-		if desc.IsList() {
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-		}
-
-	case protoreflect.StringKind:
-		if desc.IsList() {
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P(`if val == "" {`)
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", field.GoName, ` contains empty value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if ", prefix, ".", field.GoName, ` == "" {`)
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-		g.P("}")
-
-	case protoreflect.BytesKind:
-		if desc.IsList() {
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P(`if len(val) == 0 {`)
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", field.GoName, ` contains empty value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-		g.P("}")
-
-	case protoreflect.MessageKind: // map<,> or message{}
-		if desc.IsMap() { // map<,>
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-			return
-		}
-
-		if desc.IsList() { // repeated message{}
-			g.P()
-			g.P("if len(", prefix, ".", field.GoName, ") == 0 {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P(`if val == nil {`)
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", field.GoName, ` contains empty value")`)
-			g.P("}")
-
-			g.P("if err := val.Validate(); err != nil {")
-			g.P("return err")
-			g.P("}")
-
-			g.P("}")
-			return
-		}
-
-		if desc.HasPresence() { // message{}
-			g.P()
-			g.P("if ", prefix, ".", field.GoName, " == nil {")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` required")`)
-			g.P("}")
-
-			g.P("if err := ", prefix, ".", field.GoName, ".Validate(); err != nil {")
-			g.P("return err")
-			g.P("}")
-			return
-		}
-
-		fallthrough
-
-	default:
-		g.P("// unknow kind")
-		g.P("// ", field.GoName, " ", desc.Kind().String(), " ", desc)
-	}
-}
-
-func generateEQNE(structName, prefix string, field *protogen.Field, operation, condition string, g *protogen.GeneratedFile) {
-	condition = strings.TrimSpace(condition)
-
-	desc := field.Desc
-	switch desc.Kind() {
-	case protoreflect.BoolKind,
-		protoreflect.Int32Kind,
-		protoreflect.Sint32Kind,
-		protoreflect.Uint32Kind,
-		protoreflect.Int64Kind,
-		protoreflect.Sint64Kind,
-		protoreflect.Uint64Kind,
-		protoreflect.Sfixed32Kind,
-		protoreflect.Fixed32Kind,
-		protoreflect.FloatKind,
-		protoreflect.Sfixed64Kind,
-		protoreflect.Fixed64Kind,
-		protoreflect.DoubleKind:
-		if desc.IsList() {
-			g.P()
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P("if !(val", operation, condition, "){")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` contains illegal value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if !(", prefix, ".", field.GoName, operation, condition, "){")
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-		g.P("}")
-
-	case protoreflect.StringKind:
-		if desc.IsList() {
-			g.P()
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P("if !(val", operation, `"`, condition, `"){`)
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` contains illegal value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if !(", prefix, ".", field.GoName, operation, `"`, condition, `"){`)
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-		g.P("}")
-	}
-}
-
-func generateLTLEGTGE(structName, prefix string, field *protogen.Field, operation, condition string, g *protogen.GeneratedFile) {
-	condition = strings.TrimSpace(condition)
-
-	desc := field.Desc
-	switch desc.Kind() {
-	case protoreflect.BoolKind,
-		protoreflect.Int32Kind,
-		protoreflect.Sint32Kind,
-		protoreflect.Uint32Kind,
-		protoreflect.Int64Kind,
-		protoreflect.Sint64Kind,
-		protoreflect.Uint64Kind,
-		protoreflect.Sfixed32Kind,
-		protoreflect.Fixed32Kind,
-		protoreflect.FloatKind,
-		protoreflect.Sfixed64Kind,
-		protoreflect.Fixed64Kind,
-		protoreflect.DoubleKind:
-		if desc.IsList() {
-			g.P()
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P("if !(val", operation, condition, "){")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` contains illegal value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if !(", prefix, ".", field.GoName, operation, condition, "){")
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-		g.P("}")
-
-	case protoreflect.StringKind:
-		if desc.IsList() {
-			g.P()
-			g.P("for _, val := range ", prefix, ".", field.GoName, "{")
-			g.P("if !(len([]rune(val))", operation, condition, "){")
-			g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` contains illegal value")`)
-			g.P("}")
-			g.P("}")
-			return
-		}
-
-		g.P()
-		g.P("if !(len([]rune(", prefix, ".", field.GoName, "))", operation, condition, "){")
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-		g.P("}")
-	}
-}
-
-func generateCAPMinMax(structName, prefix string, field *protogen.Field, operation, condition string, g *protogen.GeneratedFile) {
-	condition = strings.TrimSpace(condition)
-
-	desc := field.Desc
-	if desc.IsList() || desc.IsMap() || desc.Kind() == protoreflect.BytesKind {
-		g.P()
-		g.P("if !(len(", prefix, ".", field.GoName, ")", operation, condition, "){")
-		g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` capacity illegal")`)
-		g.P("}")
-	}
-}
-
-func generateCSTDatetime(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-
-	g.P()
-	g.P("if ", prefix, ".", field.GoName, ` != "" {`)
-	g.P("if _, err := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04:05",`, prefix, ".", field.GoName, ", time.Local); err != nil {")
-	g.P("return ", errorsPackage.Ident("Wrap"), `(err, "`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-	g.P("}")
-}
-
-func generateCSTMinute(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-
-	g.P()
-	g.P("if ", prefix, ".", field.GoName, ` != "" {`)
-	g.P("if _, err := ", timePackage.Ident("ParseInLocation"), `("2006-01-02 15:04",`, prefix, ".", field.GoName, ", time.Local); err != nil {")
-	g.P("return ", errorsPackage.Ident("Wrap"), `(err, "`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-	g.P("}")
-}
-
-func generateCSTDay(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-
-	g.P()
-	g.P("if ", prefix, ".", field.GoName, ` != "" {`)
-	g.P("if _, err := ", timePackage.Ident("ParseInLocation"), `("2006-01-02",`, prefix, ".", field.GoName, ", time.Local); err != nil {")
-	g.P("return ", errorsPackage.Ident("Wrap"), `(err, "`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-	g.P("}")
-}
-
-func generateCNMobile(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-
-	g.P()
-	g.P("if len(", prefix, ".", field.GoName, ") != 11 || ", prefix, ".", field.GoName, `[0:1] != "1" {`)
-	g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-
-	g.P("for _, v := range ", prefix, ".", field.GoName, "[1:] {")
-	g.P("if v < '0' || v > '9' {")
-	g.P("return ", errorsPackage.Ident("New"), `("`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-	g.P("}")
-}
-
-func generateDuration(structName, prefix string, field *protogen.Field, g *protogen.GeneratedFile) {
-	desc := field.Desc
-
-	g.P()
-	g.P("if ", prefix, ".", field.GoName, ` != "" {`)
-	g.P("if _, err := ", timePackage.Ident("ParseDuration"), "(", prefix, ".", field.GoName, "); err != nil {")
-	g.P("return ", errorsPackage.Ident("Wrap"), `(err, "`, structName, "_", desc.Name(), ` illegal")`)
-	g.P("}")
-	g.P("}")
+func fieldValidator(desc protoreflect.FieldDescriptor) *options.FieldValidator {
+	fieldValidator, _ := proto.GetExtension(desc.Options(), options.E_Field).(*options.FieldValidator)
+	return fieldValidator
 }
@@ -26,20 +26,45 @@ type FieldValidator struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Require     *bool   `protobuf:"varint,1,opt,name=require,proto3,oneof" json:"require,omitempty"`
-	Eq          *string `protobuf:"bytes,2,opt,name=eq,proto3,oneof" json:"eq,omitempty"`                                        // equal to
-	Ne          *string `protobuf:"bytes,3,opt,name=ne,proto3,oneof" json:"ne,omitempty"`                                        // not equal to
-	Lt          *uint32 `protobuf:"varint,4,opt,name=lt,proto3,oneof" json:"lt,omitempty"`                                       // less then
-	Le          *uint32 `protobuf:"varint,5,opt,name=le,proto3,oneof" json:"le,omitempty"`                                       // less than or equal to
-	Gt          *uint32 `protobuf:"varint,6,opt,name=gt,proto3,oneof" json:"gt,omitempty"`                                       // greater than
-	Ge          *uint32 `protobuf:"varint,7,opt,name=ge,proto3,oneof" json:"ge,omitempty"`                                       // greater than or equal to
-	MaxCap      *uint32 `protobuf:"varint,8,opt,name=max_cap,json=maxCap,proto3,oneof" json:"max_cap,omitempty"`                 // capacity by slice/map/bytes
-	MinCap      *uint32 `protobuf:"varint,9,opt,name=min_cap,json=minCap,proto3,oneof" json:"min_cap,omitempty"`                 // capacity by slice/map/bytes
-	CstDatetime *bool   `protobuf:"varint,10,opt,name=cst_datetime,json=cstDatetime,proto3,oneof" json:"cst_datetime,omitempty"` // 2006-01-02 15:04:05
-	CstMinute   *bool   `protobuf:"varint,11,opt,name=cst_minute,json=cstMinute,proto3,oneof" json:"cst_minute,omitempty"`       // 2006-01-02 15:04
-	CnMobile    *bool   `protobuf:"varint,12,opt,name=cn_mobile,json=cnMobile,proto3,oneof" json:"cn_mobile,omitempty"`          // 10000000000
-	CstDay      *bool   `protobuf:"varint,13,opt,name=cst_day,json=cstDay,proto3,oneof" json:"cst_day,omitempty"`                // 2006-01-02
-	Duration    *bool   `protobuf:"varint,14,opt,name=duration,proto3,oneof" json:"duration,omitempty"`                          // 7s
+	Require     *bool           `protobuf:"varint,1,opt,name=require,proto3,oneof" json:"require,omitempty"`
+	Eq          *string         `protobuf:"bytes,2,opt,name=eq,proto3,oneof" json:"eq,omitempty"`                                        // equal to
+	Ne          *string         `protobuf:"bytes,3,opt,name=ne,proto3,oneof" json:"ne,omitempty"`                                        // not equal to
+	Lt          *uint32         `protobuf:"varint,4,opt,name=lt,proto3,oneof" json:"lt,omitempty"`                                       // less then
+	Le          *uint32         `protobuf:"varint,5,opt,name=le,proto3,oneof" json:"le,omitempty"`                                       // less than or equal to
+	Gt          *uint32         `protobuf:"varint,6,opt,name=gt,proto3,oneof" json:"gt,omitempty"`                                       // greater than
+	Ge          *uint32         `protobuf:"varint,7,opt,name=ge,proto3,oneof" json:"ge,omitempty"`                                       // greater than or equal to
+	MaxCap      *uint32         `protobuf:"varint,8,opt,name=max_cap,json=maxCap,proto3,oneof" json:"max_cap,omitempty"`                 // capacity by slice/map/bytes
+	MinCap      *uint32         `protobuf:"varint,9,opt,name=min_cap,json=minCap,proto3,oneof" json:"min_cap,omitempty"`                 // capacity by slice/map/bytes
+	CstDatetime *bool           `protobuf:"varint,10,opt,name=cst_datetime,json=cstDatetime,proto3,oneof" json:"cst_datetime,omitempty"` // 2006-01-02 15:04:05
+	CstMinute   *bool           `protobuf:"varint,11,opt,name=cst_minute,json=cstMinute,proto3,oneof" json:"cst_minute,omitempty"`       // 2006-01-02 15:04
+	CnMobile    *bool           `protobuf:"varint,12,opt,name=cn_mobile,json=cnMobile,proto3,oneof" json:"cn_mobile,omitempty"`          // 10000000000
+	CstDay      *bool           `protobuf:"varint,13,opt,name=cst_day,json=cstDay,proto3,oneof" json:"cst_day,omitempty"`                // 2006-01-02
+	Duration    *bool           `protobuf:"varint,14,opt,name=duration,proto3,oneof" json:"duration,omitempty"`                          // 7s
+	Pattern     *string         `protobuf:"bytes,15,opt,name=pattern,proto3,oneof" json:"pattern,omitempty"`                             // regular expression of RE2, skipped if empty
+	MinLen      *uint32         `protobuf:"varint,16,opt,name=min_len,json=minLen,proto3,oneof" json:"min_len,omitempty"`                // length in runes
+	MaxLen      *uint32         `protobuf:"varint,17,opt,name=max_len,json=maxLen,proto3,oneof" json:"max_len,omitempty"`                // length in runes
+	IntLt       *int64          `protobuf:"zigzag64,18,opt,name=int_lt,json=intLt,proto3,oneof" json:"int_lt,omitempty"`                 // signed less then
+	IntLe       *int64          `protobuf:"zigzag64,19,opt,name=int_le,json=intLe,proto3,oneof" json:"int_le,omitempty"`                 // signed less than or equal to
+	IntGt       *int64          `protobuf:"zigzag64,20,opt,name=int_gt,json=intGt,proto3,oneof" json:"int_gt,omitempty"`                 // signed greater than
+	IntGe       *int64          `protobuf:"zigzag64,21,opt,name=int_ge,json=intGe,proto3,oneof" json:"int_ge,omitempty"`                 // signed greater than or equal to
+	FloatLt     *float64        `protobuf:"fixed64,22,opt,name=float_lt,json=floatLt,proto3,oneof" json:"float_lt,omitempty"`            // floating-point less then
+	FloatLe     *float64        `protobuf:"fixed64,23,opt,name=float_le,json=floatLe,proto3,oneof" json:"float_le,omitempty"`            // floating-point less than or equal to
+	FloatGt     *float64        `protobuf:"fixed64,24,opt,name=float_gt,json=floatGt,proto3,oneof" json:"float_gt,omitempty"`            // floating-point greater than
+	FloatGe     *float64        `protobuf:"fixed64,25,opt,name=float_ge,json=floatGe,proto3,oneof" json:"float_ge,omitempty"`            // floating-point greater than or equal to
+	In          []string        `protobuf:"bytes,26,rep,name=in,proto3" json:"in,omitempty"`                                             // one of, names for enum
+	NotIn       []string        `protobuf:"bytes,27,rep,name=not_in,json=notIn,proto3" json:"not_in,omitempty"`                          // none of, names for enum
+	Email       *bool           `protobuf:"varint,28,opt,name=email,proto3,oneof" json:"email,omitempty"`                                // user@example.com, skipped if empty
+	Url         *bool           `protobuf:"varint,29,opt,name=url,proto3,oneof" json:"url,omitempty"`                                    // absolute url with scheme and host, skipped if empty
+	Uuid        *bool           `protobuf:"varint,30,opt,name=uuid,proto3,oneof" json:"uuid,omitempty"`                                  // 8-4-4-4-12 hex, skipped if empty
+	Ip          *bool           `protobuf:"varint,31,opt,name=ip,proto3,oneof" json:"ip,omitempty"`                                      // ipv4 or ipv6, skipped if empty
+	Ipv4        *bool           `protobuf:"varint,32,opt,name=ipv4,proto3,oneof" json:"ipv4,omitempty"`                                  // skipped if empty
+	Ipv6        *bool           `protobuf:"varint,33,opt,name=ipv6,proto3,oneof" json:"ipv6,omitempty"`                                  // skipped if empty
+	Cidr        *bool           `protobuf:"varint,34,opt,name=cidr,proto3,oneof" json:"cidr,omitempty"`                                  // 10.0.0.0/8, skipped if empty
+	Hostname    *bool           `protobuf:"varint,35,opt,name=hostname,proto3,oneof" json:"hostname,omitempty"`                          // rfc 1123, skipped if empty
+	DefinedOnly *bool           `protobuf:"varint,36,opt,name=defined_only,json=definedOnly,proto3,oneof" json:"defined_only,omitempty"` // enum value must be defined
+	Items       *FieldValidator `protobuf:"bytes,37,opt,name=items,proto3,oneof" json:"items,omitempty"`                                 // rules of each element of repeated
+	Keys        *FieldValidator `protobuf:"bytes,38,opt,name=keys,proto3,oneof" json:"keys,omitempty"`                                   // rules of each key of map
+	Values      *FieldValidator `protobuf:"bytes,39,opt,name=values,proto3,oneof" json:"values,omitempty"`                               // rules of each value of map
 }
 
 func (x *FieldValidator) Reset() {
@@ -172,18 +197,241 @@ func (x *FieldValidator) GetDuration() bool {
 	return false
 }
 
+func (x *FieldValidator) GetPattern() string {
+	if x != nil && x.Pattern != nil {
+		return *x.Pattern
+	}
+	return ""
+}
+
+func (x *FieldValidator) GetMinLen() uint32 {
+	if x != nil && x.MinLen != nil {
+		return *x.MinLen
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetMaxLen() uint32 {
+	if x != nil && x.MaxLen != nil {
+		return *x.MaxLen
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetIntLt() int64 {
+	if x != nil && x.IntLt != nil {
+		return *x.IntLt
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetIntLe() int64 {
+	if x != nil && x.IntLe != nil {
+		return *x.IntLe
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetIntGt() int64 {
+	if x != nil && x.IntGt != nil {
+		return *x.IntGt
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetIntGe() int64 {
+	if x != nil && x.IntGe != nil {
+		return *x.IntGe
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetFloatLt() float64 {
+	if x != nil && x.FloatLt != nil {
+		return *x.FloatLt
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetFloatLe() float64 {
+	if x != nil && x.FloatLe != nil {
+		return *x.FloatLe
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetFloatGt() float64 {
+	if x != nil && x.FloatGt != nil {
+		return *x.FloatGt
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetFloatGe() float64 {
+	if x != nil && x.FloatGe != nil {
+		return *x.FloatGe
+	}
+	return 0
+}
+
+func (x *FieldValidator) GetIn() []string {
+	if x != nil {
+		return x.In
+	}
+	return nil
+}
+
+func (x *FieldValidator) GetNotIn() []string {
+	if x != nil {
+		return x.NotIn
+	}
+	return nil
+}
+
+func (x *FieldValidator) GetEmail() bool {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return false
+}
+
+func (x *FieldValidator) GetUrl() bool {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return false
+}
+
+func (x *FieldValidator) GetUuid() bool {
+	if x != nil && x.Uuid != nil {
+		return *x.Uuid
+	}
+	return false
+}
+
+func (x *FieldValidator) GetIp() bool {
+	if x != nil && x.Ip != nil {
+		return *x.Ip
+	}
+	return false
+}
+
+func (x *FieldValidator) GetIpv4() bool {
+	if x != nil && x.Ipv4 != nil {
+		return *x.Ipv4
+	}
+	return false
+}
+
+func (x *FieldValidator) GetIpv6() bool {
+	if x != nil && x.Ipv6 != nil {
+		return *x.Ipv6
+	}
+	return false
+}
+
+func (x *FieldValidator) GetCidr() bool {
+	if x != nil && x.Cidr != nil {
+		return *x.Cidr
+	}
+	return false
+}
+
+func (x *FieldValidator) GetHostname() bool {
+	if x != nil && x.Hostname != nil {
+		return *x.Hostname
+	}
+	return false
+}
+
+func (x *FieldValidator) GetDefinedOnly() bool {
+	if x != nil && x.DefinedOnly != nil {
+		return *x.DefinedOnly
+	}
+	return false
+}
+
+func (x *FieldValidator) GetItems() *FieldValidator {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *FieldValidator) GetKeys() *FieldValidator {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *FieldValidator) GetValues() *FieldValidator {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type OneofValidator struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Require *bool `protobuf:"varint,1,opt,name=require,proto3,oneof" json:"require,omitempty"` // one of the fields must be set
+}
+
+func (x *OneofValidator) Reset() {
+	*x = OneofValidator{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_validator_options_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OneofValidator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OneofValidator) ProtoMessage() {}
+
+func (x *OneofValidator) ProtoReflect() protoreflect.Message {
+	mi := &file_validator_options_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OneofValidator.ProtoReflect.Descriptor instead.
+func (*OneofValidator) Descriptor() ([]byte, []int) {
+	return file_validator_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OneofValidator) GetRequire() bool {
+	if x != nil && x.Require != nil {
+		return *x.Require
+	}
+	return false
+}
+
 type MediaValidator struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ContentType *string `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3,oneof" json:"content_type,omitempty"`
+	ContentType *string  `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3,oneof" json:"content_type,omitempty"`
+	Accept      []string `protobuf:"bytes,2,rep,name=accept,proto3" json:"accept,omitempty"` // the content types accepted by upload besides content_type, e.g. image/*
 }
 
 func (x *MediaValidator) Reset() {
 	*x = MediaValidator{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_validator_options_proto_msgTypes[1]
+		mi := &file_validator_options_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -196,7 +444,7 @@ func (x *MediaValidator) String() string {
 func (*MediaValidator) ProtoMessage() {}
 
 func (x *MediaValidator) ProtoReflect() protoreflect.Message {
-	mi := &file_validator_options_proto_msgTypes[1]
+	mi := &file_validator_options_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -209,7 +457,7 @@ func (x *MediaValidator) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MediaValidator.ProtoReflect.Descriptor instead.
 func (*MediaValidator) Descriptor() ([]byte, []int) {
-	return file_validator_options_proto_rawDescGZIP(), []int{1}
+	return file_validator_options_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *MediaValidator) GetContentType() string {
@@ -219,6 +467,13 @@ func (x *MediaValidator) GetContentType() string {
 	return ""
 }
 
+func (x *MediaValidator) GetAccept() []string {
+	if x != nil {
+		return x.Accept
+	}
+	return nil
+}
+
 var file_validator_options_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
@@ -228,6 +483,14 @@ var file_validator_options_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,74600,opt,name=field",
 		Filename:      "validator_options.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.OneofOptions)(nil),
+		ExtensionType: (*OneofValidator)(nil),
+		Field:         74602,
+		Name:          "validator.oneof",
+		Tag:           "bytes,74602,opt,name=oneof",
+		Filename:      "validator_options.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
 		ExtensionType: (*MediaValidator)(nil),
@@ -244,10 +507,16 @@ var (
 	E_Field = &file_validator_options_proto_extTypes[0]
 )
 
+// Extension fields to descriptorpb.OneofOptions.
+var (
+	// optional validator.OneofValidator oneof = 74602;
+	E_Oneof = &file_validator_options_proto_extTypes[1]
+)
+
 // Extension fields to descriptorpb.MessageOptions.
 var (
 	// optional validator.MediaValidator media = 74601;
-	E_Media = &file_validator_options_proto_extTypes[1]
+	E_Media = &file_validator_options_proto_extTypes[2]
 )
 
 var File_validator_options_proto protoreflect.FileDescriptor
@@ -257,7 +526,7 @@ var file_validator_options_proto_rawDesc = []byte{
 	0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64,
 	0x61, 0x74, 0x6f, 0x72, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xab, 0x04, 0x0a, 0x0e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb0, 0x0c, 0x0a, 0x0e, 0x46, 0x69, 0x65, 0x6c, 0x64,
 	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1d, 0x0a, 0x07, 0x72, 0x65, 0x71,
 	0x75, 0x69, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x72, 0x65,
 	0x71, 0x75, 0x69, 0x72, 0x65, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x65, 0x71, 0x18, 0x02,
@@ -282,38 +551,112 @@ var file_validator_options_proto_rawDesc = []byte{
 	0x12, 0x1c, 0x0a, 0x07, 0x63, 0x73, 0x74, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28,
 	0x08, 0x48, 0x0c, 0x52, 0x06, 0x63, 0x73, 0x74, 0x44, 0x61, 0x79, 0x88, 0x01, 0x01, 0x12, 0x1f,
 	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08,
-	0x48, 0x0d, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42,
-	0x0a, 0x0a, 0x08, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f,
-	0x65, 0x71, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6e, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6c, 0x74,
-	0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6c, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x67, 0x74, 0x42, 0x05,
-	0x0a, 0x03, 0x5f, 0x67, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x61,
-	0x70, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x63, 0x61, 0x70, 0x42, 0x0f, 0x0a,
-	0x0d, 0x5f, 0x63, 0x73, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x0d,
-	0x0a, 0x0b, 0x5f, 0x63, 0x73, 0x74, 0x5f, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x42, 0x0c, 0x0a,
-	0x0a, 0x5f, 0x63, 0x6e, 0x5f, 0x6d, 0x6f, 0x62, 0x69, 0x6c, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f,
-	0x63, 0x73, 0x74, 0x5f, 0x64, 0x61, 0x79, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x64, 0x75, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x22, 0x49, 0x0a, 0x0e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x56, 0x61, 0x6c,
-	0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x26, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
-	0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b,
-	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0f,
-	0x0a, 0x0d, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x3a,
-	0x53, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
-	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xe8, 0xc6, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x48, 0x0d, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12,
+	0x1d, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x0e, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1c,
+	0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0d, 0x48,
+	0x0f, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07,
+	0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x10, 0x52,
+	0x06, 0x6d, 0x61, 0x78, 0x4c, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x06, 0x69, 0x6e,
+	0x74, 0x5f, 0x6c, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x12, 0x48, 0x11, 0x52, 0x05, 0x69, 0x6e,
+	0x74, 0x4c, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x06, 0x69, 0x6e, 0x74, 0x5f, 0x6c, 0x65,
+	0x18, 0x13, 0x20, 0x01, 0x28, 0x12, 0x48, 0x12, 0x52, 0x05, 0x69, 0x6e, 0x74, 0x4c, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x1a, 0x0a, 0x06, 0x69, 0x6e, 0x74, 0x5f, 0x67, 0x74, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x12, 0x48, 0x13, 0x52, 0x05, 0x69, 0x6e, 0x74, 0x47, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1a,
+	0x0a, 0x06, 0x69, 0x6e, 0x74, 0x5f, 0x67, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x12, 0x48, 0x14,
+	0x52, 0x05, 0x69, 0x6e, 0x74, 0x47, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x6c,
+	0x6f, 0x61, 0x74, 0x5f, 0x6c, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x01, 0x48, 0x15, 0x52, 0x07,
+	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x4c, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x6c,
+	0x6f, 0x61, 0x74, 0x5f, 0x6c, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x01, 0x48, 0x16, 0x52, 0x07,
+	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x4c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x6c,
+	0x6f, 0x61, 0x74, 0x5f, 0x67, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x01, 0x48, 0x17, 0x52, 0x07,
+	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x47, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x6c,
+	0x6f, 0x61, 0x74, 0x5f, 0x67, 0x65, 0x18, 0x19, 0x20, 0x01, 0x28, 0x01, 0x48, 0x18, 0x52, 0x07,
+	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x47, 0x65, 0x88, 0x01, 0x01, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x6e,
+	0x18, 0x1a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x02, 0x69, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x6e, 0x6f,
+	0x74, 0x5f, 0x69, 0x6e, 0x18, 0x1b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x74, 0x49,
+	0x6e, 0x12, 0x19, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x08,
+	0x48, 0x19, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03,
+	0x75, 0x72, 0x6c, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x08, 0x48, 0x1a, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x1e, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x1b, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x02,
+	0x69, 0x70, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x08, 0x48, 0x1c, 0x52, 0x02, 0x69, 0x70, 0x88, 0x01,
+	0x01, 0x12, 0x17, 0x0a, 0x04, 0x69, 0x70, 0x76, 0x34, 0x18, 0x20, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x1d, 0x52, 0x04, 0x69, 0x70, 0x76, 0x34, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x69, 0x70,
+	0x76, 0x36, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x48, 0x1e, 0x52, 0x04, 0x69, 0x70, 0x76, 0x36,
+	0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x63, 0x69, 0x64, 0x72, 0x18, 0x22, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x1f, 0x52, 0x04, 0x63, 0x69, 0x64, 0x72, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x23, 0x20, 0x01, 0x28, 0x08, 0x48, 0x20,
+	0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a,
+	0x0c, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x24, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x21, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x4f, 0x6e,
+	0x6c, 0x79, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x25,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x48,
+	0x22, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x04, 0x6b,
+	0x65, 0x79, 0x73, 0x18, 0x26, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x6f, 0x72, 0x48, 0x23, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x88, 0x01, 0x01, 0x12,
+	0x36, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x27, 0x20, 0x01, 0x28, 0x0b, 0x32,
 	0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x46, 0x69, 0x65, 0x6c,
-	0x64, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c,
-	0x64, 0x88, 0x01, 0x01, 0x3a, 0x55, 0x0a, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x1f, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xe9,
-	0xc6, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
-	0x6f, 0x72, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
-	0x72, 0x52, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x88, 0x01, 0x01, 0x42, 0x4c, 0x5a, 0x4a, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x6c, 0x75, 0x65, 0x6b, 0x61,
-	0x6b, 0x69, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x76, 0x76, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x6c,
-	0x75, 0x67, 0x69, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d,
-	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2d, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
-	0x72, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x64, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x48, 0x24, 0x52, 0x06, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x72, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x65, 0x71, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6e,
+	0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6c, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6c, 0x65, 0x42,
+	0x05, 0x0a, 0x03, 0x5f, 0x67, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x67, 0x65, 0x42, 0x0a, 0x0a,
+	0x08, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x61, 0x70, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x69,
+	0x6e, 0x5f, 0x63, 0x61, 0x70, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x63, 0x73, 0x74, 0x5f, 0x64, 0x61,
+	0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x73, 0x74, 0x5f, 0x6d,
+	0x69, 0x6e, 0x75, 0x74, 0x65, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x63, 0x6e, 0x5f, 0x6d, 0x6f, 0x62,
+	0x69, 0x6c, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x63, 0x73, 0x74, 0x5f, 0x64, 0x61, 0x79, 0x42,
+	0x0b, 0x0a, 0x09, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0a, 0x0a, 0x08,
+	0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x69, 0x6e,
+	0x5f, 0x6c, 0x65, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x65, 0x6e,
+	0x42, 0x09, 0x0a, 0x07, 0x5f, 0x69, 0x6e, 0x74, 0x5f, 0x6c, 0x74, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x69, 0x6e, 0x74, 0x5f, 0x6c, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x69, 0x6e, 0x74, 0x5f, 0x67,
+	0x74, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x69, 0x6e, 0x74, 0x5f, 0x67, 0x65, 0x42, 0x0b, 0x0a, 0x09,
+	0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x5f, 0x6c, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x6c,
+	0x6f, 0x61, 0x74, 0x5f, 0x6c, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74,
+	0x5f, 0x67, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x5f, 0x67, 0x65,
+	0x42, 0x08, 0x0a, 0x06, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x75,
+	0x72, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x42, 0x05, 0x0a, 0x03, 0x5f,
+	0x69, 0x70, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x42, 0x07, 0x0a, 0x05, 0x5f,
+	0x69, 0x70, 0x76, 0x36, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x63, 0x69, 0x64, 0x72, 0x42, 0x0b, 0x0a,
+	0x09, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x64,
+	0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x42, 0x08, 0x0a, 0x06, 0x5f,
+	0x69, 0x74, 0x65, 0x6d, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x42, 0x09,
+	0x0a, 0x07, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x3b, 0x0a, 0x0e, 0x4f, 0x6e, 0x65,
+	0x6f, 0x66, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1d, 0x0a, 0x07, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07,
+	0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x22, 0x61, 0x0a, 0x0e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x26, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x3a, 0x53, 0x0a, 0x05, 0x66, 0x69, 0x65,
+	0x6c, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0xe8, 0xc6, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x6f, 0x72, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x88, 0x01, 0x01, 0x3a, 0x53,
+	0x0a, 0x05, 0x6f, 0x6e, 0x65, 0x6f, 0x66, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4f, 0x6e, 0x65, 0x6f, 0x66, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xea, 0xc6, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4f, 0x6e, 0x65, 0x6f, 0x66,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x05, 0x6f, 0x6e, 0x65, 0x6f, 0x66,
+	0x88, 0x01, 0x01, 0x3a, 0x55, 0x0a, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x1f, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xe9, 0xc6,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x52, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x88, 0x01, 0x01, 0x42, 0x4c, 0x5a, 0x4a, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x6c, 0x75, 0x65, 0x6b, 0x61, 0x6b,
+	0x69, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x76, 0x76, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x6c, 0x75,
+	0x67, 0x69, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2d, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -328,23 +671,30 @@ func file_validator_options_proto_rawDescGZIP() []byte {
 	return file_validator_options_proto_rawDescData
 }
 
-var file_validator_options_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_validator_options_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_validator_options_proto_goTypes = []interface{}{
 	(*FieldValidator)(nil),              // 0: validator.FieldValidator
-	(*MediaValidator)(nil),              // 1: validator.MediaValidator
-	(*descriptorpb.FieldOptions)(nil),   // 2: google.protobuf.FieldOptions
-	(*descriptorpb.MessageOptions)(nil), // 3: google.protobuf.MessageOptions
+	(*OneofValidator)(nil),              // 1: validator.OneofValidator
+	(*MediaValidator)(nil),              // 2: validator.MediaValidator
+	(*descriptorpb.FieldOptions)(nil),   // 3: google.protobuf.FieldOptions
+	(*descriptorpb.OneofOptions)(nil),   // 4: google.protobuf.OneofOptions
+	(*descriptorpb.MessageOptions)(nil), // 5: google.protobuf.MessageOptions
 }
 var file_validator_options_proto_depIdxs = []int32{
-	2, // 0: validator.field:extendee -> google.protobuf.FieldOptions
-	3, // 1: validator.media:extendee -> google.protobuf.MessageOptions
-	0, // 2: validator.field:type_name -> validator.FieldValidator
-	1, // 3: validator.media:type_name -> validator.MediaValidator
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	2, // [2:4] is the sub-list for extension type_name
-	0, // [0:2] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: validator.FieldValidator.items:type_name -> validator.FieldValidator
+	0, // 1: validator.FieldValidator.keys:type_name -> validator.FieldValidator
+	0, // 2: validator.FieldValidator.values:type_name -> validator.FieldValidator
+	3, // 3: validator.field:extendee -> google.protobuf.FieldOptions
+	4, // 4: validator.oneof:extendee -> google.protobuf.OneofOptions
+	5, // 5: validator.media:extendee -> google.protobuf.MessageOptions
+	0, // 6: validator.field:type_name -> validator.FieldValidator
+	1, // 7: validator.oneof:type_name -> validator.OneofValidator
+	2, // 8: validator.media:type_name -> validator.MediaValidator
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	6, // [6:9] is the sub-list for extension type_name
+	3, // [3:6] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_validator_options_proto_init() }
@@ -366,6 +716,18 @@ func file_validator_options_proto_init() {
 			}
 		}
 		file_validator_options_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OneofValidator); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_validator_options_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MediaValidator); i {
 			case 0:
 				return &v.state
@@ -380,14 +742,15 @@ func file_validator_options_proto_init() {
 	}
 	file_validator_options_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	file_validator_options_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_validator_options_proto_msgTypes[2].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_validator_options_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
-			NumExtensions: 2,
+			NumMessages:   3,
+			NumExtensions: 3,
 			NumServices:   0,
 		},
 		GoTypes:           file_validator_options_proto_goTypes,
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// code the pieces of a line passed to g.P, idents qualified (imported) only if the line printed
+type code []interface{}
+
+func concat(pieces ...interface{}) code {
+	var line code
+	for _, piece := range pieces {
+		if c, ok := piece.(code); ok {
+			line = append(line, c...)
+			continue
+		}
+		line = append(line, piece)
+	}
+	return line
+}
+
+type pattern struct {
+	name string
+	expr string
+}
+
+// validator generate the rules of fields of a message, the path of field kept in error
+type validator struct {
+	g          *protogen.GeneratedFile
+	structName string
+	patterns   []pattern
+}
+
+func (v *validator) print(lines []code) {
+	if len(lines) == 0 {
+		return
+	}
+
+	v.g.P()
+	for _, line := range lines {
+		v.g.P(line...)
+	}
+}
+
+// join path of the field, path is the parameter of ValidateWithPath
+func (v *validator) join(name string) code {
+	return concat(validationPackage.Ident("Join"), "(path, ", strconv.Quote(name), ")")
+}
+
+// fail add a violation into collector, stop unless collecting all of them
+func (v *validator) fail(path code, rule, expected string, actual interface{}, reason string) []code {
+	return []code{
+		concat("if !collector.Add(", path, ", ", strconv.Quote(rule), ", ", strconv.Quote(expected), ", ", actual, ", ", strconv.Quote(reason), ") {"),
+		{"return false"},
+		{"}"},
+	}
+}
+
+// wrap the same as fail, err kept as the cause
+func (v *validator) wrap(path code, rule, expected string, actual interface{}) []code {
+	return []code{
+		concat("if !collector.Wrap(err, ", path, ", ", strconv.Quote(rule), ", ", strconv.Quote(expected), ", ", actual, `, "illegal") {`),
+		{"return false"},
+		{"}"},
+	}
+}
+
+// when if condition {fail}
+func (v *validator) when(condition code, fail []code) []code {
+	lines := []code{concat("if ", condition, " {")}
+	lines = append(lines, fail...)
+	return append(lines, code{"}"})
+}
+
+func (v *validator) pattern(name, expr string) string {
+	if _, err := regexp.Compile(expr); err != nil {
+		panic(fmt.Sprintf("%s_%s pattern %s illegal: %v", v.structName, name, expr, err))
+	}
+
+	name = fmt.Sprintf("_%s_%s_pattern", v.structName, name)
+	for _, pattern := range v.patterns {
+		if pattern.name == name {
+			return name
+		}
+	}
+
+	v.patterns = append(v.patterns, pattern{name: name, expr: expr})
+	return name
+}
+
+func (v *validator) trimSpace(prefix string, field *protogen.Field) {
+	desc := field.Desc
+	if desc.Kind() != protoreflect.StringKind || desc.IsMap() {
+		return
+	}
+	if oneof := desc.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		return // can not assign by getter
+	}
+
+	name := prefix + "." + field.GoName
+	switch {
+	case desc.IsList():
+		v.g.P("for idx := range ", name, "{")
+		v.g.P(name, "[idx] = ", stringsPackage.Ident("TrimSpace"), "(", name, "[idx])")
+		v.g.P("}")
+
+	case desc.HasPresence():
+		v.g.P("if ", name, " != nil {")
+		v.g.P("*", name, " = ", stringsPackage.Ident("TrimSpace"), "(*", name, ")")
+		v.g.P("}")
+
+	default:
+		v.g.P(name, " = ", stringsPackage.Ident("TrimSpace"), "(", name, ")")
+	}
+}
+
+func (v *validator) field(prefix string, field *protogen.Field) {
+	desc := field.Desc
+	rules := fieldValidator(desc)
+	path := v.join(string(desc.Name()))
+	getter := prefix + ".Get" + field.GoName + "()"
+
+	if rules.GetRequire() {
+		v.require(prefix, field, getter, path)
+	}
+
+	if rules != nil && (desc.IsList() || desc.IsMap() || desc.Kind() == protoreflect.BytesKind) {
+		var lines []code
+		if rules.MinCap != nil {
+			lines = append(lines, v.when(code{"len(", getter, ") < ", rules.GetMinCap()}, v.fail(path, "min_cap", fmt.Sprint(rules.GetMinCap()), "len("+getter+")", "capacity illegal"))...)
+		}
+		if rules.MaxCap != nil {
+			lines = append(lines, v.when(code{"len(", getter, ") > ", rules.GetMaxCap()}, v.fail(path, "max_cap", fmt.Sprint(rules.GetMaxCap()), "len("+getter+")", "capacity illegal"))...)
+		}
+		v.print(lines)
+	}
+
+	var lines []code
+	switch {
+	case desc.IsMap():
+		key, value := field.Message.Fields[0], field.Message.Fields[1]
+		elementPath := concat(validationPackage.Ident("Key"), "(", path, ", key)")
+
+		keyLines := v.value(key, rules.GetKeys(), "key", elementPath, field.GoName+"_keys", true)
+		valueLines := v.value(value, rules.GetValues(), "val", elementPath, field.GoName+"_values", true)
+		if value.Desc.Kind() == protoreflect.MessageKind {
+			valueLines = append(valueLines, v.nested("val", elementPath)...)
+		}
+
+		if len(valueLines) > 0 {
+			lines = append(lines, code{"for key, val := range ", getter, " {"})
+		} else if len(keyLines) > 0 {
+			lines = append(lines, code{"for key := range ", getter, " {"})
+		}
+		if len(lines) > 0 {
+			lines = append(lines, keyLines...)
+			lines = append(lines, valueLines...)
+			lines = append(lines, code{"}"})
+		}
+
+	case desc.IsList():
+		elementPath := concat(validationPackage.Ident("Index"), "(", path, ", idx)")
+
+		var elementLines []code
+		if rules != nil {
+			legacy := proto.Clone(rules).(*options.FieldValidator) // the rules of field applied to each element
+			legacy.MinCap, legacy.MaxCap, legacy.Items = nil, nil, nil
+
+			elementLines = append(elementLines, v.value(field, legacy, "val", elementPath, field.GoName, true)...)
+			elementLines = append(elementLines, v.value(field, rules.GetItems(), "val", elementPath, field.GoName+"_items", true)...)
+		}
+		if desc.Kind() == protoreflect.MessageKind {
+			elementLines = append(elementLines, v.nested("val", elementPath)...)
+		}
+
+		if len(elementLines) > 0 {
+			lines = append(lines, code{"for idx, val := range ", getter, " {"})
+			lines = append(lines, elementLines...)
+			lines = append(lines, code{"}"})
+		}
+
+	default:
+		lines = v.value(field, rules, getter, path, field.GoName, false)
+		if len(lines) > 0 && desc.Kind() != protoreflect.MessageKind {
+			lines = v.present(prefix, field, lines)
+		}
+		if desc.Kind() == protoreflect.MessageKind {
+			lines = append(lines,
+				code{"if sub := ", getter, "; sub != nil {"},
+				concat("if !", validationPackage.Ident("Nested"), "(", path, ", sub, collector) {"),
+				code{"return false"},
+				code{"}"},
+				code{"}"},
+			)
+		}
+	}
+
+	v.print(lines)
+}
+
+// present the rules of a member of oneof or an optional field applied only if it set
+func (v *validator) present(prefix string, field *protogen.Field, lines []code) []code {
+	var condition code
+	switch oneof := field.Oneof; {
+	case oneof != nil && !oneof.Desc.IsSynthetic():
+		condition = concat("if _, ok := ", prefix, ".", oneof.GoName, ".(*", field.GoIdent, "); ok {")
+	case field.Desc.HasPresence():
+		condition = code{"if ", prefix, ".", field.GoName, " != nil {"}
+	default:
+		return lines
+	}
+
+	return append(append([]code{condition}, lines...), code{"}"})
+}
+
+func (v *validator) nested(val string, path code) []code {
+	return []code{
+		{"if ", val, " != nil {"},
+		concat("if !", validationPackage.Ident("Nested"), "(", path, ", ", val, ", collector) {"),
+		{"return false"},
+		{"}"},
+		{"}"},
+	}
+}
+
+func (v *validator) require(prefix string, field *protogen.Field, getter string, path code) {
+	desc := field.Desc
+
+	var condition string
+	switch {
+	case desc.IsList() || desc.IsMap():
+		condition = "len(" + getter + ") == 0"
+	case desc.Kind() == protoreflect.StringKind:
+		condition = getter + ` == ""`
+	case desc.Kind() == protoreflect.BytesKind:
+		condition = "len(" + getter + ") == 0"
+	case desc.Kind() == protoreflect.MessageKind:
+		condition = getter + " == nil"
+	case desc.Kind() == protoreflect.EnumKind:
+		condition = getter + " == 0"
+	case desc.HasPresence() && (desc.ContainingOneof() == nil || desc.ContainingOneof().IsSynthetic()):
+		condition = prefix + "." + field.GoName + " == nil"
+	default:
+		v.g.P("// require of ", desc.Kind().String(), " ignored")
+		return
+	}
+
+	v.print(v.when(code{condition}, v.fail(path, "require", "", "nil", "required")))
+}
+
+// value the rules applied to a value, element means one of repeated or map, which checks require and capacity itself
+func (v *validator) value(field *protogen.Field, rules *options.FieldValidator, val string, path code, name string, element bool) []code {
+	if rules == nil {
+		return nil
+	}
+
+	var lines []code
+	check := func(rule, expected, reason string, condition ...interface{}) {
+		lines = append(lines, v.when(concat(condition...), v.fail(path, rule, expected, val, reason))...)
+	}
+	parse := func(rule, layout string) {
+		lines = append(lines, code{"if ", val, ` != "" {`})
+		lines = append(lines, v.when(concat("_, err := ", timePackage.Ident("ParseInLocation"), "(", strconv.Quote(layout), ", ", val, ", ", timePackage.Ident("Local"), "); err != nil"), v.wrap(path, rule, layout, val))...)
+		lines = append(lines, code{"}"})
+	}
+	format := func(enable bool, rule, fn string) {
+		if enable {
+			check(rule, "", "illegal", val, ` != "" && !`, validationPackage.Ident(fn), "(", val, ")")
+		}
+	}
+
+	desc := field.Desc
+	switch desc.Kind() {
+	case protoreflect.StringKind:
+		if element && rules.GetRequire() {
+			check("require", "", "required", val, ` == ""`)
+		}
+
+		if rules.GetEq() != "" {
+			check("eq", strings.TrimSpace(rules.GetEq()), "illegal", val, " != ", strconv.Quote(strings.TrimSpace(rules.GetEq())))
+		}
+		if rules.GetNe() != "" {
+			check("ne", strings.TrimSpace(rules.GetNe()), "illegal", val, " == ", strconv.Quote(strings.TrimSpace(rules.GetNe())))
+		}
+
+		length := code{utf8Package.Ident("RuneCountInString"), "(", val, ")"}
+		if rules.Lt != nil {
+			check("lt", fmt.Sprint(rules.GetLt()), "illegal", "!(", length, " < ", rules.GetLt(), ")")
+		}
+		if rules.Le != nil {
+			check("le", fmt.Sprint(rules.GetLe()), "illegal", "!(", length, " <= ", rules.GetLe(), ")")
+		}
+		if rules.Gt != nil {
+			check("gt", fmt.Sprint(rules.GetGt()), "illegal", "!(", length, " > ", rules.GetGt(), ")")
+		}
+		if rules.Ge != nil {
+			check("ge", fmt.Sprint(rules.GetGe()), "illegal", "!(", length, " >= ", rules.GetGe(), ")")
+		}
+		if rules.MinLen != nil {
+			check("min_len", fmt.Sprint(rules.GetMinLen()), "length illegal", length, " < ", rules.GetMinLen())
+		}
+		if rules.MaxLen != nil {
+			check("max_len", fmt.Sprint(rules.GetMaxLen()), "length illegal", length, " > ", rules.GetMaxLen())
+		}
+
+		if rules.GetPattern() != "" {
+			check("pattern", rules.GetPattern(), "illegal", val, ` != "" && !`, v.pattern(name, rules.GetPattern()), ".MatchString(", val, ")")
+		}
+
+		if len(rules.GetIn()) > 0 {
+			check("in", expected(rules.GetIn()), "illegal", "!(", equals(val, quote(rules.GetIn())), ")")
+		}
+		if len(rules.GetNotIn()) > 0 {
+			check("not_in", expected(rules.GetNotIn()), "illegal", equals(val, quote(rules.GetNotIn())))
+		}
+
+		if rules.GetCstDatetime() {
+			parse("cst_datetime", "2006-01-02 15:04:05")
+		}
+		if rules.GetCstMinute() {
+			parse("cst_minute", "2006-01-02 15:04")
+		}
+		if rules.GetCstDay() {
+			parse("cst_day", "2006-01-02")
+		}
+		if rules.GetCnMobile() {
+			check("cn_mobile", "", "illegal", val, ` != "" && (len(`, val, `) != 11 || `, val, `[0] != '1' || `, stringsPackage.Ident("Trim"), "(", val, `, "0123456789") != "")`)
+		}
+		if rules.GetDuration() {
+			lines = append(lines, code{"if ", val, ` != "" {`})
+			lines = append(lines, v.when(concat("_, err := ", timePackage.Ident("ParseDuration"), "(", val, "); err != nil"), v.wrap(path, "duration", "", val))...)
+			lines = append(lines, code{"}"})
+		}
+
+		format(rules.GetEmail(), "email", "IsEmail")
+		format(rules.GetUrl(), "url", "IsURL")
+		format(rules.GetUuid(), "uuid", "IsUUID")
+		format(rules.GetIp(), "ip", "IsIP")
+		format(rules.GetIpv4(), "ipv4", "IsIPv4")
+		format(rules.GetIpv6(), "ipv6", "IsIPv6")
+		format(rules.GetCidr(), "cidr", "IsCIDR")
+		format(rules.GetHostname(), "hostname", "IsHostname")
+
+	case protoreflect.BytesKind:
+		if element && rules.GetRequire() {
+			check("require", "", "required", "len(", val, ") == 0")
+		}
+		if element && rules.MinCap != nil {
+			lines = append(lines, v.when(code{"len(", val, ") < ", rules.GetMinCap()}, v.fail(path, "min_cap", fmt.Sprint(rules.GetMinCap()), "len("+val+")", "capacity illegal"))...)
+		}
+		if element && rules.MaxCap != nil {
+			lines = append(lines, v.when(code{"len(", val, ") > ", rules.GetMaxCap()}, v.fail(path, "max_cap", fmt.Sprint(rules.GetMaxCap()), "len("+val+")", "capacity illegal"))...)
+		}
+
+	case protoreflect.MessageKind:
+		if element && rules.GetRequire() {
+			check("require", "", "required", val, " == nil")
+		}
+
+	case protoreflect.EnumKind:
+		if element && rules.GetRequire() {
+			check("require", "", "required", val, " == 0")
+		}
+
+		if rules.GetDefinedOnly() {
+			names := protogen.GoIdent{GoName: field.Enum.GoIdent.GoName + "_name", GoImportPath: field.Enum.GoIdent.GoImportPath}
+			check("defined_only", "", "illegal", "_, ok := ", names, "[int32(", val, ")]; !ok")
+		}
+
+		if len(rules.GetIn()) > 0 {
+			check("in", expected(rules.GetIn()), "illegal", "!(", equals(val, v.enumValues(field, rules.GetIn())), ")")
+		}
+		if len(rules.GetNotIn()) > 0 {
+			check("not_in", expected(rules.GetNotIn()), "illegal", equals(val, v.enumValues(field, rules.GetNotIn())))
+		}
+
+	case protoreflect.BoolKind:
+		if rules.GetEq() != "" {
+			check("eq", strings.TrimSpace(rules.GetEq()), "illegal", "!(", val, " == ", strings.TrimSpace(rules.GetEq()), ")")
+		}
+		if rules.GetNe() != "" {
+			check("ne", strings.TrimSpace(rules.GetNe()), "illegal", "!(", val, " != ", strings.TrimSpace(rules.GetNe()), ")")
+		}
+
+		if len(rules.GetIn()) > 0 {
+			check("in", expected(rules.GetIn()), "illegal", "!(", equals(val, literals(desc, rules.GetIn())), ")")
+		}
+		if len(rules.GetNotIn()) > 0 {
+			check("not_in", expected(rules.GetNotIn()), "illegal", equals(val, literals(desc, rules.GetNotIn())))
+		}
+
+	case protoreflect.Int32Kind,
+		protoreflect.Sint32Kind,
+		protoreflect.Uint32Kind,
+		protoreflect.Int64Kind,
+		protoreflect.Sint64Kind,
+		protoreflect.Uint64Kind,
+		protoreflect.Sfixed32Kind,
+		protoreflect.Fixed32Kind,
+		protoreflect.FloatKind,
+		protoreflect.Sfixed64Kind,
+		protoreflect.Fixed64Kind,
+		protoreflect.DoubleKind:
+		if rules.GetEq() != "" {
+			check("eq", strings.TrimSpace(rules.GetEq()), "illegal", "!(", val, " == ", strings.TrimSpace(rules.GetEq()), ")")
+		}
+		if rules.GetNe() != "" {
+			check("ne", strings.TrimSpace(rules.GetNe()), "illegal", "!(", val, " != ", strings.TrimSpace(rules.GetNe()), ")")
+		}
+
+		if rules.Lt != nil {
+			check("lt", fmt.Sprint(rules.GetLt()), "illegal", "!(", val, " < ", rules.GetLt(), ")")
+		}
+		if rules.Le != nil {
+			check("le", fmt.Sprint(rules.GetLe()), "illegal", "!(", val, " <= ", rules.GetLe(), ")")
+		}
+		if rules.Gt != nil {
+			check("gt", fmt.Sprint(rules.GetGt()), "illegal", "!(", val, " > ", rules.GetGt(), ")")
+		}
+		if rules.Ge != nil {
+			check("ge", fmt.Sprint(rules.GetGe()), "illegal", "!(", val, " >= ", rules.GetGe(), ")")
+		}
+
+		bound := func(rule, operation string, limit *int64) {
+			if limit != nil {
+				check(rule, fmt.Sprint(*limit), "illegal", "!(", v.signed(desc, val, *limit), " ", operation, " ", *limit, ")")
+			}
+		}
+		bound("int_lt", "<", rules.IntLt)
+		bound("int_le", "<=", rules.IntLe)
+		bound("int_gt", ">", rules.IntGt)
+		bound("int_ge", ">=", rules.IntGe)
+
+		float := func(rule, operation string, limit *float64) {
+			if limit != nil {
+				if math.IsNaN(*limit) || math.IsInf(*limit, 0) {
+					panic(fmt.Sprintf("%s_%s float bound %v illegal", v.structName, name, *limit))
+				}
+				check(rule, strconv.FormatFloat(*limit, 'g', -1, 64), "illegal", "!(float64(", val, ") ", operation, " ", strconv.FormatFloat(*limit, 'g', -1, 64), ")")
+			}
+		}
+		float("float_lt", "<", rules.FloatLt)
+		float("float_le", "<=", rules.FloatLe)
+		float("float_gt", ">", rules.FloatGt)
+		float("float_ge", ">=", rules.FloatGe)
+
+		if len(rules.GetIn()) > 0 {
+			check("in", expected(rules.GetIn()), "illegal", "!(", equals(val, literals(desc, rules.GetIn())), ")")
+		}
+		if len(rules.GetNotIn()) > 0 {
+			check("not_in", expected(rules.GetNotIn()), "illegal", equals(val, literals(desc, rules.GetNotIn())))
+		}
+	}
+
+	return lines
+}
+
+// signed the conversion of value compared with a signed bound
+func (v *validator) signed(desc protoreflect.FieldDescriptor, val string, limit int64) string {
+	switch desc.Kind() {
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+		if limit < 0 {
+			panic(fmt.Sprintf("%s_%s negative bound %d of unsigned", v.structName, desc.Name(), limit))
+		}
+		return "uint64(" + val + ")"
+
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "float64(" + val + ")"
+
+	default:
+		return "int64(" + val + ")"
+	}
+}
+
+func (v *validator) enumValues(field *protogen.Field, names []string) []interface{} {
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+
+		var found *protogen.EnumValue
+		for _, value := range field.Enum.Values {
+			if string(value.Desc.Name()) == name {
+				found = value
+				break
+			}
+		}
+		if found == nil {
+			panic(fmt.Sprintf("%s_%s enum value %s not defined in %s", v.structName, field.Desc.Name(), name, field.Enum.Desc.FullName()))
+		}
+
+		values[i] = found.GoIdent
+	}
+	return values
+}
+
+// equals val == a || val == b
+func equals(val string, values []interface{}) code {
+	var condition code
+	for i, value := range values {
+		if i > 0 {
+			condition = append(condition, " || ")
+		}
+		condition = append(condition, val, " == ", value)
+	}
+	return condition
+}
+
+// expected the values of in/not_in, e.g. a,b,c
+func expected(values []string) string {
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.TrimSpace(value)
+	}
+	return strings.Join(trimmed, ",")
+}
+
+func quote(values []string) []interface{} {
+	quoted := make([]interface{}, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(strings.TrimSpace(value))
+	}
+	return quoted
+}
+
+// literals check the values of in/not_in by kind, panic if illegal
+func literals(desc protoreflect.FieldDescriptor, values []string) []interface{} {
+	trimmed := make([]interface{}, len(values))
+	for i, value := range values {
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch desc.Kind() {
+		case protoreflect.BoolKind:
+			_, err = strconv.ParseBool(value)
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+			_, err = strconv.ParseUint(value, 10, 32)
+		case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			_, err = strconv.ParseUint(value, 10, 64)
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+			_, err = strconv.ParseInt(value, 10, 32)
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			_, err = strconv.ParseInt(value, 10, 64)
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			_, err = strconv.ParseFloat(value, 64)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("%s value %s illegal of %s", desc.FullName(), value, desc.Kind()))
+		}
+
+		trimmed[i] = value
+	}
+	return trimmed
+}
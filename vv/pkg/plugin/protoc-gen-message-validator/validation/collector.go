@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+)
+
+// Violation a field broke one rule of validator
+type Violation struct {
+	Field    string `json:"field"`
+	Rule     string `json:"rule"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+
+	reason string
+	cause  error
+}
+
+func (v *Violation) Error() string {
+	return v.Field + " " + v.reason
+}
+
+// Violations all the violations of a message, returned by ValidateAll
+type Violations []*Violation
+
+func (v Violations) Error() string {
+	messages := make([]string, len(v))
+	for i, violation := range v {
+		messages[i] = violation.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Collector collect the violations, only the first one kept unless collecting all
+type Collector struct {
+	all        bool
+	violations Violations
+}
+
+// NewCollector create a collector, all means collecting all the violations
+func NewCollector(all bool) *Collector {
+	return &Collector{all: all}
+}
+
+// Add a violation, returns false if validating should stop
+func (c *Collector) Add(field, rule, expected string, actual interface{}, reason string) bool {
+	c.violations = append(c.violations, &Violation{
+		Field:    field,
+		Rule:     rule,
+		Expected: expected,
+		Actual:   format(actual),
+		reason:   reason,
+	})
+	return c.all
+}
+
+// Wrap the same as Add, err kept as the cause
+func (c *Collector) Wrap(err error, field, rule, expected string, actual interface{}, reason string) bool {
+	c.Add(field, rule, expected, actual, reason)
+	c.violations[len(c.violations)-1].cause = err
+	return c.all
+}
+
+// Violations collected
+func (c *Collector) Violations() Violations {
+	return c.violations
+}
+
+// Err nil if no violation; Violations if collecting all, otherwise an error of the first violation
+func (c *Collector) Err() error {
+	if len(c.violations) == 0 {
+		return nil
+	}
+	if c.all {
+		return c.violations
+	}
+
+	first := c.violations[0]
+	if first.cause != nil {
+		return errors.Wrap(first.cause, first.Error())
+	}
+	return errors.New(first.Error())
+}
+
+func format(actual interface{}) string {
+	switch actual := actual.(type) {
+	case nil:
+		return ""
+	case string:
+		return actual
+	case []byte:
+		return fmt.Sprintf("%d bytes", len(actual))
+	}
+	return fmt.Sprint(actual)
+}
@@ -0,0 +1,105 @@
+// Package validation the runtime helpers of code generated by protoc-gen-message-validator
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Join the path of a field, e.g. child.name
+func Join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Index the path of an element of repeated, e.g. tags[1]
+func Index(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+// Key the path of a value of map, e.g. labels[env]
+func Key(path string, key interface{}) string {
+	return path + "[" + fmt.Sprint(key) + "]"
+}
+
+// Nested validate a sub message, the path kept if it generated by protoc-gen-message-validator, returns false if validating should stop
+func Nested(path string, message interface{}, collector *Collector) bool {
+	var err error
+	switch validator := message.(type) {
+	case interface {
+		ValidateCollect(string, *Collector) bool
+	}:
+		return validator.ValidateCollect(path, collector)
+
+	case interface{ ValidateWithPath(string) error }:
+		err = validator.ValidateWithPath(path)
+
+	case interface{ Validate() error }:
+		err = validator.Validate()
+	}
+
+	if err != nil {
+		return collector.Wrap(err, path, "message", "", nil, "illegal")
+	}
+	return true
+}
+
+// IsEmail user@example.com, display name not allowed
+func IsEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return false
+	}
+	return addr.Address == value
+}
+
+// IsURL an absolute url with scheme and host
+func IsURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return u.Scheme != "" && u.Host != ""
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID 8-4-4-4-12 hex
+func IsUUID(value string) bool {
+	return uuidRegexp.MatchString(value)
+}
+
+// IsIP ipv4 or ipv6
+func IsIP(value string) bool {
+	return net.ParseIP(value) != nil
+}
+
+// IsIPv4 e.g. 10.0.0.1
+func IsIPv4(value string) bool {
+	return net.ParseIP(value) != nil && !strings.Contains(value, ":")
+}
+
+// IsIPv6 e.g. ::1
+func IsIPv6(value string) bool {
+	return net.ParseIP(value) != nil && strings.Contains(value, ":")
+}
+
+// IsCIDR e.g. 10.0.0.0/8
+func IsCIDR(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
+// IsHostname rfc 1123
+func IsHostname(value string) bool {
+	return len(value) <= 253 && hostnameRegexp.MatchString(value)
+}
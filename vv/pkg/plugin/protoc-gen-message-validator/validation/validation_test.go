@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("name", Join("", "name"))
+	assert.Equal("child.tags[1]", Index(Join("child", "tags"), 1))
+	assert.Equal("labels[env]", Key("labels", "env"))
+}
+
+func TestFormats(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsEmail("user@example.com"))
+	assert.False(IsEmail("User <user@example.com>"))
+	assert.False(IsEmail("user"))
+
+	assert.True(IsURL("https://example.com/path?q=1"))
+	assert.False(IsURL("/path"))
+	assert.False(IsURL("example.com"))
+
+	assert.True(IsUUID("123e4567-e89b-12d3-a456-426614174000"))
+	assert.False(IsUUID("123e4567e89b12d3a456426614174000"))
+
+	assert.True(IsIP("10.0.0.1"))
+	assert.True(IsIP("::1"))
+	assert.True(IsIPv4("10.0.0.1"))
+	assert.False(IsIPv4("::ffff:10.0.0.1"))
+	assert.True(IsIPv6("::1"))
+	assert.False(IsIPv6("10.0.0.1"))
+
+	assert.True(IsCIDR("10.0.0.0/8"))
+	assert.False(IsCIDR("10.0.0.0"))
+
+	assert.True(IsHostname("api.example.com"))
+	assert.False(IsHostname("-api.example.com"))
+	assert.False(IsHostname("api_example.com"))
+}
+
+func TestCollector(t *testing.T) {
+	assert := assert.New(t)
+
+	collector := NewCollector(false)
+	assert.NoError(collector.Err())
+	assert.False(collector.Add("name", "require", "", nil, "required"))
+	assert.EqualError(collector.Err(), "name required")
+
+	collector = NewCollector(true)
+	assert.True(collector.Add("name", "max_len", "2", "abc", "length illegal"))
+	assert.True(collector.Wrap(errors.New("bad layout"), "day", "cst_day", "2006-01-02", "2006/01/02", "illegal"))
+	assert.True(collector.Add("raw", "max_cap", "1", []byte("ab"), "capacity illegal"))
+
+	violations, ok := collector.Err().(Violations)
+	if assert.True(ok) && assert.Len(violations, 3) {
+		assert.Equal("abc", violations[0].Actual)
+		assert.Equal("2 bytes", violations[2].Actual)
+		assert.EqualError(violations, "name length illegal; day illegal; raw capacity illegal")
+	}
+}
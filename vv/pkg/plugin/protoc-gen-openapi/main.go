@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bluekaki/pkg/errors"
+	"github.com/bluekaki/pkg/vv/pkg/plugin/cuzerr"
+	interceptor "github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const version = "1.0.0"
+
+var (
+	flags      flag.FlagSet
+	codesFile  = flags.String("codes", "", `json file of cuzerr codes exported by cuzerr.ExportJSON, documented once as the schema vv.BzCode, e.g. [{"bz_code":1101,"http_code":400,"desc":"some business error occurs"}]`)
+	apiVersion = flags.String("api_version", "version not set", "the info.version of document")
+)
+
+// Code a cuzerr code documented as error response
+type Code struct {
+	BzCode   int    `json:"bz_code"`
+	HTTPCode int    `json:"http_code"`
+	Desc     string `json:"desc"`
+}
+
+func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-openapi %v\n", version)
+		return
+	}
+
+	protogen.Options{ParamFunc: flags.Set}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+
+		codes, err := loadCodes(*codesFile)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			if err := generateFile(gen, f, codes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func loadCodes(file string) ([]*Code, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read codes %s err", file)
+	}
+
+	var codes []*Code
+	if err = json.Unmarshal(raw, &codes); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal codes %s err", file)
+	}
+
+	for _, code := range codes {
+		if http.StatusText(code.HTTPCode) == "" {
+			return nil, errors.Errorf("bzCode %d with illegal httpCode %d", code.BzCode, code.HTTPCode)
+		}
+	}
+	return codes, nil
+}
+
+// generateFile generates a .openapi.json file containing the http rules of services.
+func generateFile(gen *protogen.Plugin, file *protogen.File, codes []*Code) error {
+	g := newGenerator(codes)
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: &Info{
+			Title:   file.Desc.Path(),
+			Version: *apiVersion,
+		},
+		Paths: make(map[string]*PathItem),
+	}
+
+	for _, service := range file.Services {
+		serviceHandler, _ := proto.GetExtension(service.Desc.Options(), interceptor.E_ServiceHandler).(*interceptor.ServiceHandler)
+
+		var exposed bool
+		for _, method := range service.Methods {
+			rule, _ := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if rule == nil {
+				continue
+			}
+			exposed = true
+
+			methodHandler, _ := proto.GetExtension(method.Desc.Options(), interceptor.E_MethodHandler).(*interceptor.MethodHandler)
+
+			rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+			for i, binding := range rules {
+				verb, template := httpPattern(binding)
+				if verb == "" {
+					return errors.Errorf("%s has illegal http rule", method.Desc.FullName())
+				}
+
+				operation, err := g.operation(service, method, serviceHandler, methodHandler, template, binding.GetBody())
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					operation.OperationID += "_" + strconv.Itoa(i)
+				}
+
+				path, _ := parseTemplate(template)
+				item := doc.Paths[path]
+				if item == nil {
+					item = &PathItem{}
+					doc.Paths[path] = item
+				}
+				(*item)[strings.ToLower(verb)] = operation
+			}
+		}
+
+		if exposed {
+			doc.Tags = append(doc.Tags, &Tag{
+				Name:        string(service.Desc.Name()),
+				Description: comment(service.Comments.Leading),
+			})
+		}
+	}
+
+	if len(doc.Paths) == 0 {
+		return nil
+	}
+
+	doc.Components = &Components{
+		Schemas:         g.schemas,
+		SecuritySchemes: g.securitySchemes,
+	}
+
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "marshal openapi of %s err", file.Desc.Path())
+	}
+
+	f := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+".openapi.json", "")
+	f.Write(raw)
+	return nil
+}
+
+type generator struct {
+	schemas         map[string]*Schema
+	securitySchemes map[string]*SecurityScheme
+}
+
+// newGenerator the codes documented once as the shared schema vv.BzCode, not on every operation
+func newGenerator(codes []*Code) *generator {
+	g := &generator{
+		schemas: map[string]*Schema{
+			statusSchema: {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"code":    {Type: "integer", Format: "int32", Description: "grpc code or bz_code of cuzerr"},
+					"message": {Type: "string"},
+					"details": {Type: "array", Items: &Schema{Type: "object"}},
+				},
+			},
+		},
+		securitySchemes: make(map[string]*SecurityScheme),
+	}
+
+	if len(codes) > 0 {
+		schema := &Schema{Type: "integer", Format: "int32"}
+
+		descs := make([]string, len(codes))
+		for i, code := range codes {
+			schema.Enum = append(schema.Enum, code.BzCode)
+			descs[i] = fmt.Sprintf("%d: %s (%d %s)", code.BzCode, code.Desc, code.HTTPCode, http.StatusText(code.HTTPCode))
+		}
+		schema.Description = "bz_code of cuzerr, " + strings.Join(descs, "; ")
+
+		g.schemas[bzCodeSchema] = schema
+		g.schemas[statusSchema].Properties["code"].Description = "grpc code or bz_code of cuzerr, see " + bzCodeSchema
+	}
+
+	return g
+}
+
+const (
+	statusSchema = "vv.Status"
+	bzCodeSchema = "vv.BzCode"
+)
+
+func (g *generator) operation(service *protogen.Service, method *protogen.Method, serviceHandler *interceptor.ServiceHandler, methodHandler *interceptor.MethodHandler, template, body string) (*Operation, error) {
+	operation := &Operation{
+		OperationID: fmt.Sprintf("%s_%s", service.Desc.Name(), method.Desc.Name()),
+		Description: comment(method.Comments.Leading),
+		Tags:        []string{string(service.Desc.Name())},
+		Responses:   make(map[string]*Response),
+	}
+
+	_, params := parseTemplate(template)
+	exclude := make(map[string]bool)
+	for _, param := range params {
+		field := findField(method.Input, param)
+		if field == nil {
+			return nil, errors.Errorf("%s path parameter %s not found in %s", method.Desc.FullName(), param, method.Input.Desc.FullName())
+		}
+
+		exclude[strings.Split(param, ".")[0]] = true
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     param,
+			In:       "path",
+			Required: true,
+			Schema:   g.fieldSchema(field),
+		})
+	}
+
+	switch body {
+	case "":
+		if !method.Desc.IsStreamingClient() {
+			operation.Parameters = append(operation.Parameters, g.queryParameters(method.Input, exclude)...)
+		}
+
+	case "*":
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: g.messageSchema(method.Input)}},
+		}
+
+	default:
+		field := findField(method.Input, body)
+		if field == nil {
+			return nil, errors.Errorf("%s body %s not found in %s", method.Desc.FullName(), body, method.Input.Desc.FullName())
+		}
+
+		exclude[body] = true
+		operation.Parameters = append(operation.Parameters, g.queryParameters(method.Input, exclude)...)
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: g.fieldSchema(field)}},
+		}
+	}
+
+	operation.Responses["200"] = g.successResponse(method)
+
+	authorization, authorizationProxy, whitelisting, rateLimit, timeout := effective(serviceHandler, methodHandler)
+	operation.Whitelisting = whitelisting
+	operation.Timeout = timeout
+
+	requirement := make(map[string][]string)
+	if authorization != "" {
+		g.securitySchemes[authorization] = &SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        http.CanonicalHeaderKey("authorization"),
+			Description: "authorization: " + authorization,
+		}
+		requirement[authorization] = []string{}
+	}
+	if authorizationProxy != "" {
+		g.securitySchemes[authorizationProxy] = &SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        http.CanonicalHeaderKey("authorization-proxy"),
+			Description: "authorization_proxy: " + authorizationProxy + ", the signature covers the Date header",
+		}
+		requirement[authorizationProxy] = []string{}
+	}
+	if len(requirement) > 0 {
+		operation.Security = []map[string][]string{requirement}
+	}
+
+	failures := make(map[int][]string)
+	if hasValidator(method.Input, make(map[string]bool)) {
+		failures[http.StatusBadRequest] = append(failures[http.StatusBadRequest], "InvalidArgument: request validation failed")
+	}
+	if authorization != "" {
+		failures[http.StatusUnauthorized] = append(failures[http.StatusUnauthorized], "Unauthenticated: authorization by "+authorization+" failed")
+	}
+	if authorizationProxy != "" {
+		failures[http.StatusForbidden] = append(failures[http.StatusForbidden], "PermissionDenied: signature by "+authorizationProxy+" does not match")
+	}
+	if whitelisting != "" {
+		failures[http.StatusConflict] = append(failures[http.StatusConflict], "Aborted: ip does not allow access by "+whitelisting)
+	}
+	if rateLimit {
+		code := cuzerr.TooManyRequests
+		failures[code.HTTPCode()] = append(failures[code.HTTPCode()], fmt.Sprintf("%d: %s", code.BzCode(), code.Desc()))
+	}
+	if timeout != "" {
+		failures[http.StatusGatewayTimeout] = append(failures[http.StatusGatewayTimeout], "DeadlineExceeded: cut off by deadline "+timeout)
+	}
+
+	for httpCode, reasons := range failures {
+		operation.Responses[strconv.Itoa(httpCode)] = statusResponse(strings.Join(reasons, "; "))
+	}
+	operation.Responses["default"] = statusResponse("An unexpected error response.")
+
+	return operation, nil
+}
+
+func (g *generator) successResponse(method *protogen.Method) *Response {
+	response := &Response{Description: "A successful response."}
+
+	if contentType := mediaContentType(method.Output); contentType != "" {
+		response.Content = map[string]*MediaType{contentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		return response
+	}
+
+	schema := g.messageSchema(method.Output)
+	if method.Desc.IsStreamingServer() {
+		response.Description = "A stream of newline-delimited results."
+		schema = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"result": schema,
+				"error":  {Ref: "#/components/schemas/" + statusSchema},
+			},
+		}
+	}
+
+	response.Content = map[string]*MediaType{"application/json": {Schema: schema}}
+	return response
+}
+
+func statusResponse(description string) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + statusSchema}},
+		},
+	}
+}
+
+// effective method overrides service
+func effective(serviceHandler *interceptor.ServiceHandler, methodHandler *interceptor.MethodHandler) (authorization, authorizationProxy, whitelisting string, rateLimit bool, timeout string) {
+	pick := func(service, method string) string {
+		if method != "" {
+			return method
+		}
+		return service
+	}
+
+	authorization = pick(serviceHandler.GetAuthorization(), methodHandler.GetAuthorization())
+	authorizationProxy = pick(serviceHandler.GetAuthorizationProxy(), methodHandler.GetAuthorizationProxy())
+	whitelisting = pick(serviceHandler.GetWhitelisting(), methodHandler.GetWhitelisting())
+	rateLimit = serviceHandler.GetRateLimit().GetRps() > 0 || methodHandler.GetRateLimit().GetRps() > 0
+	timeout = pick(serviceHandler.GetTimeout(), methodHandler.GetTimeout())
+	return
+}
+
+func httpPattern(rule *annotations.HttpRule) (verb, template string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// parseTemplate /v1/{name=messages/*}:cancel => /v1/{name}:cancel, [name]
+func parseTemplate(template string) (path string, params []string) {
+	var builder strings.Builder
+	for len(template) > 0 {
+		begin := strings.IndexByte(template, '{')
+		if begin == -1 {
+			builder.WriteString(template)
+			break
+		}
+
+		end := strings.IndexByte(template[begin:], '}')
+		if end == -1 {
+			builder.WriteString(template)
+			break
+		}
+		end += begin
+
+		param := template[begin+1 : end]
+		if index := strings.IndexByte(param, '='); index != -1 {
+			param = param[:index]
+		}
+		params = append(params, param)
+
+		builder.WriteString(template[:begin])
+		builder.WriteString("{" + param + "}")
+		template = template[end+1:]
+	}
+
+	return builder.String(), params
+}
+
+func comment(comments protogen.Comments) string {
+	return strings.TrimSpace(string(comments))
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	interceptor "github.com/bluekaki/pkg/vv/pkg/plugin/interceptor/options"
+	example "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/example"
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+	dummy "github.com/bluekaki/pkg/vv/testdata/api/gen"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// placeholders the options are imported by another path in dummy.proto
+var placeholders = map[string]protoreflect.FileDescriptor{
+	"interceptor/options.proto": interceptor.File_options_proto,
+	"validator/options.proto":   validator.File_validator_options_proto,
+}
+
+func request(fd protoreflect.FileDescriptor, parameter string) *pluginpb.CodeGeneratorRequest {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.Path()},
+		Parameter:      proto.String(parameter),
+	}
+
+	seen := make(map[string]bool)
+	var walk func(path string, fd protoreflect.FileDescriptor)
+	walk = func(path string, fd protoreflect.FileDescriptor) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+
+		if fd.IsPlaceholder() {
+			fd = placeholders[path]
+		}
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			walk(imports.Get(i).Path(), imports.Get(i).FileDescriptor)
+		}
+
+		file := protodesc.ToFileDescriptorProto(fd)
+		file.Name = proto.String(path)
+		if file.GetOptions().GetGoPackage() == "" {
+			file.Options = &descriptorpb.FileOptions{GoPackage: proto.String("example.com/" + fd.Path())}
+		}
+		req.ProtoFile = append(req.ProtoFile, file)
+	}
+	walk(fd.Path(), fd)
+
+	return req
+}
+
+func TestGenerateFile(t *testing.T) {
+	assert := assert.New(t)
+
+	codes := filepath.Join(t.TempDir(), "codes.json")
+	assert.NoError(os.WriteFile(codes, []byte(`[{"bz_code":1101,"http_code":400,"desc":"some business error occurs"}]`), 0644))
+
+	gen, err := protogen.Options{ParamFunc: flags.Set}.New(request(dummy.File_dummy_proto, "codes="+codes))
+	if !assert.NoError(err) {
+		return
+	}
+
+	loaded, err := loadCodes(*codesFile)
+	assert.NoError(err)
+	assert.NoError(generateFile(gen, gen.FilesByPath["dummy.proto"], loaded))
+
+	resp := gen.Response()
+	if !assert.Len(resp.File, 1) {
+		return
+	}
+	assert.Equal("dummy.openapi.json", resp.File[0].GetName())
+
+	doc := new(Document)
+	assert.NoError(json.Unmarshal([]byte(resp.File[0].GetContent()), doc))
+	assert.Equal("3.0.3", doc.OpenAPI)
+
+	echo := (*doc.Paths["/dummy/echo"])["get"]
+	if assert.NotNil(echo) {
+		assert.Equal("DummyService_Echo", echo.OperationID)
+		assert.Equal([]map[string][]string{{"dummy_sso": {}, "dummy_sign": {}}}, echo.Security)
+		assert.Equal("dummy_iplist", echo.Whitelisting)
+
+		if assert.Len(echo.Parameters, 1) {
+			param := echo.Parameters[0]
+			assert.Equal("message", param.Name)
+			assert.Equal("query", param.In)
+			assert.True(param.Required)
+			assert.Equal(uint64(1), *param.Schema.MinLength)
+			assert.Equal(uint64(30), *param.Schema.MaxLength)
+		}
+
+		assert.Contains(echo.Responses["400"].Description, "InvalidArgument")
+		assert.NotContains(echo.Responses["400"].Description, "1101") // documented once
+		assert.Contains(echo.Responses["401"].Description, "dummy_sso")
+		assert.Contains(echo.Responses["403"].Description, "dummy_sign")
+		assert.Contains(echo.Responses["409"].Description, "dummy_iplist")
+		assert.NotNil(echo.Responses["default"])
+	}
+
+	postEcho := (*doc.Paths["/dummy/echo"])["post"]
+	if assert.NotNil(postEcho) {
+		assert.Equal("#/components/schemas/dummy.PostEchoReq", postEcho.RequestBody.Content["application/json"].Schema.Ref)
+	}
+
+	upload := (*doc.Paths["/dummy/upload/{file_name}"])["post"]
+	if assert.NotNil(upload) && assert.Len(upload.Parameters, 1) {
+		assert.Equal("path", upload.Parameters[0].In)
+		assert.Equal("byte", upload.RequestBody.Content["application/json"].Schema.Format)
+	}
+
+	picture := (*doc.Paths["/dummy/picture"])["get"]
+	if assert.NotNil(picture) {
+		assert.Equal("binary", picture.Responses["200"].Content["image/png"].Schema.Format)
+		assert.Nil(picture.Security)
+	}
+
+	stream := (*doc.Paths["/dummy/stream/echo"])["get"]
+	if assert.NotNil(stream) {
+		assert.Equal("#/components/schemas/dummy.EchoResp", stream.Responses["200"].Content["application/json"].Schema.Properties["result"].Ref)
+	}
+
+	schema := doc.Components.Schemas["dummy.PostEchoReq"]
+	if assert.NotNil(schema) {
+		assert.Equal([]string{"name", "message"}, schema.Required)
+	}
+	assert.Equal("Authorization-Proxy", doc.Components.SecuritySchemes["dummy_sign"].Name)
+
+	bzCode := doc.Components.Schemas["vv.BzCode"]
+	if assert.NotNil(bzCode) {
+		assert.Equal([]interface{}{1101.0}, bzCode.Enum)
+		assert.Contains(bzCode.Description, "1101: some business error occurs (400 Bad Request)")
+	}
+	assert.Contains(doc.Components.Schemas["vv.Status"].Properties["code"].Description, "vv.BzCode")
+}
+
+func TestParseTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	path, params := parseTemplate("/v1/{name=messages/*}/books/{book.id}:cancel")
+	assert.Equal("/v1/{name}/books/{book.id}:cancel", path)
+	assert.Equal([]string{"name", "book.id"}, params)
+}
+
+func TestApplyValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	gen, err := protogen.Options{}.New(request(example.File_example_proto, ""))
+	if !assert.NoError(err) {
+		return
+	}
+
+	g := newGenerator(nil)
+	g.messageSchema(gen.FilesByPath["example.proto"].Messages[1])
+
+	profile := g.schemas["example.Profile"].Properties
+	assert.Equal(uint64(2), *profile["nickname"].MinLength)
+	assert.Equal(uint64(4), *profile["nickname"].MaxLength)
+	assert.Equal("email", profile["email"].Format)
+	assert.Equal("uri", profile["homepage"].Format)
+	assert.Equal("hostname", profile["hostname"].Format)
+	assert.Equal([]interface{}{"male", "female"}, profile["gender"].Enum)
+	assert.Equal([]interface{}{"root"}, profile["role"].Not.Enum)
+	assert.Equal(-10.0, *profile["offset"].Minimum)
+	assert.Equal(10.0, *profile["offset"].Maximum)
+	assert.True(profile["ratio"].ExclusiveMinimum)
+	assert.Equal([]interface{}{1.0, 3.0, 5.0}, profile["retries"].Enum)
+	assert.Equal([]interface{}{"LEVEL_UNSPECIFIED", "LEVEL_LOW"}, profile["level"].Enum)
+	assert.Equal(uint64(3), *profile["tags"].MaxItems)
+	assert.Equal(uint64(5), *profile["tags"].Items.MaxLength)
+	assert.Equal(0.0, *profile["scores"].AdditionalProperties.Minimum)
+
+	address := g.schemas["example.Address"]
+	assert.Equal([]string{"city"}, address.Required)
+	assert.Equal("^[0-9]{6}$", address.Properties["zip"].Pattern)
+}
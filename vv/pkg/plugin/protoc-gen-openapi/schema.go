@@ -0,0 +1,495 @@
+package main
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	validator "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/options"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	cstDatetimePattern = `^$|^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`
+	cstMinutePattern   = `^$|^\d{4}-\d{2}-\d{2} \d{2}:\d{2}$`
+	cstDayPattern      = `^$|^\d{4}-\d{2}-\d{2}$`
+	cnMobilePattern    = `^1\d{10}$`
+	durationPattern    = `^$|^-?(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`
+)
+
+// wellKnown schemas of well known types, follow the protojson mapping
+var wellKnown = map[protoreflect.FullName]func() *Schema{
+	"google.protobuf.Timestamp": func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+	"google.protobuf.Duration":  func() *Schema { return &Schema{Type: "string", Example: "1.5s"} },
+	"google.protobuf.FieldMask": func() *Schema { return &Schema{Type: "string"} },
+	"google.protobuf.Empty":     func() *Schema { return &Schema{Type: "object"} },
+	"google.protobuf.Struct":    func() *Schema { return &Schema{Type: "object", AdditionalProperties: &Schema{}} },
+	"google.protobuf.Value":     func() *Schema { return &Schema{} },
+	"google.protobuf.ListValue": func() *Schema { return &Schema{Type: "array", Items: &Schema{}} },
+	"google.protobuf.Any": func() *Schema {
+		return &Schema{Type: "object", Properties: map[string]*Schema{"@type": {Type: "string"}}}
+	},
+	"google.protobuf.DoubleValue": func() *Schema { return &Schema{Type: "number", Format: "double"} },
+	"google.protobuf.FloatValue":  func() *Schema { return &Schema{Type: "number", Format: "float"} },
+	"google.protobuf.Int64Value":  func() *Schema { return &Schema{Type: "string", Format: "int64"} },
+	"google.protobuf.UInt64Value": func() *Schema { return &Schema{Type: "string", Format: "uint64"} },
+	"google.protobuf.Int32Value":  func() *Schema { return &Schema{Type: "integer", Format: "int32"} },
+	"google.protobuf.UInt32Value": func() *Schema { return &Schema{Type: "integer", Format: "int64"} },
+	"google.protobuf.BoolValue":   func() *Schema { return &Schema{Type: "boolean"} },
+	"google.protobuf.StringValue": func() *Schema { return &Schema{Type: "string"} },
+	"google.protobuf.BytesValue":  func() *Schema { return &Schema{Type: "string", Format: "byte"} },
+}
+
+// messageSchema register the message into components, return a $ref
+func (g *generator) messageSchema(message *protogen.Message) *Schema {
+	if schema, ok := wellKnown[message.Desc.FullName()]; ok {
+		return schema()
+	}
+
+	name := string(message.Desc.FullName())
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+
+	schema := &Schema{
+		Type:        "object",
+		Description: comment(message.Comments.Leading),
+		Properties:  make(map[string]*Schema),
+	}
+	g.schemas[name] = schema // placeholder for recursive message
+
+	for _, field := range message.Fields {
+		schema.Properties[string(field.Desc.Name())] = g.fieldSchema(field)
+		if required(field) {
+			schema.Required = append(schema.Required, string(field.Desc.Name()))
+		}
+	}
+
+	return ref
+}
+
+// fieldSchema the schema of field with the constraints of validator
+func (g *generator) fieldSchema(field *protogen.Field) *Schema {
+	var schema *Schema
+	switch {
+	case field.Desc.IsMap():
+		schema = &Schema{Type: "object", AdditionalProperties: g.kindSchema(field.Message.Fields[1])}
+	case field.Desc.IsList():
+		schema = &Schema{Type: "array", Items: g.kindSchema(field)}
+	default:
+		schema = g.kindSchema(field)
+	}
+
+	if description := comment(field.Comments.Leading); description != "" && schema.Ref == "" {
+		schema.Description = description
+	}
+
+	applyValidator(schema, field)
+	return schema
+}
+
+func (g *generator) kindSchema(field *protogen.Field) *Schema {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		schema := &Schema{Type: "string"}
+		for _, value := range field.Enum.Values {
+			schema.Enum = append(schema.Enum, string(value.Desc.Name()))
+		}
+		return schema
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageSchema(field.Message)
+	}
+	return &Schema{}
+}
+
+// queryParameters scalar fields of the top level, exclude those in path or body
+func (g *generator) queryParameters(message *protogen.Message, exclude map[string]bool) []*Parameter {
+	var params []*Parameter
+	for _, field := range message.Fields {
+		if exclude[string(field.Desc.Name())] || field.Desc.IsMap() {
+			continue
+		}
+
+		if kind := field.Desc.Kind(); kind == protoreflect.MessageKind || kind == protoreflect.GroupKind {
+			if _, ok := wellKnown[field.Message.Desc.FullName()]; !ok {
+				continue
+			}
+		}
+
+		params = append(params, &Parameter{
+			Name:     string(field.Desc.Name()),
+			In:       "query",
+			Required: required(field),
+			Schema:   g.fieldSchema(field),
+		})
+	}
+	return params
+}
+
+// findField by dotted path, e.g. book.name
+func findField(message *protogen.Message, path string) *protogen.Field {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		var found *protogen.Field
+		for _, field := range message.Fields {
+			if string(field.Desc.Name()) == name {
+				found = field
+				break
+			}
+		}
+
+		if found == nil {
+			return nil
+		}
+		if i == len(names)-1 {
+			return found
+		}
+		if found.Message == nil {
+			return nil
+		}
+		message = found.Message
+	}
+	return nil
+}
+
+func fieldValidator(field *protogen.Field) *validator.FieldValidator {
+	rules, _ := proto.GetExtension(field.Desc.Options(), validator.E_Field).(*validator.FieldValidator)
+	return rules
+}
+
+func required(field *protogen.Field) bool {
+	return fieldValidator(field).GetRequire()
+}
+
+// hasValidator the message or its sub messages declared any validator
+func hasValidator(message *protogen.Message, visited map[string]bool) bool {
+	name := string(message.Desc.FullName())
+	if visited[name] {
+		return false
+	}
+	visited[name] = true
+
+	for _, field := range message.Fields {
+		if fieldValidator(field) != nil {
+			return true
+		}
+		if field.Message != nil && hasValidator(field.Message, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func mediaContentType(message *protogen.Message) string {
+	media, _ := proto.GetExtension(message.Desc.Options(), validator.E_Media).(*validator.MediaValidator)
+	if media.GetContentType() == "" {
+		return ""
+	}
+
+	contentType, _, err := mime.ParseMediaType(media.GetContentType())
+	if err != nil {
+		panic(err)
+	}
+	return contentType
+}
+
+// applyValidator map the rules of FieldValidator to json schema keywords, the same semantic as protoc-gen-message-validator
+func applyValidator(schema *Schema, field *protogen.Field) {
+	rules := fieldValidator(field)
+	if rules == nil {
+		return
+	}
+
+	desc := field.Desc
+	element := schema
+	if desc.IsList() {
+		element = schema.Items
+	}
+
+	if rules.GetRequire() {
+		switch {
+		case desc.IsMap():
+			schema.MinProperties = uint64Ptr(1)
+		case desc.IsList():
+			schema.MinItems = uint64Ptr(1)
+			if desc.Kind() == protoreflect.StringKind || desc.Kind() == protoreflect.BytesKind {
+				element.MinLength = uint64Ptr(1)
+			}
+		case desc.Kind() == protoreflect.StringKind || desc.Kind() == protoreflect.BytesKind:
+			schema.MinLength = uint64Ptr(1)
+		}
+	}
+
+	if desc.IsMap() {
+		if rules.MinCap != nil {
+			schema.MinProperties = uint64Ptr(uint64(rules.GetMinCap()))
+		}
+		if rules.MaxCap != nil {
+			schema.MaxProperties = uint64Ptr(uint64(rules.GetMaxCap()))
+		}
+
+		applyValue(schema.AdditionalProperties, field.Message.Fields[1], rules.GetValues())
+		return
+	}
+
+	if desc.IsList() {
+		if rules.MinCap != nil {
+			schema.MinItems = uint64Ptr(uint64(rules.GetMinCap()))
+		}
+		if rules.MaxCap != nil {
+			schema.MaxItems = uint64Ptr(uint64(rules.GetMaxCap()))
+		}
+
+	} else if desc.Kind() == protoreflect.BytesKind && (rules.MinCap != nil || rules.MaxCap != nil) {
+		var capacity []string
+		if rules.MinCap != nil {
+			capacity = append(capacity, "at least "+strconv.FormatUint(uint64(rules.GetMinCap()), 10)+" bytes")
+		}
+		if rules.MaxCap != nil {
+			capacity = append(capacity, "at most "+strconv.FormatUint(uint64(rules.GetMaxCap()), 10)+" bytes")
+		}
+		schema.Description = strings.TrimSpace(schema.Description + " " + strings.Join(capacity, ", "))
+	}
+
+	applyValue(element, field, rules)
+	if desc.IsList() {
+		applyValue(element, field, rules.GetItems())
+	}
+}
+
+// applyValue the rules of a value, or an element of repeated or map
+func applyValue(element *Schema, field *protogen.Field, rules *validator.FieldValidator) {
+	if rules == nil || element == nil {
+		return
+	}
+
+	kind := field.Desc.Kind()
+	if rules.GetRequire() && (kind == protoreflect.StringKind || kind == protoreflect.BytesKind) {
+		element.MinLength = uint64Ptr(1)
+	}
+
+	switch kind {
+	case protoreflect.StringKind:
+		if rules.GetEq() != "" {
+			element.Enum = []interface{}{strings.TrimSpace(rules.GetEq())}
+		}
+		if rules.GetNe() != "" {
+			element.Not = &Schema{Enum: []interface{}{strings.TrimSpace(rules.GetNe())}}
+		}
+
+		// the length in runes
+		if rules.Lt != nil && rules.GetLt() > 0 {
+			element.MaxLength = uint64Ptr(uint64(rules.GetLt()) - 1)
+		}
+		if rules.Le != nil {
+			element.MaxLength = uint64Ptr(uint64(rules.GetLe()))
+		}
+		if rules.Gt != nil {
+			element.MinLength = uint64Ptr(uint64(rules.GetGt()) + 1)
+		}
+		if rules.Ge != nil {
+			element.MinLength = uint64Ptr(uint64(rules.GetGe()))
+		}
+
+		if rules.MinLen != nil {
+			element.MinLength = uint64Ptr(uint64(rules.GetMinLen()))
+		}
+		if rules.MaxLen != nil {
+			element.MaxLength = uint64Ptr(uint64(rules.GetMaxLen()))
+		}
+		if rules.GetPattern() != "" {
+			element.Pattern = rules.GetPattern()
+		}
+		for _, value := range rules.GetIn() {
+			element.Enum = append(element.Enum, value)
+		}
+		if len(rules.GetNotIn()) > 0 {
+			element.Not = &Schema{}
+			for _, value := range rules.GetNotIn() {
+				element.Not.Enum = append(element.Not.Enum, value)
+			}
+		}
+
+		switch {
+		case rules.GetEmail():
+			element.Format = "email"
+		case rules.GetUrl():
+			element.Format = "uri"
+		case rules.GetUuid():
+			element.Format = "uuid"
+		case rules.GetIp():
+			element.Format = "ip"
+		case rules.GetIpv4():
+			element.Format = "ipv4"
+		case rules.GetIpv6():
+			element.Format = "ipv6"
+		case rules.GetCidr():
+			element.Format = "cidr"
+		case rules.GetHostname():
+			element.Format = "hostname"
+		}
+
+		switch {
+		case rules.GetCstDatetime():
+			element.Pattern, element.Example = cstDatetimePattern, "2006-01-02 15:04:05"
+		case rules.GetCstMinute():
+			element.Pattern, element.Example = cstMinutePattern, "2006-01-02 15:04"
+		case rules.GetCstDay():
+			element.Pattern, element.Example = cstDayPattern, "2006-01-02"
+		case rules.GetCnMobile():
+			element.Pattern, element.Example = cnMobilePattern, "13800000000"
+		case rules.GetDuration():
+			element.Pattern, element.Example = durationPattern, "7s"
+		}
+
+	case protoreflect.BoolKind,
+		protoreflect.Int32Kind,
+		protoreflect.Sint32Kind,
+		protoreflect.Uint32Kind,
+		protoreflect.Int64Kind,
+		protoreflect.Sint64Kind,
+		protoreflect.Uint64Kind,
+		protoreflect.Sfixed32Kind,
+		protoreflect.Fixed32Kind,
+		protoreflect.FloatKind,
+		protoreflect.Sfixed64Kind,
+		protoreflect.Fixed64Kind,
+		protoreflect.DoubleKind:
+		if value, ok := scalar(kind, rules.GetEq()); ok {
+			element.Enum = []interface{}{value}
+		}
+		if value, ok := scalar(kind, rules.GetNe()); ok {
+			element.Not = &Schema{Enum: []interface{}{value}}
+		}
+
+		if rules.Lt != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(float64(rules.GetLt())), true
+		}
+		if rules.Le != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(float64(rules.GetLe())), false
+		}
+		if rules.Gt != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(float64(rules.GetGt())), true
+		}
+		if rules.Ge != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(float64(rules.GetGe())), false
+		}
+
+		if rules.IntLt != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(float64(rules.GetIntLt())), true
+		}
+		if rules.IntLe != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(float64(rules.GetIntLe())), false
+		}
+		if rules.IntGt != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(float64(rules.GetIntGt())), true
+		}
+		if rules.IntGe != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(float64(rules.GetIntGe())), false
+		}
+		if rules.FloatLt != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(rules.GetFloatLt()), true
+		}
+		if rules.FloatLe != nil {
+			element.Maximum, element.ExclusiveMaximum = float64Ptr(rules.GetFloatLe()), false
+		}
+		if rules.FloatGt != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(rules.GetFloatGt()), true
+		}
+		if rules.FloatGe != nil {
+			element.Minimum, element.ExclusiveMinimum = float64Ptr(rules.GetFloatGe()), false
+		}
+
+		for _, condition := range rules.GetIn() {
+			if value, ok := scalar(kind, condition); ok {
+				element.Enum = append(element.Enum, value)
+			}
+		}
+		for _, condition := range rules.GetNotIn() {
+			if value, ok := scalar(kind, condition); ok {
+				if element.Not == nil {
+					element.Not = &Schema{}
+				}
+				element.Not.Enum = append(element.Not.Enum, value)
+			}
+		}
+
+	case protoreflect.EnumKind:
+		// the names of enum already listed, defined_only implied
+		if len(rules.GetIn()) > 0 {
+			element.Enum = nil
+			for _, value := range rules.GetIn() {
+				element.Enum = append(element.Enum, strings.TrimSpace(value))
+			}
+		}
+		if len(rules.GetNotIn()) > 0 {
+			excluded := make(map[string]bool)
+			for _, value := range rules.GetNotIn() {
+				excluded[strings.TrimSpace(value)] = true
+			}
+
+			enum := element.Enum[:0]
+			for _, value := range element.Enum {
+				if !excluded[value.(string)] {
+					enum = append(enum, value)
+				}
+			}
+			element.Enum = enum
+		}
+	}
+}
+
+// scalar the json value of eq/ne, 64-bit integers are strings in protojson
+func scalar(kind protoreflect.Kind, condition string) (interface{}, bool) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return nil, false
+	}
+
+	switch kind {
+	case protoreflect.BoolKind:
+		value, err := strconv.ParseBool(condition)
+		return value, err == nil
+
+	case protoreflect.Int64Kind,
+		protoreflect.Sint64Kind,
+		protoreflect.Uint64Kind,
+		protoreflect.Sfixed64Kind,
+		protoreflect.Fixed64Kind:
+		return condition, true
+
+	default:
+		value, err := strconv.ParseFloat(condition, 64)
+		return value, err == nil
+	}
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,21 @@
+package proposal
+
+import (
+	"time"
+)
+
+// BridgeConfig the config of bridging streaming methods over websocket and server-sent events
+type BridgeConfig struct {
+	// PingInterval ping the websocket or comment the event stream in it, default 30s
+	PingInterval time.Duration
+	// PongTimeout the websocket closed if nothing received in it, default twice PingInterval
+	PongTimeout time.Duration
+	// WriteTimeout a message not written in it closes the connection, default 10s
+	WriteTimeout time.Duration
+	// MaxMessageSize the max size of a websocket message received, default 1MB
+	MaxMessageSize int64
+	// MaxPendingMessages the websocket messages received but not consumed by the stream, exceeded closes the connection, default 16
+	MaxPendingMessages int
+	// CheckOrigin check the Origin of websocket upgrade, default the same host only
+	CheckOrigin func(origin string) bool
+}
@@ -0,0 +1,31 @@
+package proposal
+
+// Stage a built-in step of interceptor
+type Stage string
+
+const (
+	// StageJournal log the req/resp payload, recover panic and send alert; always the outermost built-in stage
+	StageJournal Stage = "journal"
+	// StageWhitelisting filter ip by options.whitelisting, gateway only
+	StageWhitelisting Stage = "whitelisting"
+	// StageValidation validate request message, server only
+	StageValidation Stage = "validation"
+	// StagePeerCertificate authorize the verified client certificate of mtls by options.peer_certificate, server only
+	StagePeerCertificate Stage = "peer_certificate"
+	// StageAuthorization verify sso by options.authorization, server only
+	StageAuthorization Stage = "authorization"
+	// StageAuthorizationProxy verify signature by options.authorization_proxy on server, or do sign on client
+	StageAuthorizationProxy Stage = "authorization_proxy"
+	// StageRateLimit throttle by options.rate_limit, server only
+	StageRateLimit Stage = "rate_limit"
+)
+
+// Position where a custom interceptor placed relative to a built-in stage
+type Position int
+
+const (
+	// Before run before the stage
+	Before Position = iota + 1
+	// After run after the stage
+	After
+)
@@ -0,0 +1,23 @@
+package proposal
+
+import (
+	"time"
+)
+
+// CORSPolicy the cors policy of gateway, options.cors of method overrides it
+type CORSPolicy struct {
+	// AllowedOrigins exact e.g. https://example.com, or wildcard subdomain e.g. https://*.example.com, "*" means all
+	AllowedOrigins []string
+	// AllowOriginFunc check the origin by callback, AllowedOrigins ignored if set
+	AllowOriginFunc func(origin string) bool
+	// AllowedMethods default HEAD, GET and POST
+	AllowedMethods []string
+	// AllowedHeaders the non simple headers, "*" means all
+	AllowedHeaders []string
+	// ExposedHeaders the headers could be read by browser
+	ExposedHeaders []string
+	// AllowCredentials cookies, authorization headers or tls client certificates
+	AllowCredentials bool
+	// MaxAge how long the preflight cached
+	MaxAge time.Duration
+}
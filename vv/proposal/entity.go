@@ -11,11 +11,57 @@ import (
 type AlertMessage struct {
 	ProjectName  string            `json:"project_name,omitempty"`
 	JournalID    string            `json:"journal_id,omitempty"`
+	Method       string            `json:"method,omitempty"`
+	BzCode       int               `json:"bz_code,omitempty"`
+	Origin       string            `json:"origin,omitempty"` // where the error created, e.g. function file:line
+	Severity     Severity          `json:"severity,omitempty"`
+	Occurrences  int               `json:"occurrences,omitempty"` // more than 1 if it's a summary of the suppressed same alerts
 	ErrorVerbose string            `json:"error_verbose,omitempty"`
 	Meta         *AlertMessageMeta `json:"meta,omitempty"`
 	Timestamp    time.Time         `json:"timestamp,omitempty"`
 }
 
+// Severity the level of alert, zero value treated as SeverityError
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "error"
+}
+
+// MarshalText marshal as the name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText unmarshal from the name
+func (s *Severity) UnmarshalText(text []byte) error {
+	for severity, name := range severityNames {
+		if name == string(text) {
+			*s = severity
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown severity %s", text)
+}
+
 // AlertMessageMeta some optional meta
 type AlertMessageMeta struct {
 	URL        string `json:"url,omitempty"`
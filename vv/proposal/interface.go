@@ -1,7 +1,12 @@
 package proposal
 
 import (
+	"context"
+	"time"
+
 	"github.com/bluekaki/pkg/errors"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // Code some enum with business/http code, and some short descriptive information.
@@ -19,6 +24,11 @@ type BzError interface {
 	StackErr() errors.Error
 }
 
+// DetailedError an error carries google.rpc error details, e.g. ErrorInfo, RetryInfo, QuotaFailure and ResourceInfo
+type DetailedError interface {
+	Details() []proto.Message
+}
+
 // AlertError a critical error, which will send an alert.
 type AlertError interface {
 	error
@@ -31,6 +41,11 @@ type Validator interface {
 	Validate() error
 }
 
+// AllValidator collect all the violations of protobuf message fields
+type AllValidator interface {
+	ValidateAll() error
+}
+
 // NotifyHandler a handler for send alert
 type NotifyHandler func(msg *AlertMessage)
 
@@ -51,8 +66,46 @@ type Payload interface {
 // UserinfoHandler a handler for sso
 type UserinfoHandler func(authorization string, payload Payload) (userinfo interface{}, err error)
 
+// ScopedUserinfo the userinfo granted scopes, checked against options.scopes of method
+type ScopedUserinfo interface {
+	HasScope(scope string) bool
+}
+
+// Authorizer tell the roles and permissions granted to the userinfo returned by the UserinfoHandler of the same name,
+// checked against options.roles and options.permissions
+type Authorizer interface {
+	HasRole(userinfo interface{}, role string) bool
+	HasPermission(userinfo interface{}, permission string) bool
+}
+
 // SignatureHandler a handler for verify signature
 type SignatureHandler func(authorizationProxy string, payload Payload) (identifier string, ok bool, err error)
 
 // WhitelistingHandler a handler for filter ip
 type WhitelistingHandler func(xForwardedFor string) (ok bool, err error)
+
+// ReadinessCheck a named check for readiness, e.g. ping redis, should return before ctx done
+type ReadinessCheck func(ctx context.Context) error
+
+// IdempotencyStore keep the responses of options.idempotent methods by Idempotency-Key
+type IdempotencyStore interface {
+	// Acquire lock the key for lockTTL while the request in flight; the stored response returned if completed already,
+	// acquired false if another one in flight
+	Acquire(key string, lockTTL time.Duration) (acquired bool, response []byte, err error)
+	// Complete store the response of key for ttl, the lock released
+	Complete(key string, response []byte, ttl time.Duration) error
+	// Release the lock of key without a response, so that the request could be retried
+	Release(key string) error
+	// Close the store
+	Close() error
+}
+
+// CacheStore keep the responses of options.cache methods on gateway
+type CacheStore interface {
+	// Get the value of key, ok false if missing or expired
+	Get(key string) (value []byte, ok bool, err error)
+	// Set the value of key for ttl, never expired if ttl is 0
+	Set(key string, value []byte, ttl time.Duration) error
+	// Close the store
+	Close() error
+}
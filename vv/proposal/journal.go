@@ -0,0 +1,36 @@
+package proposal
+
+import (
+	"time"
+)
+
+// Journal a journal written by interceptor, raw is the redacted json of it
+type Journal struct {
+	ID          string
+	Interceptor string // e.g. server unary interceptor
+	Method      string
+	Success     bool
+	Raw         []byte
+}
+
+// JournalSink somewhere journals written to besides the logger, e.g. files, queue
+type JournalSink interface {
+	// Write a batch of journals in order, should not retain the slice
+	Write(journals []*Journal) error
+	// Close flush and release the sink
+	Close() error
+}
+
+// JournalConfig customize the sampling and batching of journal sinks, zero value means the default
+type JournalConfig struct {
+	// SuccessRatio the ratio of successful journals written in (0, 1], default 1; negative means none, failed ones always written
+	SuccessRatio float64
+	// BatchSize default 100
+	BatchSize int
+	// FlushInterval flush the batch even if not full, default 1s
+	FlushInterval time.Duration
+	// QueueSize the pending journals, default 10000
+	QueueSize int
+	// MaxWait how long a request blocked when the queue is full before the journal dropped, default 100ms
+	MaxWait time.Duration
+}
@@ -0,0 +1,25 @@
+package proposal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsConfig customize the prometheus metrics, zero value means the default
+type MetricsConfig struct {
+	// Registry register and gather the metrics, prometheus.DefaultRegisterer and prometheus.DefaultGatherer if nil
+	Registry *prometheus.Registry
+	// Namespace default bluekaki
+	Namespace string
+	// Subsystem default vv
+	Subsystem string
+	// Buckets of duration in seconds, default 0.1 to 1
+	Buckets []float64
+	// SizeBuckets of request/response size in bytes, default 64B to 16MB
+	SizeBuckets []float64
+	// ProjectLabel add label project with the project name
+	ProjectLabel bool
+	// IdentifierLabel add label identifier with the identifier of options.authorization_proxy, empty on gateway and client
+	IdentifierLabel bool
+	// InFlight add gauge of in-flight requests
+	InFlight bool
+}
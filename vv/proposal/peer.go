@@ -0,0 +1,15 @@
+package proposal
+
+import (
+	"crypto/x509"
+)
+
+// PeerIdentity the identity of the client certificate verified by mtls
+type PeerIdentity struct {
+	SPIFFEID            string   // the SAN URI of scheme spiffe, empty if absent
+	DNSNames            []string // SAN DNS
+	URIs                []string // SAN URI
+	CommonName          string   // subject CN
+	OrganizationalUnits []string // subject OU
+	Certificate         *x509.Certificate
+}
@@ -0,0 +1,51 @@
+package proposal
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryPolicy retry a failed unary call with exponential backoff, zero value means the default
+type RetryPolicy struct {
+	// MaxAttempts the first attempt included, default 3
+	MaxAttempts int
+	// Codes the retryable codes, default Unavailable
+	Codes []codes.Code
+	// InitialBackoff default 10ms
+	InitialBackoff time.Duration
+	// MaxBackoff default 1s, the google.rpc.RetryInfo responded also capped by it
+	MaxBackoff time.Duration
+	// BackoffMultiplier default 2
+	BackoffMultiplier float64
+	// Jitter randomize the backoff by ±Jitter in [0, 1), default 0.2
+	Jitter float64
+}
+
+// HedgingPolicy send the same unary call again if no response within Delay, the first successful one wins,
+// only for the idempotent methods; zero value means the default
+type HedgingPolicy struct {
+	// MaxAttempts the first attempt included, default 2
+	MaxAttempts int
+	// Delay between attempts, default 100ms
+	Delay time.Duration
+	// NonFatalCodes the next attempt sent immediately on these codes, others returned at once; default Unavailable
+	NonFatalCodes []codes.Code
+}
+
+// BreakerConfig a circuit breaker opened when the failure ratio of a window exceeded, and closed after the half-open probes succeeded;
+// zero value means the default
+type BreakerConfig struct {
+	// Window the interval counts reset in closed state, default 10s
+	Window time.Duration
+	// MinRequests the requests required in a window before opened, default 20
+	MinRequests int
+	// FailureRatio in (0, 1], default 0.5
+	FailureRatio float64
+	// OpenTimeout how long stay open before half-open, default 5s
+	OpenTimeout time.Duration
+	// HalfOpenRequests the probes allowed in half-open, closed if all of them succeeded, default 1
+	HalfOpenRequests int
+	// FailureCodes the codes counted as failure, default Unavailable, DeadlineExceeded, ResourceExhausted, Internal and Unknown
+	FailureCodes []codes.Code
+}
@@ -3,117 +3,273 @@
 package dummy
 
 import (
-	errors "github.com/bluekaki/pkg/errors"
+	validation "github.com/bluekaki/pkg/vv/pkg/plugin/protoc-gen-message-validator/validation"
 	strings "strings"
+	utf8 "unicode/utf8"
 )
 
 func (e *EchoReq) Validate() error {
+	return e.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (e *EchoReq) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	e.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (e *EchoReq) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	e.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (e *EchoReq) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Message  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	e.Message = strings.TrimSpace(e.Message)
 
-	if e.Message == "" {
-		return errors.New("message required")
+	if e.GetMessage() == "" {
+		if !collector.Add(validation.Join(path, "message"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	if !(len(e.Message) <= 30) {
-		return errors.New("message illegal")
+	if !(utf8.RuneCountInString(e.GetMessage()) <= 30) {
+		if !collector.Add(validation.Join(path, "message"), "le", "30", e.GetMessage(), "illegal") {
+			return false
+		}
 	}
 
-	return nil
+	return true
 }
 
 func (e *EchoResp) Validate() error {
+	return e.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (e *EchoResp) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	e.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (e *EchoResp) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	e.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (e *EchoResp) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Message  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	e.Message = strings.TrimSpace(e.Message)
 
 	// Ack  Kind:BoolKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 
-	return nil
+	return true
 }
 
 func (p *PostEchoReq) Validate() error {
+	return p.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (p *PostEchoReq) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	p.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (p *PostEchoReq) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	p.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (p *PostEchoReq) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Name  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	p.Name = strings.TrimSpace(p.Name)
 
-	if p.Name == "" {
-		return errors.New("name required")
+	if p.GetName() == "" {
+		if !collector.Add(validation.Join(path, "name"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	if !(len(p.Name) <= 30) {
-		return errors.New("name illegal")
+	if !(utf8.RuneCountInString(p.GetName()) <= 30) {
+		if !collector.Add(validation.Join(path, "name"), "le", "30", p.GetName(), "illegal") {
+			return false
+		}
 	}
 
 	// Message  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	p.Message = strings.TrimSpace(p.Message)
 
-	if p.Message == "" {
-		return errors.New("message required")
+	if p.GetMessage() == "" {
+		if !collector.Add(validation.Join(path, "message"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	if !(len(p.Message) <= 30) {
-		return errors.New("message illegal")
+	if !(utf8.RuneCountInString(p.GetMessage()) <= 30) {
+		if !collector.Add(validation.Join(path, "message"), "le", "30", p.GetMessage(), "illegal") {
+			return false
+		}
 	}
 
-	return nil
+	return true
 }
 
 func (p *PostEchoResp) Validate() error {
+	return p.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (p *PostEchoResp) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	p.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (p *PostEchoResp) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	p.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (p *PostEchoResp) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Message  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	p.Message = strings.TrimSpace(p.Message)
 
 	// Ack  Kind:BoolKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 
-	return nil
+	return true
 }
 
 func (u *UploadReq) Validate() error {
+	return u.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (u *UploadReq) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	u.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (u *UploadReq) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	u.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (u *UploadReq) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// FileName  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	u.FileName = strings.TrimSpace(u.FileName)
 
-	if u.FileName == "" {
-		return errors.New("file_name required")
+	if u.GetFileName() == "" {
+		if !collector.Add(validation.Join(path, "file_name"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	if !(len(u.FileName) <= 30) {
-		return errors.New("file_name illegal")
+	if !(utf8.RuneCountInString(u.GetFileName()) <= 30) {
+		if !collector.Add(validation.Join(path, "file_name"), "le", "30", u.GetFileName(), "illegal") {
+			return false
+		}
 	}
 
 	// Raw  Kind:BytesKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 
-	if len(u.Raw) == 0 {
-		return errors.New("raw required")
+	if len(u.GetRaw()) == 0 {
+		if !collector.Add(validation.Join(path, "raw"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	return nil
+	return true
 }
 
 func (u *UploadResp) Validate() error {
+	return u.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (u *UploadResp) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	u.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (u *UploadResp) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	u.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (u *UploadResp) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Digest  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	u.Digest = strings.TrimSpace(u.Digest)
 
-	return nil
+	return true
 }
 
 func (p *PictureReq) Validate() error {
+	return p.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (p *PictureReq) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	p.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (p *PictureReq) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	p.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (p *PictureReq) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// FileName  Kind:StringKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 	p.FileName = strings.TrimSpace(p.FileName)
 
-	if p.FileName == "" {
-		return errors.New("file_name required")
+	if p.GetFileName() == "" {
+		if !collector.Add(validation.Join(path, "file_name"), "require", "", nil, "required") {
+			return false
+		}
 	}
 
-	if !(len(p.FileName) <= 30) {
-		return errors.New("file_name illegal")
+	if !(utf8.RuneCountInString(p.GetFileName()) <= 30) {
+		if !collector.Add(validation.Join(path, "file_name"), "le", "30", p.GetFileName(), "illegal") {
+			return false
+		}
 	}
 
-	return nil
+	return true
 }
 
 func (p *PictureResp) ContentType() string {
@@ -123,10 +279,29 @@ func (p *PictureResp) Payload() []byte {
 	return p.Raw
 }
 func (p *PictureResp) Validate() error {
+	return p.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (p *PictureResp) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	p.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (p *PictureResp) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	p.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (p *PictureResp) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Raw  Kind:BytesKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 
-	return nil
+	return true
 }
 
 func (e *ExcelResp) ContentType() string {
@@ -136,8 +311,27 @@ func (e *ExcelResp) Payload() []byte {
 	return e.Raw
 }
 func (e *ExcelResp) Validate() error {
+	return e.ValidateWithPath("")
+}
+
+// ValidateAll collect all the violations rather than the first one, the error is validation.Violations
+func (e *ExcelResp) ValidateAll() error {
+	collector := validation.NewCollector(true)
+	e.ValidateCollect("", collector)
+	return collector.Err()
+}
+
+// ValidateWithPath the path prefixed to the field in error, used by nested message
+func (e *ExcelResp) ValidateWithPath(path string) error {
+	collector := validation.NewCollector(false)
+	e.ValidateCollect(path, collector)
+	return collector.Err()
+}
+
+// ValidateCollect add the violations into collector, returns false if validating should stop
+func (e *ExcelResp) ValidateCollect(path string, collector *validation.Collector) bool {
 
 	// Raw  Kind:BytesKind Cardinality:optional IsList:false IsMap:false IsPacked:false IsPlaceholder:false IsWeak:false IsExtension:false HasPresence:false
 
-	return nil
+	return true
 }
@@ -28,9 +28,10 @@ func main() {
 		return dummy.RegisterDummyServiceHandlerFromEndpoint(ctx, mux, "127.0.0.1:8000", opts)
 	}
 
+	handler := gateway.NewCorsHandler(logger, notifyHandler, register, gateway.WithProjectName("dummy-gateway"))
 	server := &http.Server{
 		Addr:    ":8080",
-		Handler: gateway.NewCorsHandler(logger, notifyHandler, register, gateway.WithProjectName("dummy-gateway")),
+		Handler: handler,
 	}
 
 	go func() {
@@ -43,6 +44,7 @@ func main() {
 	shutdown.NewHook().Close(
 		func() {
 			server.Shutdown(context.TODO())
+			handler.Close()
 			cancel()
 			logger.Info("shutdown")
 		},
@@ -8,6 +8,7 @@ import (
 	"github.com/bluekaki/pkg/errors"
 	"github.com/bluekaki/pkg/shutdown"
 	"github.com/bluekaki/pkg/vv/builder/server"
+	"github.com/bluekaki/pkg/vv/pkg/alert"
 	"github.com/bluekaki/pkg/vv/proposal"
 	"github.com/bluekaki/pkg/vv/testdata/api/gen"
 	"github.com/bluekaki/pkg/zaplog"
@@ -30,7 +31,8 @@ func main() {
 		dummy.RegisterDummyServiceServer(server, &dummyService{logger: logger})
 	}
 
-	srv := server.New(logger, notifyHandler, register, server.WithProjectName("dummy-server"))
+	alerts := alert.NewManager(notifyHandler, &alert.Config{Window: time.Minute * 5})
+	srv := server.New(logger, alerts.Notify, register, server.WithProjectName("dummy-server"))
 
 	listener, err := net.Listen("tcp", "127.0.0.1:8000")
 	if err != nil {
@@ -48,6 +50,7 @@ func main() {
 		func() {
 			srv.GracefulStop()
 			hcheck.Shutdown()
+			alerts.Close()
 			logger.Info("shutdown")
 		},
 	)
@@ -6,7 +6,9 @@ import (
 
 	"github.com/bluekaki/pkg/vv/internal/interceptor"
 	"github.com/bluekaki/pkg/vv/internal/pkg/multipart"
+	"github.com/bluekaki/pkg/vv/proposal"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -61,6 +63,11 @@ func SignatureIdentifier(ctx context.Context) string {
 	return identifier
 }
 
+// PeerIdentity get the identity of the client certificate verified by mtls from context, nil if no verified one
+func PeerIdentity(ctx context.Context) *proposal.PeerIdentity {
+	return interceptor.VerifiedPeerIdentity(ctx)
+}
+
 // IsValidatorError check this is an error of validator or not
 func IsValidatorError(err error) bool {
 	if status, _ := status.FromError(err); status != nil && status.Code() == codes.InvalidArgument {
@@ -70,6 +77,21 @@ func IsValidatorError(err error) bool {
 	return false
 }
 
+// FieldViolations get the violations of google.rpc.BadRequest, returned by server WithCollectAllViolations
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	status, _ := status.FromError(err)
+	if status == nil {
+		return nil
+	}
+
+	for _, detail := range status.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			return badRequest.FieldViolations
+		}
+	}
+	return nil
+}
+
 // ParseFormData get file(s) from a wrapped multipart/form-data body
 func ParseFormData(raw []byte) [][]byte {
 	return multipart.ParseFormData(raw)